@@ -61,6 +61,14 @@ func GetApp(configFactoryList []core.ConfigFactory, parameters []*core.Parameter
 						runMode = runApi
 					}
 
+					if onChange {
+						if runMode != runApi {
+							return errors.New("on-change of run should be used with api, or use run auto --on-change")
+						}
+						OnChange(parameters, nil)
+						return nil
+					}
+
 					if Time != 0 {
 						_ = RunDDNS(parameters)
 						RunPerTime(Time, nil, parameters)
@@ -74,14 +82,21 @@ func GetApp(configFactoryList []core.ConfigFactory, parameters []*core.Parameter
 						Name:    "api",
 						Aliases: []string{"i", "I"},
 
-						Usage: "get ip address from provided `ApiName`, eg: ipify/identMe",
+						Usage: "get ip address from provided `ApiName`, eg: ipify/identMe/openDNS/googleDNS/stun/upnp/natpmp, or consensus of several apis",
 
 						Destination: &ApiName,
 
 						Category: "RUN",
 					},
+					consensusApisFlag,
+					quorumFlag,
+					consensusTimeoutFlag,
 					parallelFlag,
+					forceFlag,
 					timeFlag,
+					onChangeFlag,
+					onChangeScanTimeFlag,
+					onChangeDebounceFlag,
 					timesLimitationFlag,
 					retryFlag,
 					silentFlag,
@@ -100,9 +115,11 @@ func GetApp(configFactoryList []core.ConfigFactory, parameters []*core.Parameter
 						Usage:   "run ddns, use ip address of interface set in Device Section automatically",
 						Flags: []cli.Flag{
 							parallelFlag,
+							forceFlag,
 							timeFlag,
 							onChangeFlag,
 							onChangeScanTimeFlag,
+							onChangeDebounceFlag,
 							timesLimitationFlag,
 							retryFlag,
 							silentFlag,
@@ -161,9 +178,11 @@ func GetApp(configFactoryList []core.ConfigFactory, parameters []*core.Parameter
 								Usage:   "run ddns, override the ip address of interface set in each service Section",
 								Flags: []cli.Flag{
 									parallelFlag,
+									forceFlag,
 									timeFlag,
 									onChangeFlag,
 									onChangeScanTimeFlag,
+									onChangeDebounceFlag,
 									timesLimitationFlag,
 									retryFlag,
 									silentFlag,
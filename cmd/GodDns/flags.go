@@ -113,6 +113,35 @@ var (
 		},
 	}
 
+	onChangeDebounceFlag = &cli.StringFlag{
+		Name:        "on-change-debounce",
+		Aliases:     []string{"ocdb", "OCDB"},
+		Usage:       "wait until address change of device settles for the time before checking ip change, linux only",
+		DefaultText: "2 seconds",
+		Category:    "TRIGGER",
+		Action: func(context *cli.Context, s string) error {
+			if !context.Bool("on-change") {
+				return errors.New("on-change-debounce should be used with on-change")
+			}
+
+			t, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			core.UniversalConfig[core.OcDebounce] = t
+			return nil
+		},
+	}
+
+	forceFlag = &cli.BoolFlag{
+		Name:        "force",
+		Aliases:     []string{"f", "F"},
+		Value:       false,
+		Usage:       "update every record even if its ip is published last time according to the state file",
+		Destination: &force,
+		Category:    "RUN",
+	}
+
 	timeFlag = &cli.StringFlag{
 		Name:        "time",
 		Aliases:     []string{"t", "T"},
@@ -220,6 +249,46 @@ var (
 		Category: "RUN",
 	}
 
+	consensusApisFlag = &cli.StringFlag{
+		Name:        "consensus-apis",
+		Aliases:     []string{"capis"},
+		Value:       consensusApis,
+		Usage:       "`ApiNames` queried in consensus mode of api, separated by comma",
+		Destination: &consensusApis,
+		Category:    "CONSENSUS",
+	}
+
+	quorumFlag = &cli.IntFlag{
+		Name:        "quorum",
+		Aliases:     []string{"q", "Q"},
+		Value:       0,
+		DefaultText: "majority",
+		Usage:       "accept ip only if `n` apis agree in consensus mode of api",
+		Destination: &quorum,
+		Action: func(context *cli.Context, i int) error {
+			if i < 0 {
+				return errors.New("quorum should not be negative")
+			}
+			return nil
+		},
+		Category: "CONSENSUS",
+	}
+
+	consensusTimeoutFlag = &cli.DurationFlag{
+		Name:        "consensus-timeout",
+		Aliases:     []string{"ct", "CT"},
+		Value:       DEFAULTCONSENSUSTIMEOUT,
+		Usage:       "wait apis up to `time` in consensus mode of api",
+		Destination: &consensusTimeout,
+		Action: func(context *cli.Context, d time.Duration) error {
+			if d <= 0 {
+				return errors.New("consensus timeout should be positive")
+			}
+			return nil
+		},
+		Category: "CONSENSUS",
+	}
+
 	parallelFlag = &cli.BoolFlag{
 		Name:        "parallel",
 		Aliases:     []string{"Parallel"},
@@ -71,21 +71,21 @@ func RunDDNS(parameters []*core.Parameters) error {
 	return GenerateExecuteSave(parameters)
 }
 
-// device name -> [ipv4, ipv6]
-type d2i map[string]collections.Pair[string, string]
+// source of ip -> [ipv4, ipv6], the ips are the ones left after the filter of source
+type d2i map[ipSource]collections.Pair[string, string]
 
 var Device2Ips = make(d2i, 20)
 
-func (d *d2i) Add(device string, ip string, t netutil.Type) {
+func (d *d2i) Add(source ipSource, ip string, t netutil.Type) {
 	switch t {
 	case netutil.A:
-		(map[string]collections.Pair[string, string])(*d)[device] = collections.Pair[string, string]{
+		(map[ipSource]collections.Pair[string, string])(*d)[source] = collections.Pair[string, string]{
 			First:  &ip,
-			Second: (map[string]collections.Pair[string, string])(*d)[device].Second,
+			Second: (map[ipSource]collections.Pair[string, string])(*d)[source].Second,
 		}
 	case netutil.AAAA:
-		(map[string]collections.Pair[string, string])(*d)[device] = collections.Pair[string, string]{
-			First:  (map[string]collections.Pair[string, string])(*d)[device].First,
+		(map[ipSource]collections.Pair[string, string])(*d)[source] = collections.Pair[string, string]{
+			First:  (map[ipSource]collections.Pair[string, string])(*d)[source].First,
 			Second: &ip,
 		}
 	default:
@@ -93,6 +93,33 @@ func (d *d2i) Add(device string, ip string, t netutil.Type) {
 	}
 }
 
+// getIpByType and filterDeviceIp are replaced in tests
+var (
+	getIpByType    = netutil.GetIpByType
+	filterDeviceIp = netutil.FilterDeviceIp
+)
+
+// filterIp apply filter to ips got from device, error if no ip is left
+// policies like stable-only in filter are resolved per service, as each service has its own filter
+func filterIp(device string, ips []string, filter string) ([]string, error) {
+	res, err := filterDeviceIp(device, ips, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no ip of %s left after filter %q", device, filter)
+	}
+	return res, nil
+}
+
+// filterOf return the filter of the service if set, else the filter of the global device
+func filterOf(GlobalDevice netinterface.Device, parameter core.Parameters) string {
+	if f, ok := parameter.(core.Filterable); ok && f.GetFilter() != "" {
+		return f.GetFilter()
+	}
+	return GlobalDevice.GetFilter()
+}
+
 func ReadConfig(configs []core.ConfigFactory) ([]core.Parameters, error) {
 	parameters, fileErr, configErrs := core.ConfigureReader(core.GetConfigureLocation(), configs...)
 	if fileErr != nil {
@@ -118,8 +145,11 @@ func RunGetFromApi(parameters []*core.Parameters) error {
 	// if api is api name , get api from map
 	// if api is url , try to make request to url  http://example.com/api?ip=4 or http://example.com/api?ip=6
 
-	var api netutil.Api
-	api, err := netutil.ApiMap.GetApi(ApiName)
+	timeout := 10 * time.Second
+	if ApiName == core.ConsensusApi && consensusTimeout >= timeout {
+		timeout = consensusTimeout + time.Second
+	}
+	api, err := getApi(ApiName)
 	if err != nil {
 		log.Errorf("error getting api %s, %s", ApiName, err)
 		// todo suggestion "do you mean xxx"
@@ -182,7 +212,7 @@ func RunGetFromApi(parameters []*core.Parameters) error {
 				}
 			}
 
-		case <-time.After(10 * time.Second):
+		case <-time.After(timeout):
 			log.Errorf("timeout getting ip address from %s", ApiName)
 			return errors.New("quit")
 		}
@@ -191,18 +221,25 @@ func RunGetFromApi(parameters []*core.Parameters) error {
 			break
 		}
 	}
+	// the last known ips of api are compared with when checking ip change
+	Device2Ips.Add(apiSource(), strings.TrimSpace(ip4), netutil.A)
+	Device2Ips.Add(apiSource(), strings.TrimSpace(ip6), netutil.AAAA)
 
 	for _, parameter := range parameters {
 		if (*parameter).GetName() != netinterface.ServiceName {
 			if d, ok := (*parameter).(core.Service); ok {
 				if d.IsTypeSet() {
+					var err error
 					if netutil.TypeEqual(d.GetType(), netutil.A) {
-						d.SetValue(ip4)
+						err = core.SetValue(d, ip4)
 					} else if netutil.TypeEqual(d.GetType(), netutil.AAAA) {
-						d.SetValue(ip6)
+						err = core.SetValue(d, ip6)
 					} else {
 						log.Errorf("unknown type %s", d.GetType())
 					}
+					if err != nil {
+						log.Errorf("error setting ip address: %s, service:%s", err.Error(), d.GetName())
+					}
 				}
 			}
 		}
@@ -211,89 +248,39 @@ func RunGetFromApi(parameters []*core.Parameters) error {
 	return GenerateExecuteSave(parameters)
 }
 
-func RunAuto(GlobalDevice netinterface.Device, parameters []*core.Parameters) error {
-	log.Info("get ip address automatically")
-	// get ip addr automatically
-
-	/*------------------------------------------------------------------------------------------*/
-
-	devices := GlobalDevice.GetDevices()
-
-	// First is device, second is ip
-	ip4 := collections.Pair[string, string]{}
-	// First is device, second is ip
-	ip6 := collections.Pair[string, string]{}
-
-	var err1, err2 error
-
-	var ip4s []string = nil
-	var ip6s []string = nil
-
-	for _, device := range devices {
-		if ip4s == nil {
-			ip4sTemp, err1Temp := netutil.GetIpByType(device, netutil.A)
-			if err1Temp != nil {
-				err1 = errors.Join(err1, err1Temp)
-				log.Errorf("error getting ipv4 %s ,%s", device, err1)
-			} else {
-				log.Infof("ipv4 from %s: %s", device, ip4sTemp)
-				ip4s, err1 = netutil.HandleIp(ip4sTemp)
-				device := device
-				ip4.Move(&device, &ip4s[0])
-			}
-		}
-
-		if ip6s == nil {
-			ip6sTemp, err2Temp := netutil.GetIpByType(device, netutil.AAAA)
-			if err2Temp != nil {
-				err2 = errors.Join(err2, err2Temp)
-				log.Errorf("error getting ipv6 %s ,%s", device, err2)
-			} else {
-				log.Infof("ipv6 from %s: %s", device, ip6sTemp)
-				ip6s, err2 = netutil.HandleIp(ip6sTemp)
-				device := device
-				ip6.Move(&device, &ip6s[0])
-			}
-		}
-
-		if ip6s != nil && ip4s != nil {
-			break
-		}
+// getApi return the api of name, apis in consensusApis are asked if name is core.ConsensusApi
+func getApi(name string) (netutil.Api, error) {
+	if name == core.ConsensusApi {
+		return newConsensusApi(), nil
 	}
+	return netutil.ApiMap.GetApi(name)
+}
 
-	o4 := sync.Once{}
-	o6 := sync.Once{}
-	set := func(parameter *core.Parameters) error {
-		switch (*parameter).(core.Service).GetType() {
-		case "4":
-			if ip4.First != nil {
-				MainBinder.Bind(ip4.GetFirst(), parameter)
-				(*parameter).(core.Service).SetValue(ip4.GetSecond())
-				o4.Do(
-					func() {
-						Device2Ips.Add(ip4.GetFirst(), ip4.GetSecond(), netutil.A)
-					})
+// newConsensusApi return an api querying consensusApis concurrently, ip is accepted only if quorum of them agree
+// which api said what is logged when they disagree
+func newConsensusApi() netutil.Api {
+	apis := strings.Fields(strings.ReplaceAll(consensusApis, ",", " "))
+	return netutil.Api{
+		Get: func(t netutil.Type) (string, error) {
+			ip, answers, err := core.Consensus(apis, t, quorum, consensusTimeout)
+			if err != nil {
+				return "", err
 			}
-			return err1
-		case "6":
-			if ip6.First != nil {
-				MainBinder.Bind(ip6.GetFirst(), parameter)
-				(*parameter).(core.Service).SetValue(ip6.GetSecond())
-				o6.Do(
-					func() {
-						Device2Ips.Add(ip6.GetFirst(), ip6.GetSecond(), netutil.AAAA)
-					})
+			if !answers.Agreed() {
+				log.Warnf("apis disagree on ipv%d, %s is accepted: %s", t, ip, answers)
 			}
-			return err2
-		default:
-			return fmt.Errorf("unknown type %s", (*parameter).(core.Service).GetType())
-		}
+			return ip, nil
+		},
 	}
+}
+
+func RunAuto(GlobalDevice netinterface.Device, parameters []*core.Parameters) error {
+	log.Info("get ip address automatically")
+	// get ip addr automatically, each service takes the ip left after its own filter
 
 	for _, parameter := range parameters {
 		if _, ok := (*parameter).(core.Service); ok {
-			// if parameter implements DeviceOverridable interface, set the ip address
-			if err := set(parameter); err != nil {
+			if err := set(GlobalDevice, parameter); err != nil {
 				log.Errorf("error setting ip address: %s, skip service:%s", err.Error(), (*parameter).GetName())
 			}
 		}
@@ -358,18 +345,33 @@ func RunOverride(GlobalDevice netinterface.Device, parameters []*core.Parameters
 
 				TypeInt, _ := strconv.Atoi(d.GetType())
 				tempDeviceName = d.GetDevice()
-				ips, err := netutil.GetIpByType(tempDeviceName, uint8(TypeInt))
+				ips, err := getIpByType(tempDeviceName, uint8(TypeInt))
 				if err != nil {
 					errCount++
 
 					log.Errorf("error getting ip address: %s, skip service:%s", err.Error(), d.GetName())
 					continue
 				}
+				filter := filterOf(GlobalDevice, *parameter)
+				ips, err = filterIp(tempDeviceName, ips, filter)
+				if err != nil {
+					errCount++
+
+					log.Errorf("error filtering ip address: %s, skip service:%s", err.Error(), d.GetName())
+					continue
+				}
+
+				if err := core.SetValue(d, ips[0]); err != nil {
+					errCount++
 
-				log.Infof("override %s with %s", d.GetName(), ips[0])
-				d.SetValue(ips[0])
+					log.Errorf("error setting ip address: %s, skip service:%s", err.Error(), d.GetName())
+					continue
+				}
+				log.Infof("override %s with %s", d.GetName(), d.GetIP())
 
-				MainBinder.Bind(tempDeviceName, parameter)
+				source := ipSource{device: tempDeviceName, filter: filter}
+				MainBinder.Bind(source, parameter)
+				Device2Ips.Add(source, ips[0], uint8(TypeInt))
 			} else {
 				// Service is not DeviceOverridable, use ip got from Devices Section
 				err := set(GlobalDevice, parameter)
@@ -396,24 +398,30 @@ func set(GlobalDevice netinterface.Device, ParameterToSet *core.Parameters) erro
 	var err error
 	devices := GlobalDevice.GetDevices()
 	var ips []string = nil
+	// loopback is removed if neither the service nor the global device declares a filter
+	filter := filterOf(GlobalDevice, *ParameterToSet)
+	if filter == "" {
+		filter = "remove-loopback"
+	}
 
 	// if failed to get ip, then try to get ip of next device in the list, else break
 	switch Type {
 	case "4":
 		for _, device := range devices {
 			if ips == nil {
-				ip4sTemp, errTemp := netutil.GetIpByType(device, netutil.A)
+				ip4sTemp, errTemp := getIpByType(device, netutil.A)
 				if errTemp != nil {
 					err = errors.Join(err, errTemp)
 					log.Errorf("error getting ipv4 %s ,%s", device, err)
 				} else {
 					log.Infof("ipv4 from %s: %s", device, ip4sTemp)
-					ips, errTemp = netutil.HandleIp(ip4sTemp, netutil.RemoveLoopback)
-					if errTemp != nil {
+					if ips, errTemp = filterIp(device, ip4sTemp, filter); errTemp != nil {
 						err = errors.Join(err, errTemp)
-						log.Errorf("error handling ipv4 %s ,%s", device, err)
+						log.Errorf("error filtering ipv4 %s ,%s", device, err)
+					} else {
+						device := device
+						ip.Move(&device, &ips[0])
 					}
-					ip.Move(&device, &ips[0])
 				}
 			}
 		}
@@ -421,18 +429,19 @@ func set(GlobalDevice netinterface.Device, ParameterToSet *core.Parameters) erro
 	case "6":
 		for _, device := range devices {
 			if ips == nil {
-				ip6sTemp, errTemp := netutil.GetIpByType(device, netutil.AAAA)
+				ip6sTemp, errTemp := getIpByType(device, netutil.AAAA)
 				if errTemp != nil {
 					err = errors.Join(err, errTemp)
 					log.Errorf("error getting ipv6 %s ,%s", device, err)
 				} else {
 					log.Infof("ipv6 from %s: %s", device, ip6sTemp)
-					ips, errTemp = netutil.HandleIp(ip6sTemp, netutil.RemoveLoopback)
-					if errTemp != nil {
+					if ips, errTemp = filterIp(device, ip6sTemp, filter); errTemp != nil {
 						err = errors.Join(err, errTemp)
-						log.Errorf("error handling ipv4 %s ,%s", device, err)
+						log.Errorf("error filtering ipv6 %s ,%s", device, err)
+					} else {
+						device := device
+						ip.Move(&device, &ips[0])
 					}
-					ip.Move(&device, &ips[0])
 				}
 			}
 		}
@@ -442,8 +451,12 @@ func set(GlobalDevice netinterface.Device, ParameterToSet *core.Parameters) erro
 	}
 
 	if ip.First != nil && ip.Second != nil {
-		MainBinder.Bind(ip.GetFirst(), ParameterToSet)
-		(*ParameterToSet).(core.Service).SetValue(ip.GetSecond())
+		if err := core.SetValue((*ParameterToSet).(core.Service), ip.GetSecond()); err != nil {
+			return err
+		}
+		source := ipSource{device: ip.GetFirst(), filter: filter}
+		MainBinder.Bind(source, ParameterToSet)
+		Device2Ips.Add(source, ip.GetSecond(), netutil.Type2Uint8(Type))
 		return nil
 	} else {
 		return err
@@ -457,32 +470,62 @@ func (n NoRequestErr) Error() string {
 }
 
 func GenerateExecuteSave(parameters []*core.Parameters) error {
-	requests := GenerateRequests(parameters)
+	// skip records whose ip is published last time, unless --force is set
+	state := core.GetState()
+	toRun := make([]*core.Parameters, 0, len(parameters))
+	for _, p := range parameters {
+		if s, ok := (*p).(core.Service); ok {
+			state.Restore(s)
+			if !force && state.Unchanged(s) {
+				log.Infof("%s:%s(%s) is unchanged since last run, skip", s.GetName(), s.Target(), netutil.Type2Str(s.GetType()))
+				continue
+			}
+		}
+		toRun = append(toRun, p)
+	}
+
+	requests := GenerateRequests(toRun)
 
 	if requests == nil {
+		if len(toRun) < len(parameters) {
+			log.Info("all records are unchanged since last run, use --force to update them anyway")
+			return nil
+		}
 		return NoRequestErr{}
 	}
 
-	Parameters2Save := make([]core.Parameters, 0, len(parameters))
-	for _, p := range parameters {
-		if (*p).GetName() == netinterface.ServiceName {
-			Parameters2Save = append(Parameters2Save, *p)
+	ExecuteRequests(requests...)
+	DisplayAll(output, requests...)
+
+	// update info from request.parameters, requests are generated in order of toRun
+	// but parameters failed to generate request are skipped
+	i := 0
+	for _, p := range toRun {
+		if i >= len(requests) {
 			break
 		}
+		s, ok := (*p).(core.Service)
+		if !ok || (*p).GetName() == netinterface.ServiceName {
+			continue
+		}
+		r := requests[i].ToParameters()
+		if s.GetName() != r.GetName() || s.Target() != r.Target() || s.GetType() != r.GetType() {
+			continue
+		}
+		*p = r
+		i++
 	}
-
-	ExecuteRequests(requests...)
-	DisplayAll(output, requests...)
 	for _, request := range requests {
-		// update info from request.parameters
-		r2p := request.ToParameters()
-		Parameters2Save = append(Parameters2Save, r2p)
+		state.Record(request)
 	}
-
-	for i := range parameters {
-		*parameters[i] = Parameters2Save[i]
+	if err := state.Save(); err != nil {
+		log.Errorf("error saving state: %s", err.Error())
 	}
 
+	Parameters2Save := make([]core.Parameters, 0, len(parameters))
+	for _, p := range parameters {
+		Parameters2Save = append(Parameters2Save, *p)
+	}
 	return SaveFromParameters(Parameters2Save...)
 }
 
@@ -512,13 +555,13 @@ func PrintDefault(request core.Request, output io.Writer) {
 	switch {
 	case len(serviceErr) > 0:
 		_, _ = log.ErrPP.Fprintln(output,
-			fmt.Sprint("error executing request ", request.GetName(), " at ", request.Target()))
+			fmt.Sprint("error executing request ", request.GetName(), " at ", targetOf(request)))
 	case len(serviceWarn) > 0:
 		_, _ = log.WarnPP.Fprintln(output,
-			fmt.Sprint("warning executing request ", request.GetName(), " at ", request.Target()))
+			fmt.Sprint("warning executing request ", request.GetName(), " at ", targetOf(request)))
 	default:
 		_, _ = log.InfoPP.Fprintln(output,
-			fmt.Sprint("displaying message from Service ", request.GetName(), " at ", request.Target()))
+			fmt.Sprint("displaying message from Service ", request.GetName(), " at ", targetOf(request)))
 	}
 
 	if len(serviceInfo) > 0 {
@@ -539,6 +582,17 @@ func PrintDefault(request core.Request, output io.Writer) {
 	}
 }
 
+// targetOf return target with the type of record like "www.example.com(AAAA)",
+// so that A and AAAA of a dual-stack section are told apart
+func targetOf(request core.Request) string {
+	if p := request.ToParameters(); p != nil {
+		if t := netutil.Type2Str(p.GetType()); t != "" {
+			return request.Target() + "(" + t + ")"
+		}
+	}
+	return request.Target()
+}
+
 func PrintInTable(request core.Request, output io.Writer) {
 	t := GetTableObj(request)
 	t.SetOutputMirror(output)
@@ -558,7 +612,7 @@ func PrintMD(request core.Request, output io.Writer) {
 	content.WriteString("# ")
 	content.WriteString(request.GetName())
 	content.WriteString(" at ")
-	content.WriteString(request.Target())
+	content.WriteString(targetOf(request))
 	content.WriteByte('\n')
 
 	infoMsg := request.Status().MG.GetMsgOf(core.Info)
@@ -597,8 +651,13 @@ func PrintMD(request core.Request, output io.Writer) {
 func GetTableObj(request core.Request) table.Writer {
 	t := table.NewWriter()
 	t.SetTitle(request.GetName())
-	status := "OK"
-	if request.Status().Status != core.Success {
+	var status string
+	switch request.Status().Status {
+	case core.Success:
+		status = "OK"
+	case core.Unchanged:
+		status = "Unchanged"
+	default:
 		status = "Fail"
 	}
 	TitleColor := text.Colors{text.FgGreen}
@@ -606,7 +665,7 @@ func GetTableObj(request core.Request) table.Writer {
 	content := table.Row{
 		request.GetName(),
 		status,
-		request.Target(),
+		targetOf(request),
 		request.ToParameters().GetIP(),
 	}
 
@@ -679,7 +738,8 @@ func ExecuteRequests(requests ...core.Request) {
 	var wg sync.WaitGroup
 
 	deal := func(err error, request core.Request) {
-		if err != nil || (request).Status().Status != core.Success {
+		// the record already holds the ip, nothing to retry
+		if err != nil || ((request).Status().Status != core.Success && (request).Status().Status != core.Unchanged) {
 			log.ErrorRaw(fmt.Sprintf("error executing request, %v", err))
 			Retry(request, retryAttempt)
 		}
@@ -689,12 +749,15 @@ func ExecuteRequests(requests ...core.Request) {
 		switch res.Status {
 		case core.Success:
 			status = "Success"
-			log.InfoRaw(fmt.Sprintf("name:%s, status:%s  msg:%s", res.Name, status, res.MG))
+			log.InfoRaw(fmt.Sprintf("name:%s, target:%s, status:%s  msg:%s", res.Name, targetOf(request), status, res.MG))
+		case core.Unchanged:
+			status = "Unchanged"
+			log.InfoRaw(fmt.Sprintf("name:%s, target:%s, status:%s  msg:%s", res.Name, targetOf(request), status, res.MG))
 		case core.Failed:
 			errMsg := fmt.Sprintf("error executing request, %v", err)
 			log.ErrorRaw(errMsg)
 			status = "Failed"
-			log.InfoRaw(fmt.Sprintf("name:%s, status:%s, msg:%s", res.Name, status, res.MG))
+			log.InfoRaw(fmt.Sprintf("name:%s, target:%s, status:%s, msg:%s", res.Name, targetOf(request), status, res.MG))
 			if retryAttempt != 0 {
 				log.ErrorRaw(fmt.Sprintf("all retry failed, skip %s:%s", (request).GetName(), (request).Target()))
 			}
@@ -715,14 +778,28 @@ func ExecuteRequests(requests ...core.Request) {
 		})
 	}
 
+	// requests of the same batch are sent in one API call
+	executeBatch := func(group []core.Request) {
+		log.Tracef("batch request: %s, %d requests", group[0].GetName(), len(group))
+		err := group[0].(core.Batch).MakeBatchRequest(group[1:]...)
+		for _, request := range group {
+			deal(err, request)
+		}
+	}
+
 	if proxyEnable {
-		for _, request := range requests {
-			request := request
+		for _, group := range core.GroupBatches(requests...) {
+			group := group
+			request := group[0]
 			wg.Add(1)
 
 			_ = core.MainGoroutinePool.Submit(func() {
 				var err error
 				defer wg.Done()
+				if len(group) > 1 {
+					executeBatch(group)
+					return
+				}
 				log.Tracef("request: %s", request.GetName())
 				throughProxy, ok := request.(core.ThroughProxy)
 				if ok {
@@ -739,11 +816,16 @@ func ExecuteRequests(requests ...core.Request) {
 		}
 		wg.Wait()
 	} else {
-		for _, request := range requests {
-			request := request
+		for _, group := range core.GroupBatches(requests...) {
+			group := group
+			request := group[0]
 			wg.Add(1)
 			_ = core.MainGoroutinePool.Submit(func() {
 				defer wg.Done()
+				if len(group) > 1 {
+					executeBatch(group)
+					return
+				}
 				var err error
 				log.Tracef("request: %s", request.GetName())
 				err = request.MakeRequest()
@@ -765,20 +847,31 @@ func ExecuteRequests(requests ...core.Request) {
 
 func Retry(request core.Request, i uint8) {
 	for j := uint8(1); j <= i; j++ {
+		if backOff, ok := request.(core.BackOff); ok && !backOff.Retryable() {
+			warnMsg := fmt.Sprintf("%s:%s is rejected, fix the config before retrying", request.GetName(), request.Target())
+			log.WarnRaw(warnMsg)
+			request.Status().MG.AddWarn(warnMsg)
+			return
+		}
+		if backOff, ok := request.(core.BackOff); ok && backOff.RetryAfter() > 0 {
+			warnMsg := fmt.Sprintf("%s:%s asks to back off for %s, skip retrying", request.GetName(), request.Target(), backOff.RetryAfter().Round(time.Second))
+			log.WarnRaw(warnMsg)
+			request.Status().MG.AddWarn(warnMsg)
+			return
+		}
+
 		errMsg := fmt.Sprintf("retrying %s:%s, attempt %d", request.GetName(), request.Target(), j)
 		log.WarnRaw(errMsg)
 		request.Status().MG.AddError(fmt.Sprintf("retrying %s:%s, attempt %d", request.GetName(), request.Target(), j))
 
-		if proxyEnable {
-			throughProxy, ok := request.(core.ThroughProxy)
-			if ok {
-				err := throughProxy.RequestThroughProxy()
-				if err != nil {
-					throughProxy.Status().MG.AddError(fmt.Sprintf("error executing request, %v", err))
-					log.ErrorRaw(fmt.Sprintf("error: %s", err.Error()))
-				} else {
-					return
-				}
+		// services which can not go through a proxy(e.g. RFC2136) are requested directly
+		if throughProxy, ok := request.(core.ThroughProxy); proxyEnable && ok {
+			err := throughProxy.RequestThroughProxy()
+			if err != nil {
+				throughProxy.Status().MG.AddError(fmt.Sprintf("error executing request, %v", err))
+				log.ErrorRaw(fmt.Sprintf("error: %s", err.Error()))
+			} else {
+				return
 			}
 		} else {
 			err := request.MakeRequest()
@@ -900,7 +993,8 @@ func SaveConfig(FileName string, flag int, parameters ...core.Parameters) error
 
 func SaveFromParameters(parameters ...core.Parameters) error {
 	log.Debug(parameters)
-	// todo Merge Parameters that differ only by subdomain
+	// merge Parameters that differ only by subdomain back into one section
+	parameters = core.MergeParameters(parameters...)
 	err := SaveConfig(core.GetConfigureLocation(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, parameters...)
 	if err != nil {
 		log.Errorf("error saving config: %s", err.Error())
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"GodDns/core"
+	"GodDns/netinterface"
+	"GodDns/netutil"
+)
+
+// fakeService is an AAAA record filtered by its own filter
+type fakeService struct {
+	name   string
+	filter string
+	ip     string
+}
+
+func (f *fakeService) GetName() string                         { return f.name }
+func (f *fakeService) SaveConfig(uint) (core.ConfigStr, error) { return core.ConfigStr{}, nil }
+func (f *fakeService) Target() string                          { return f.name + ".example.com" }
+func (f *fakeService) ToRequest() (core.Request, error)        { return nil, errors.New("not implemented") }
+func (f *fakeService) SetValue(ip string)                      { f.ip = ip }
+func (f *fakeService) GetIP() string                           { return f.ip }
+func (f *fakeService) GetType() string                         { return "6" }
+func (f *fakeService) IsTypeSet() bool                         { return true }
+func (f *fakeService) GetFilter() string                       { return f.filter }
+
+// useDevice replace ips of devices and apply policies to infos, restore them after test
+func useDevice(t *testing.T, device string, infos []netutil.AddrInfo) {
+	oldGet, oldFilter := getIpByType, filterDeviceIp
+	oldBinder, oldIps := MainBinder, Device2Ips
+	MainBinder, Device2Ips = make(BindDeviceService), make(d2i)
+	t.Cleanup(func() {
+		getIpByType, filterDeviceIp = oldGet, oldFilter
+		MainBinder, Device2Ips = oldBinder, oldIps
+	})
+
+	getIpByType = func(d string, _ netutil.Type) ([]string, error) {
+		if d != device {
+			return nil, errors.New("no such device")
+		}
+		ips := make([]string, 0, len(infos))
+		for _, info := range infos {
+			ips = append(ips, info.IP)
+		}
+		return ips, nil
+	}
+	policies := map[string]netutil.Policy{
+		"stable-only":    netutil.StableOnly,
+		"temporary-only": netutil.TemporaryOnly,
+	}
+	filterDeviceIp = func(_ string, ips []string, filter string) ([]string, error) {
+		policy, ok := policies[filter]
+		if !ok {
+			return ips, nil
+		}
+		var res []string
+		for _, info := range policy(infos) {
+			res = append(res, info.IP)
+		}
+		return res, nil
+	}
+}
+
+func TestSetPolicyPerService(t *testing.T) {
+	const stable, temporary = "2001:db8::1:2:3:4", "2001:db8::dead:beef"
+	useDevice(t, "eth0", []netutil.AddrInfo{
+		{IP: temporary, Temporary: true, PreferredLifetime: 3600, ValidLifetime: 7200},
+		{IP: stable, PreferredLifetime: netutil.InfiniteLifetime, ValidLifetime: netutil.InfiniteLifetime},
+	})
+	global := netinterface.Device{Devices: []string{"eth0"}}
+
+	var nas, laptop core.Parameters = &fakeService{name: "nas", filter: "stable-only"},
+		&fakeService{name: "laptop", filter: "temporary-only"}
+	for _, p := range []*core.Parameters{&nas, &laptop} {
+		if err := set(global, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ip := nas.(core.Service).GetIP(); ip != stable {
+		t.Errorf("expect %s with stable-only, got %s", stable, ip)
+	}
+	if ip := laptop.(core.Service).GetIP(); ip != temporary {
+		t.Errorf("expect %s with temporary-only, got %s", temporary, ip)
+	}
+
+	// the daemon checks each policy separately, so that a change is sent to the services of that policy only
+	if len(MainBinder) != 2 {
+		t.Fatalf("expect 2 sources, got %v", MainBinder)
+	}
+	for source, services := range MainBinder {
+		last := Device2Ips[source]
+		if len(services) != 1 || last.Second == nil || (*services[0]).(core.Service).GetIP() != last.GetSecond() {
+			t.Errorf("source %+v: unexpected services %v or last ip %v", source, services, last.Second)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"GodDns/core"
 	log "GodDns/log"
 	"GodDns/netinterface"
+	"GodDns/netutil"
 	_ "GodDns/service" // register all services
 	"github.com/panjf2000/ants/v2"
 )
@@ -21,6 +22,8 @@ const (
 	MINTIMEGAP          = 5
 	MAXTIMES            = 2628000
 	DEFAULTRETRYATTEMPT = 3
+	// DEFAULTCONSENSUSTIMEOUT is the default timeout of consensus mode of api
+	DEFAULTCONSENSUSTIMEOUT = 5 * time.Second
 )
 
 const (
@@ -40,6 +43,9 @@ var (
 	Time              uint64
 	TimesLimitation   int // 0 means no limitation
 	ApiName           string
+	consensusApis     = "ipify,identMe," + netutil.OpenDNS + "," + netutil.GoogleDNS + "," + netutil.Stun
+	quorum            int
+	consensusTimeout        = DEFAULTCONSENSUSTIMEOUT
 	retryAttempt      uint8 = DEFAULTRETRYATTEMPT
 	config            string
 	defaultLocation   string
@@ -50,6 +56,7 @@ var (
 	runMode           string
 	isLogSet          bool
 	onChange          bool
+	force             bool
 	memProfiling      bool
 	tab               bool
 	md                bool
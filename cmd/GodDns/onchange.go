@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,22 +16,42 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
-type BindDeviceService map[string][]*core.Parameters // refactor?
+// ipSource is a device and the filter pipeline applied to its ips, services on the same device with different filters
+// are bound to different sources, so that each of them is checked and updated with the ip left after its own filter
+type ipSource struct {
+	device string // device name, or api like "api:ipify"
+	filter string
+}
+
+type BindDeviceService map[ipSource][]*core.Parameters // refactor?
 
 var MainBinder = make(BindDeviceService, 20)
 
-func (b *BindDeviceService) Bind(Device string, Service *core.Parameters) (ok bool) {
-	(*b)[Device] = append((*b)[Device], Service)
+func (b *BindDeviceService) Bind(source ipSource, Service *core.Parameters) (ok bool) {
+	(*b)[source] = append((*b)[source], Service)
 	return true
 }
 
+// apiSourcePrefix marks a device of ipSource which is an api instead of a device, like "api:ipify"
+const apiSourcePrefix = "api:"
+
+// apiSource return the source of ip got from api ApiName
+func apiSource() ipSource {
+	return ipSource{device: apiSourcePrefix + ApiName}
+}
+
+// OnChange run ddns once, then run it again when ip of devices in GlobalDevice or ip got from api changes
+// GlobalDevice can be nil if ip is got from api
 func OnChange(ps []*core.Parameters, GlobalDevice *netinterface.Device) {
 	defer core.CatchPanic(output)
 
-	if GlobalDevice == nil {
+	if GlobalDevice == nil && runMode != runApi {
 		panic("no global device")
 	}
 	err := ModeController(ps, GlobalDevice)
+	if runMode == runApi {
+		bindApiSource(ps)
+	}
 	switch err {
 	case nil:
 		break
@@ -44,6 +65,40 @@ func OnChange(ps []*core.Parameters, GlobalDevice *netinterface.Device) {
 	StartIpChangeDaemon(ps)
 }
 
+// bindApiSource bind services to the api, a family without last known ip is recorded as "",
+// so the first ip got from api is published
+func bindApiSource(ps []*core.Parameters) {
+	source := apiSource()
+	for _, p := range ps {
+		if s, ok := (*p).(core.Service); ok && s.GetName() != netinterface.ServiceName && s.IsTypeSet() {
+			MainBinder.Bind(source, p)
+		}
+	}
+	if Device2Ips[source].First == nil {
+		Device2Ips.Add(source, "", netutil.A)
+	}
+	if Device2Ips[source].Second == nil {
+		Device2Ips.Add(source, "", netutil.AAAA)
+	}
+}
+
+// getIpOf return ips of device, or the ip got from api if source is like "api:ipify"
+func getIpOf(source string, t netutil.Type) ([]string, error) {
+	name, ok := strings.CutPrefix(source, apiSourcePrefix)
+	if !ok {
+		return getIpByType(source, t)
+	}
+	api, err := getApi(name)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := api.Get(t)
+	if err != nil {
+		return nil, err
+	}
+	return []string{strings.TrimSpace(ip)}, nil
+}
+
 func StartIpChangeDaemon(ps []*core.Parameters) {
 	type result int
 	const (
@@ -124,25 +179,33 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 			log.Int("timeout", res[timeout]).String())
 	}
 
-	for d, services := range MainBinder {
+	// source -> check ip change of the source, run per scanGap and when address of the device changed
+	checks := make(map[ipSource]func(), len(MainBinder))
+
+	for source, services := range MainBinder {
+		source, services := source, services
+		d := source.device
 		changedSignal := make([]chan string, len(services))
 		for i := range changedSignal {
 			changedSignal[i] = make(chan string, 1)
 		}
 		serviceResult := make(chan result, len(services))
 		wg.Add(len(services))
-		_, _ = c.AddFunc(fmt.Sprintf("@every %s", scanGap.String()), func() {
+		var checking sync.Mutex
+		checks[source] = func() {
 			defer core.CatchPanic(output)
-			log.Info("checking ip change ", log.String("device", d).String())
+			checking.Lock()
+			defer checking.Unlock()
+			log.Info("checking ip change ", log.String("device", d).String(), log.String("filter", source.filter).String())
 			res := [4]int{0, 0, 0, 0}
 			{
 				var handledIp []string
-				ip, err := netutil.GetIpByType(d, netutil.A)
+				ip, err := getIpOf(d, netutil.A)
 				if err != nil {
 					log.Error("error getting ip", log.String("error", err.Error()).String())
 					goto AAAA
 				}
-				handledIp, err = netutil.HandleIp(ip)
+				handledIp, err = filterDeviceIp(d, ip, source.filter)
 				if err != nil {
 					log.Error("error handle ip: ", log.String("error", err.Error()).String())
 					goto AAAA
@@ -152,7 +215,7 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 					goto AAAA
 				}
 
-				if OldIp, ok := Device2Ips[d]; ok {
+				if OldIp, ok := Device2Ips[source]; ok {
 					if OldIp.First == nil {
 						goto AAAA
 					}
@@ -167,11 +230,11 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 			}
 		AAAA:
 			{
-				ip, err := netutil.GetIpByType(d, netutil.AAAA)
+				ip, err := getIpOf(d, netutil.AAAA)
 				if err != nil {
 					log.Error("error getting ip ", log.String("error", err.Error()).String())
 				}
-				handledIp, err := netutil.HandleIp(ip)
+				handledIp, err := filterDeviceIp(d, ip, source.filter)
 				if err != nil {
 					log.Error("error handle ip ", log.String("error", err.Error()).String())
 				}
@@ -180,7 +243,7 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 					goto END
 				}
 
-				if OldIp, ok := Device2Ips[d]; ok {
+				if OldIp, ok := Device2Ips[source]; ok {
 					if OldIp.Second == nil {
 						goto END
 					}
@@ -196,7 +259,8 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 			if res[done] != 0 {
 				save <- struct{}{}
 			}
-		})
+		}
+		_, _ = c.AddFunc(fmt.Sprintf("@every %s", scanGap.String()), checks[source])
 
 		for i, service := range services {
 			times := TimesLimitation
@@ -209,16 +273,20 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 					newIp := <-changedSignal[i]
 					_type := strconv.Itoa(int(netutil.WhichType(newIp)))
 					if (*service).(core.Service).GetType() == _type {
-						(*service).(core.Service).SetValue(newIp)
+						if err := core.SetValue((*service).(core.Service), newIp); err != nil {
+							_, _ = log.ErrPP.Fprintln(output, err.Error())
+							serviceResult <- errorOccur
+							continue
+						}
 						request, err := (*service).(core.Service).ToRequest()
 						if err != nil {
 							_, _ = log.ErrPP.Fprintln(output, err.Error())
 							continue
 						}
 
-						if proxyEnable {
+						if throughProxy, ok := request.(core.ThroughProxy); proxyEnable && ok {
 							_, _ = log.InfoPP.Fprintln(output, "try to request through proxy")
-							err := request.(core.ThroughProxy).RequestThroughProxy()
+							err := throughProxy.RequestThroughProxy()
 							if err != nil {
 								_, _ = log.ErrPP.Fprintln(output, err.Error())
 								Retry(request, retryAttempt)
@@ -233,7 +301,7 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 						}
 
 						switch request.Status().Status {
-						case core.Success:
+						case core.Success, core.Unchanged:
 							serviceResult <- done
 						case core.Failed:
 							serviceResult <- errorOccur
@@ -242,6 +310,7 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 							// todo timeout
 						}
 						Display(request, output)
+						core.GetState().Record(request)
 						*service = request.ToParameters()
 					} else {
 						serviceResult <- unaffected
@@ -256,6 +325,32 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 
 	c.Start()
 
+	// check ip change as soon as address of a device settles, polling is the fallback
+	debounce, ok := core.UniversalConfig[core.OcDebounce].(time.Duration)
+	if !ok {
+		debounce = 2 * time.Second
+	}
+	if watcher, err := netutil.WatchAddr(debounce); err != nil {
+		log.Infof("%s, check ip change per %s only", err.Error(), scanGap)
+	} else {
+		defer watcher.Close()
+		_ = core.MainGoroutinePool.Submit(func() {
+			for d := range watcher.C {
+				for source, check := range checks {
+					if source.device == d {
+						log.Info("address changed ", log.String("device", d).String(), log.String("filter", source.filter).String())
+						check()
+					}
+					// public ip behind NAT may change with address of any device
+					if strings.HasPrefix(source.device, apiSourcePrefix) {
+						log.Info("address changed, check api ", log.String("device", d).String(), log.String("api", source.device).String())
+						check()
+					}
+				}
+			}
+		})
+	}
+
 	_ = core.MainGoroutinePool.Submit(func() {
 		for {
 			<-save
@@ -270,6 +365,9 @@ func StartIpChangeDaemon(ps []*core.Parameters) {
 			if err != nil {
 				_, _ = log.ErrPP.Fprintln(output, err.Error())
 			}
+			if err := core.GetState().Save(); err != nil {
+				_, _ = log.ErrPP.Fprintln(output, err.Error())
+			}
 			time.Sleep(1 * time.Second)
 		}
 	})
@@ -3,7 +3,10 @@ package core
 import (
 	"encoding/json"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -136,6 +139,32 @@ func TestJsonHandler(t *testing.T) {
 	}
 }
 
+func TestGetJsonValue(t *testing.T) {
+	source := `{"result":[{"id":"1"}],"ips":["1.2.3.4"],"success":true}`
+
+	cases := map[string]any{
+		"result[0].id": "1",
+		"ips[0]":       "1.2.3.4",
+		"success":      true,
+	}
+	for path, expected := range cases {
+		value, err := GetJsonValue(source, path)
+		if err != nil {
+			t.Errorf("error getting %s: %v", path, err)
+		}
+		if value != expected {
+			t.Errorf("%s should be %v, got %v", path, expected, value)
+		}
+	}
+
+	// should not panic
+	for _, path := range []string{"result[1].id", "ips[0].id", "success[0]", "missing"} {
+		if _, err := GetJsonValue(source, path); err == nil {
+			t.Errorf("getting %s should return error", path)
+		}
+	}
+}
+
 func TestURLParse(t *testing.T) {
 	urls := []string{
 		"https://myip.ipip.net/s",
@@ -281,3 +310,79 @@ func TestConfigureReader(t *testing.T) {
 	}
 	t.Log(time.Since(tn))
 }
+
+func TestLoadProgramConfigResolvers(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ProgramConfigFileName)
+	err := os.WriteFile(file, []byte(`
+[settings]
+OpenDNSResolver = 127.0.0.1:5353
+googlednsresolver = ::1
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, fatal, warn := LoadProgramConfig(file)
+	if fatal != nil {
+		t.Fatal(fatal)
+	}
+	if warn != nil {
+		t.Error(warn)
+	}
+	if config.resolvers[netutil.OpenDNS] != "127.0.0.1:5353" || config.resolvers[netutil.GoogleDNS] != "::1" {
+		t.Errorf("unexpected resolvers %v", config.resolvers)
+	}
+	if content := config.ConfigStr().Content; !strings.Contains(content, "OpenDNSResolver") || !strings.Contains(content, "GoogleDNSResolver") {
+		t.Errorf("resolvers should be saved, got %s", content)
+	}
+
+	for _, api := range [2]string{netutil.OpenDNS, netutil.GoogleDNS} {
+		old, _ := netutil.GetDNSResolver(api)
+		defer func(api string) { _ = netutil.SetDNSResolver(api, old) }(api)
+	}
+	config.Setup()
+	if r, _ := netutil.GetDNSResolver(netutil.GoogleDNS); r != "[::1]:53" {
+		t.Errorf("resolver of %s should be set, got %s", netutil.GoogleDNS, r)
+	}
+}
+
+func TestLoadProgramConfigStunAndGateway(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ProgramConfigFileName)
+	err := os.WriteFile(file, []byte(`
+[settings]
+StunServers = 127.0.0.1:3478, [::1]:3478 stun.example.com
+StunTimeout = 500ms
+NATPMPGateway = 192.168.1.1
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, fatal, warn := LoadProgramConfig(file)
+	if fatal != nil {
+		t.Fatal(fatal)
+	}
+	if warn != nil {
+		t.Error(warn)
+	}
+	if len(config.stunServers) != 3 || config.stunServers[1] != "[::1]:3478" || config.stunTimeout != 500*time.Millisecond {
+		t.Errorf("unexpected stun settings %v %s", config.stunServers, config.stunTimeout)
+	}
+
+	oldServers, oldTimeout, oldGateway := netutil.GetStunServers(), netutil.GetStunTimeout(), netutil.GetNATPMPGateway()
+	defer func() {
+		_ = netutil.SetStunServers(oldServers...)
+		netutil.SetStunTimeout(oldTimeout)
+		netutil.SetNATPMPGateway(oldGateway)
+	}()
+	config.Setup()
+	if servers := netutil.GetStunServers(); len(servers) != 3 || servers[2] != "stun.example.com:3478" {
+		t.Errorf("stun servers should be set, got %v", servers)
+	}
+	if netutil.GetStunTimeout() != 500*time.Millisecond {
+		t.Errorf("stun timeout should be set, got %s", netutil.GetStunTimeout())
+	}
+	if g := netutil.GetNATPMPGateway(); g != "192.168.1.1:5351" {
+		t.Errorf("nat-pmp gateway should be set, got %s", g)
+	}
+}
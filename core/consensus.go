@@ -0,0 +1,138 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"GodDns/netutil"
+)
+
+// ConsensusApi is the name of consensus mode of api, ip is accepted only if enough apis agree
+const ConsensusApi = "consensus"
+
+// Answer is the answer of an api in consensus
+type Answer struct {
+	Api string
+	IP  string
+	Err error
+}
+
+func (a Answer) String() string {
+	if a.Err != nil {
+		return fmt.Sprintf("%s: error %s", a.Api, a.Err)
+	}
+	return fmt.Sprintf("%s: %s", a.Api, a.IP)
+}
+
+// Answers is the answers of apis in consensus, in the order of apis
+type Answers []Answer
+
+func (a Answers) String() string {
+	s := make([]string, len(a))
+	for i, answer := range a {
+		s[i] = answer.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// Agreed return true if all apis answered the same ip
+func (a Answers) Agreed() bool {
+	for _, answer := range a {
+		if answer.Err != nil || answer.IP != a[0].IP {
+			return false
+		}
+	}
+	return true
+}
+
+// Consensus query apis of netutil.ApiMap concurrently on MainGoroutinePool,
+// ip of Type is accepted only if at least quorum of apis agree, quorum <= 0 means majority of apis.
+// an api not answering in timeout counts as failed. answers of all apis are returned so that disagreements can be told
+func Consensus(apis []string, Type netutil.Type, quorum int, timeout time.Duration) (string, Answers, error) {
+	if len(apis) == 0 {
+		return "", nil, errors.New("no api for consensus")
+	}
+	if quorum <= 0 {
+		quorum = len(apis)/2 + 1
+	}
+	if quorum > len(apis) {
+		return "", nil, fmt.Errorf("quorum %d is more than the number of apis %d", quorum, len(apis))
+	}
+	getters := make([]netutil.Api, len(apis))
+	for i, name := range apis {
+		api, err := netutil.ApiMap.GetApi(name)
+		if err != nil {
+			return "", nil, fmt.Errorf("api %s %w", name, err)
+		}
+		getters[i] = api
+	}
+
+	type indexed struct {
+		i      int
+		answer Answer
+	}
+	results := make(chan indexed, len(apis))
+	answers := make(Answers, len(apis))
+	pending := 0
+	for i := range apis {
+		i := i
+		answers[i] = Answer{Api: apis[i], Err: errors.New("timeout")}
+		err := MainGoroutinePool.Submit(func() {
+			results <- indexed{i, answer(apis[i], getters[i], Type)}
+		})
+		if err != nil {
+			answers[i].Err = err
+			continue
+		}
+		pending++
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+collect:
+	for ; pending > 0; pending-- {
+		select {
+		case r := <-results:
+			answers[r.i] = r.answer
+		case <-timer.C:
+			break collect
+		}
+	}
+
+	// count votes, the ip with most votes wins
+	votes := make(map[string]int, len(apis))
+	best := ""
+	for _, a := range answers {
+		if a.Err != nil {
+			continue
+		}
+		votes[a.IP]++
+		if votes[a.IP] > votes[best] {
+			best = a.IP
+		}
+	}
+	if votes[best] < quorum {
+		return "", answers, fmt.Errorf("no quorum of %d in %d apis: %s", quorum, len(apis), answers)
+	}
+	return best, answers, nil
+}
+
+// answer get ip of Type from api, ip is normalized so that "2001:db8::1" and "2001:0db8::1" agree
+func answer(name string, api netutil.Api, Type netutil.Type) Answer {
+	ip, err := api.Get(Type)
+	if err != nil {
+		return Answer{Api: name, Err: err}
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return Answer{Api: name, Err: fmt.Errorf("invalid ip %q", ip)}
+	}
+	addr = addr.Unmap()
+	if netutil.WhichType(addr.String()) != Type {
+		return Answer{Api: name, Err: fmt.Errorf("%s is not of type %d", addr, Type)}
+	}
+	return Answer{Api: name, IP: addr.String()}
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"GodDns/netutil"
+)
+
+// addFakeApi add an api answering ip after delay to netutil.ApiMap
+func addFakeApi(name string, ip string, delay time.Duration) string {
+	netutil.ApiMap.Add2Apis(name, netutil.Api{Get: func(t netutil.Type) (string, error) {
+		time.Sleep(delay)
+		if ip == "" {
+			return "", errors.New("unreachable")
+		}
+		return ip, nil
+	}})
+	return name
+}
+
+func TestConsensus(t *testing.T) {
+	good1 := addFakeApi("consensus-good1", "1.2.3.4", 0)
+	good2 := addFakeApi("consensus-good2", " 1.2.3.4", 10*time.Millisecond)
+	proxy := addFakeApi("consensus-proxy", "5.6.7.8", 0)
+	failed := addFakeApi("consensus-failed", "", 0)
+	slow := addFakeApi("consensus-slow", "1.2.3.4", time.Second)
+	v6 := addFakeApi("consensus-v6", "2001:0db8::1", 0)
+
+	cases := []struct {
+		name   string
+		apis   []string
+		quorum int
+		expect string
+	}{
+		{"majority", []string{good1, good2, proxy}, 0, "1.2.3.4"},
+		{"all agree", []string{good1, good2}, 2, "1.2.3.4"},
+		{"disagree", []string{good1, proxy}, 2, ""},
+		{"failed api", []string{good1, failed, proxy}, 2, ""},
+		{"timeout", []string{good1, slow}, 2, ""},
+		{"wrong type", []string{good1, v6}, 2, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			start := time.Now()
+			ip, answers, err := Consensus(c.apis, netutil.A, c.quorum, 200*time.Millisecond)
+			if time.Since(start) > 500*time.Millisecond {
+				t.Errorf("consensus should return after timeout, took %s", time.Since(start))
+			}
+			if ip != c.expect {
+				t.Errorf("expect %q, got %q, answers: %s", c.expect, ip, answers)
+			}
+			if (err == nil) != (c.expect != "") {
+				t.Errorf("unexpected error %v", err)
+			}
+			if len(answers) != len(c.apis) {
+				t.Fatalf("expect an answer per api, got %v", answers)
+			}
+			for i, a := range answers {
+				if a.Api != c.apis[i] {
+					t.Errorf("answers should be in the order of apis, got %s at %d", a.Api, i)
+				}
+			}
+			t.Log(answers)
+		})
+	}
+
+	ip, answers, err := Consensus([]string{v6, good1}, netutil.AAAA, 1, time.Second)
+	if err != nil || ip != "2001:db8::1" {
+		t.Errorf("ip should be normalized, got %q %v", ip, err)
+	}
+	if answers.Agreed() {
+		t.Error("answers should not be agreed")
+	}
+	if !strings.Contains(answers.String(), good1+": error") {
+		t.Errorf("failure of %s should be told, got %s", good1, answers)
+	}
+}
+
+func TestConsensusInvalid(t *testing.T) {
+	good := addFakeApi("consensus-good", "1.2.3.4", 0)
+	if _, _, err := Consensus(nil, netutil.A, 0, time.Second); err == nil {
+		t.Error("expect error without apis")
+	}
+	if _, _, err := Consensus([]string{good}, netutil.A, 2, time.Second); err == nil {
+		t.Error("expect error when quorum is more than apis")
+	}
+	if _, _, err := Consensus([]string{good, "no-such-api"}, netutil.A, 1, time.Second); err == nil {
+		t.Error("expect error with unknown api")
+	}
+}
@@ -4,6 +4,8 @@ package core
 
 import (
 	"fmt"
+
+	"GodDns/netutil"
 )
 
 // Parameters basic interface
@@ -32,6 +34,58 @@ type DeviceOverridable interface {
 	IsDeviceSet() bool
 }
 
+// Filterable is an interface for parameters declaring an ordered filter pipeline of ip collected from devices,
+// like "remove-private,remove-loopback,global-unicast,select:0", see netutil.ParseFilter
+type Filterable interface {
+	// GetFilter return the filter pipeline, "" means no filter
+	GetFilter() string
+}
+
+// SuffixComposable is an interface for services publishing a static ipv6 suffix under the current prefix,
+// the AAAA value is composed of the prefix of ip got from device or api and the suffix, see netutil.ComposeIPv6
+type SuffixComposable interface {
+	// GetIPv6Suffix return the suffix, "" means the ip is published as it is
+	GetIPv6Suffix() string
+	// GetPrefixLength return the length of prefix taken from the ip, 0 means netutil.DefaultPrefixLength
+	GetPrefixLength() uint8
+}
+
+// DeviceOptions is embedded by Parameters of services to implement Filterable and SuffixComposable,
+// it is read by ReadDeviceOptions and saved as keys of the service section
+type DeviceOptions struct {
+	Filter       string `KeyValue:"Filter,filter pipeline of ip collected from Device in order, e.g. remove-private,remove-loopback,global-unicast,select:0"`
+	IPv6Suffix   string `KeyValue:"IPv6Suffix,static ipv6 suffix composed with the prefix of ip got for AAAA record, e.g. ::1:2:3:4"`
+	PrefixLength uint8  `KeyValue:"PrefixLength,length of prefix taken from ip got when IPv6Suffix is set, 64(default)"`
+}
+
+// GetFilter return the filter pipeline of ip collected from Device
+func (o *DeviceOptions) GetFilter() string {
+	return o.Filter
+}
+
+// GetIPv6Suffix return the static ipv6 suffix
+func (o *DeviceOptions) GetIPv6Suffix() string {
+	return o.IPv6Suffix
+}
+
+// GetPrefixLength return the length of prefix taken from ip got
+func (o *DeviceOptions) GetPrefixLength() uint8 {
+	return o.PrefixLength
+}
+
+// SetValue set ip to service, the ipv6 is composed with the suffix if service is SuffixComposable and the suffix is set
+func SetValue(s Service, ip string) error {
+	if c, ok := s.(SuffixComposable); ok && c.GetIPv6Suffix() != "" && s.GetType() == "6" {
+		composed, err := netutil.ComposeIPv6(ip, c.GetIPv6Suffix(), int(c.GetPrefixLength()))
+		if err != nil {
+			return fmt.Errorf("error composing ipv6 of %s: %w", s.Target(), err)
+		}
+		ip = composed
+	}
+	s.SetValue(ip)
+	return nil
+}
+
 // Find finds the first parameter in the slice of parameters that has the same name as toFind.
 // If the parameter is found, Find returns the parameter and nil error.
 // If the parameter is not found, Find returns nil and an error.
@@ -43,3 +97,57 @@ func Find(parameters []Parameters, toFind string) (Parameters, error) {
 	}
 	return nil, fmt.Errorf("%s not found", toFind)
 }
+
+// Mergeable is an interface for Parameters which are fanned out from one config section, like one Parameters per subdomain,
+// and can be merged back into one section when saving
+type Mergeable interface {
+	Parameters
+	// MergeableWith return true if the two Parameters differ only by fields which can be merged
+	MergeableWith(Parameters) bool
+	// Merge return a new Parameters merged with others, the receiver and others must not be modified
+	Merge(others ...Parameters) Parameters
+}
+
+// MergeParameters merge Mergeable Parameters which can be merged with each other,
+// the order of first appearance is kept, others are returned as is
+func MergeParameters(parameters ...Parameters) []Parameters {
+	groups := make([][]Parameters, 0, len(parameters))
+	for _, parameter := range parameters {
+		merged := false
+		if _, ok := parameter.(Mergeable); ok {
+			for i, group := range groups {
+				if mergeableWithGroup(group, parameter) {
+					groups[i] = append(group, parameter)
+					merged = true
+					break
+				}
+			}
+		}
+		if !merged {
+			groups = append(groups, []Parameters{parameter})
+		}
+	}
+
+	result := make([]Parameters, 0, len(groups))
+	for _, group := range groups {
+		if len(group) == 1 {
+			result = append(result, group[0])
+		} else {
+			result = append(result, group[0].(Mergeable).Merge(group[1:]...))
+		}
+	}
+	return result
+}
+
+// mergeableWithGroup return true if parameter can be merged with every member of group,
+// MergeableWith only constrains a pair, e.g. www AAAA ::1 and ftp AAAA ::2 can both be merged with mail A,
+// but not with each other
+func mergeableWithGroup(group []Parameters, parameter Parameters) bool {
+	for _, member := range group {
+		m, ok := member.(Mergeable)
+		if !ok || !m.MergeableWith(parameter) {
+			return false
+		}
+	}
+	return true
+}
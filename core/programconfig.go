@@ -67,6 +67,8 @@ type LazyUsedConfig = string
 
 const (
 	OcScanTime LazyUsedConfig = "OcScanTime"
+	// OcDebounce is how long address change of a device should settle before checking ip change
+	OcDebounce LazyUsedConfig = "OcDebounce"
 )
 
 // ProgramConfig  config for program
@@ -79,6 +81,23 @@ type ProgramConfig struct {
 	proxy      proxies
 	ags        []ApiGenerator
 	ocscantime time.Duration
+	ocdebounce time.Duration
+	// resolvers of dns apis, key is the name of api like netutil.OpenDNS
+	resolvers map[string]string
+	// stun servers tried in order, and timeout of each server
+	stunServers []string
+	stunTimeout time.Duration
+	// gateway asked for public address by NAT-PMP/PCP, default gateway is used if not set
+	natpmpGateway string
+}
+
+// resolverKeys are keys of resolvers of dns apis in [settings]
+var resolverKeys = [...]struct {
+	key string
+	api string
+}{
+	{"OpenDNSResolver", netutil.OpenDNS},
+	{"GoogleDNSResolver", netutil.GoogleDNS},
 }
 
 func (p *ProgramConfig) Convert2KeyValue(format string) (content string) {
@@ -96,6 +115,23 @@ func (p *ProgramConfig) Convert2KeyValue(format string) (content string) {
 
 	builder.WriteString(p.proxy.Convert2KeyValue(format))
 	builder.WriteString(fmt.Sprintf(format, "OcScanTime", p.ocscantime))
+	if p.ocdebounce > 0 {
+		builder.WriteString(fmt.Sprintf(format, "OcDebounce", p.ocdebounce))
+	}
+	for _, r := range resolverKeys {
+		if resolver, ok := p.resolvers[r.api]; ok {
+			builder.WriteString(fmt.Sprintf(format, r.key, resolver))
+		}
+	}
+	if len(p.stunServers) > 0 {
+		builder.WriteString(fmt.Sprintf(format, "StunServers", strings.Join(p.stunServers, ", ")))
+	}
+	if p.stunTimeout > 0 {
+		builder.WriteString(fmt.Sprintf(format, "StunTimeout", p.stunTimeout))
+	}
+	if p.natpmpGateway != "" {
+		builder.WriteString(fmt.Sprintf(format, "NATPMPGateway", p.natpmpGateway))
+	}
 	builder.WriteString("\n\n")
 	for _, api := range p.ags {
 		builder.WriteString(api.Convert2KeyValue(format))
@@ -117,7 +153,10 @@ var UniversalConfig = make(map[LazyUsedConfig]any)
 // Setup  program
 // 1. set proxy [not implemented]
 // 2. add apis
-// 3. ...
+// 3. set oc scan time and debounce
+// 4. set resolvers of dns apis
+// 5. set stun servers
+// 6. set NAT-PMP gateway
 func (p *ProgramConfig) Setup() {
 	// 1. set proxy
 	for _, p := range p.proxy {
@@ -135,6 +174,33 @@ func (p *ProgramConfig) Setup() {
 		p.ocscantime = 1 * time.Minute
 	}
 	UniversalConfig[OcScanTime] = p.ocscantime
+	// if not set, default=2 seconds
+	if p.ocdebounce == 0 {
+		p.ocdebounce = 2 * time.Second
+	}
+	UniversalConfig[OcDebounce] = p.ocdebounce
+
+	// 4. set resolvers of dns apis
+	for api, resolver := range p.resolvers {
+		if err := netutil.SetDNSResolver(api, resolver); err != nil {
+			log.Warnf("error setting resolver of %s: %s", api, err)
+		}
+	}
+
+	// 5. set stun servers
+	if len(p.stunServers) > 0 {
+		if err := netutil.SetStunServers(p.stunServers...); err != nil {
+			log.Warnf("error setting stun servers: %s", err)
+		}
+	}
+	if p.stunTimeout > 0 {
+		netutil.SetStunTimeout(p.stunTimeout)
+	}
+
+	// 6. set NAT-PMP gateway
+	if p.natpmpGateway != "" {
+		netutil.SetNATPMPGateway(p.natpmpGateway)
+	}
 }
 
 var DefaultConfig = ProgramConfig{
@@ -220,7 +286,32 @@ func LoadProgramConfig(file string) (programConfig *ProgramConfig, Fatal error,
 					} else {
 						res.ocscantime = duration
 					}
+				case "ocdb", "OCDB", "OnChangeDebounce", "OcDebounce", "on change debounce":
+					duration, err := time.ParseDuration(k.Value())
+					if err != nil {
+						Warn = errors.Join(Warn, err)
+					} else {
+						res.ocdebounce = duration
+					}
+				case "StunServers", "stunservers", "STUNSERVERS":
+					res.stunServers = strings.Fields(strings.ReplaceAll(k.Value(), ",", " "))
+				case "StunTimeout", "stuntimeout", "STUNTIMEOUT":
+					duration, err := time.ParseDuration(k.Value())
+					if err != nil {
+						Warn = errors.Join(Warn, err)
+					} else {
+						res.stunTimeout = duration
+					}
+				case "NATPMPGateway", "natpmpgateway", "NatPmpGateway":
+					res.natpmpGateway = k.Value()
 				default:
+					if api, ok := resolverApi(k.Name()); ok {
+						if res.resolvers == nil {
+							res.resolvers = make(map[string]string)
+						}
+						res.resolvers[api] = k.Value()
+						continue
+					}
 					Warn = errors.Join(Warn, NewUnknownKeyErr(k.Name(), section.Name()))
 				}
 			}
@@ -248,6 +339,16 @@ func LoadProgramConfig(file string) (programConfig *ProgramConfig, Fatal error,
 	return res, nil, Warn
 }
 
+// resolverApi return the name of dns api whose resolver is set by key, case-insensitive
+func resolverApi(key string) (string, bool) {
+	for _, r := range resolverKeys {
+		if strings.EqualFold(r.key, key) {
+			return r.api, true
+		}
+	}
+	return "", false
+}
+
 // LoadApiFromConfig load api from config
 // load string like "[http://localhost:10809 https://example.com:12345 socks5://127.0.0.1:10808 ]"
 func loadProxy(proxy string) (res []url.URL, err error) {
@@ -427,6 +528,11 @@ func (a *ApiGenerator) Generate() netutil.Api {
 	}
 }
 
+// GetJsonValue get the value in json source by path like "data.ipInfo[0].value"
+func GetJsonValue(source string, path string) (any, error) {
+	return jsonHandler{}.HandleResponse(source, path)
+}
+
 type jsonHandler struct{}
 
 func (j jsonHandler) HandleResponse(source string, toGet string) (target any, err error) {
@@ -453,7 +559,7 @@ func (j jsonHandler) HandleResponse(source string, toGet string) (target any, er
 	}
 
 	parts := strings.Split(toGet, ".")
-	for _, part := range parts {
+	for n, part := range parts {
 		if strings.Contains(part, "[") {
 			key := part[:strings.Index(part, "[")]
 			index := part[strings.Index(part, "[")+1 : strings.Index(part, "]")]
@@ -461,7 +567,18 @@ func (j jsonHandler) HandleResponse(source string, toGet string) (target any, er
 			if err != nil {
 				return "", err
 			}
-			result = result[key].([]any)[i].(map[string]any)
+			array, ok := result[key].([]any)
+			if !ok || i < 0 || i >= len(array) {
+				return "", errors.New("no such key")
+			}
+			// the last part may be a value in array like "ips[0]"
+			if n == len(parts)-1 {
+				return array[i], nil
+			}
+			result, ok = array[i].(map[string]any)
+			if !ok {
+				return "", errors.New("no such key")
+			}
 		} else {
 			resultTemp, ok := result[part].(map[string]any)
 			if !ok {
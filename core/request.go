@@ -5,6 +5,7 @@ package core
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 const (
@@ -12,6 +13,8 @@ const (
 	NotExecute
 	Failed
 	Timeout
+	// Unchanged means the record already holds the value, so it is not updated
+	Unchanged
 )
 
 type MsgLevel int
@@ -229,6 +232,47 @@ type ThroughProxy interface {
 	RequestThroughProxy() error
 }
 
+// BackOff is an interface for request which may ask not to be retried immediately,
+// e.g. the server answers "abuse" or "911" in dyndns2 protocol
+type BackOff interface {
+	Request
+	// RetryAfter return how long to wait before the next attempt, 0 means retry as usual
+	RetryAfter() time.Duration
+	// Retryable return false if the request must not be retried until the config is fixed,
+	// e.g. the server answers "badauth" in dyndns2 protocol
+	Retryable() bool
+}
+
+// Batch is an interface for request which can be sent together with other requests of the same BatchKey in one API call,
+// e.g. A and AAAA records of one name replaced by one PATCH
+type Batch interface {
+	Request
+	// BatchKey return the key of batch, "" means the request can not be batched
+	BatchKey() string
+	// MakeBatchRequest make one API call for the receiver and others which have the same BatchKey,
+	// Status of every request is set
+	MakeBatchRequest(others ...Request) error
+}
+
+// GroupBatches group requests of the same BatchKey, the order of first appearance is kept,
+// requests which can not be batched are in groups of their own
+func GroupBatches(requests ...Request) [][]Request {
+	groups := make([][]Request, 0, len(requests))
+	index := make(map[string]int)
+	for _, request := range requests {
+		if batch, ok := request.(Batch); ok && batch.BatchKey() != "" {
+			key := batch.GetName() + "|" + batch.BatchKey()
+			if i, ok := index[key]; ok {
+				groups[i] = append(groups[i], request)
+				continue
+			}
+			index[key] = len(groups)
+		}
+		groups = append(groups, []Request{request})
+	}
+	return groups
+}
+
 type Status struct {
 	Name   string
 	MG     MsgGroup
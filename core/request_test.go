@@ -0,0 +1,46 @@
+package core
+
+import "testing"
+
+type fakeRequest struct {
+	name string
+	key  string
+}
+
+func (f *fakeRequest) ToParameters() Service { return nil }
+func (f *fakeRequest) GetName() string       { return f.name }
+func (f *fakeRequest) MakeRequest() error    { return nil }
+func (f *fakeRequest) Status() Status        { return Status{} }
+func (f *fakeRequest) Target() string        { return f.key }
+
+type fakeBatch struct {
+	fakeRequest
+}
+
+func (f *fakeBatch) BatchKey() string                         { return f.key }
+func (f *fakeBatch) MakeBatchRequest(others ...Request) error { return nil }
+
+func TestGroupBatches(t *testing.T) {
+	a4 := &fakeBatch{fakeRequest{name: "a", key: "www"}}
+	b := &fakeRequest{name: "b", key: "www"}
+	a6 := &fakeBatch{fakeRequest{name: "a", key: "www"}}
+	c := &fakeBatch{fakeRequest{name: "c", key: "www"}}
+	d := &fakeBatch{fakeRequest{name: "a"}}
+	e := &fakeBatch{fakeRequest{name: "a"}}
+
+	groups := GroupBatches(a4, b, a6, c, d, e)
+	expected := [][]Request{{a4, a6}, {b}, {c}, {d}, {e}}
+	if len(groups) != len(expected) {
+		t.Fatalf("expect %d groups, got %d", len(expected), len(groups))
+	}
+	for i := range expected {
+		if len(groups[i]) != len(expected[i]) {
+			t.Fatalf("group %d: expect %d requests, got %d", i, len(expected[i]), len(groups[i]))
+		}
+		for j := range expected[i] {
+			if groups[i][j] != expected[i][j] {
+				t.Errorf("group %d: unexpected request %d", i, j)
+			}
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strconv"
 
 	log "GodDns/log"
+	"GodDns/netutil"
 	"gopkg.in/ini.v1"
 )
 
@@ -225,6 +226,13 @@ func configReader(Filename string, configs []ConfigFactory, ReadConfigErrs error
 					log.Debug(msg)
 					continue // skip this service
 				}
+				if err := validateParameters(temp); err != nil {
+					errCount++
+					msg := fmt.Errorf("failed to read config for %s : %s", c.GetName(), err.Error())
+					ReadConfigErrs = errors.Join(ReadConfigErrs, msg)
+					log.Debug(msg)
+					continue // skip this service
+				}
 				log.Tracef("%s : %s", c.GetName(), temp)
 				log.Debugf("succeed to read config for %s", c.GetName())
 				ps = append(ps, temp...)
@@ -239,6 +247,40 @@ func configReader(Filename string, configs []ConfigFactory, ReadConfigErrs error
 	return ps, nil, ReadConfigErrs
 }
 
+// ReadDeviceOptions read the optional keys Filter, IPv6Suffix and PrefixLength of sec, empty if not exist
+func ReadDeviceOptions(sec ini.Section) (DeviceOptions, error) {
+	o := DeviceOptions{
+		Filter:     sec.Key("Filter").String(),
+		IPv6Suffix: sec.Key("IPv6Suffix").String(),
+	}
+	if !sec.HasKey("PrefixLength") {
+		return o, nil
+	}
+	l, err := sec.Key("PrefixLength").Uint()
+	if err != nil || l > 128 {
+		return DeviceOptions{}, fmt.Errorf("invalid PrefixLength %s, 0-128 expected", sec.Key("PrefixLength").String())
+	}
+	o.PrefixLength = uint8(l)
+	return o, nil
+}
+
+// validateParameters check the filter pipeline of Filterable parameters and the suffix of SuffixComposable ones
+func validateParameters(ps []Parameters) error {
+	for _, p := range ps {
+		if f, ok := p.(Filterable); ok {
+			if err := netutil.ValidateFilter(f.GetFilter()); err != nil {
+				return err
+			}
+		}
+		if c, ok := p.(SuffixComposable); ok && c.GetIPv6Suffix() != "" {
+			if err := netutil.ValidateSuffix(c.GetIPv6Suffix(), int(c.GetPrefixLength())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // ConfigHead generate config head, the section name
 // [Name#No]
 // if No == 0, [Name]
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "GodDns/log"
+	json "GodDns/util/json"
+)
+
+// StateFileName is the name of state file, which is kept in the directory of config file
+const StateFileName = "state.json"
+
+// RecordState is the result of the last update of a record
+type RecordState struct {
+	IP       string    `json:"ip"`
+	RecordId string    `json:"record_id,omitempty"`
+	Time     time.Time `json:"time"`
+	Status   string    `json:"status"`
+}
+
+// RecordCacheable is an interface for service whose record id is looked up from provider,
+// the id is kept in State so that it is not looked up again after restart
+type RecordCacheable interface {
+	Service
+	// GetRecordId return the record id, "" if it is not known
+	GetRecordId() string
+	// SetRecordId set the record id cached
+	SetRecordId(string)
+}
+
+// State records the last published ip of each service target and family,
+// and the time before which a server asks not to be updated again,
+// it is written by the program only and kept apart from the config edited by user
+type State struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]RecordState
+	backOff map[string]time.Time
+}
+
+// stateFile is the content of state file
+type stateFile struct {
+	Records map[string]RecordState `json:"records"`
+	BackOff map[string]time.Time   `json:"back_off,omitempty"`
+}
+
+var (
+	mainState     *State
+	mainStateOnce sync.Once
+)
+
+// GetState return the state loaded from GetStateLocation, it is loaded once when first called,
+// an empty state is used if it can not be loaded
+func GetState() *State {
+	mainStateOnce.Do(func() {
+		var err error
+		mainState, err = LoadState(GetStateLocation())
+		if err != nil {
+			log.Warnf("error loading state, %s", err.Error())
+		}
+	})
+	return mainState
+}
+
+// GetStateLocation return the location of state file, in the directory of config file
+func GetStateLocation() string {
+	return filepath.Join(filepath.Dir(GetConfigureLocation()), StateFileName)
+}
+
+// LoadState load state from file, an empty state is returned if file does not exist
+func LoadState(path string) (*State, error) {
+	s := &State{path: path, records: make(map[string]RecordState), backOff: make(map[string]time.Time)}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if len(content) == 0 {
+		return s, nil
+	}
+	file := stateFile{}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return s, fmt.Errorf("error parsing state file %s: %w", path, err)
+	}
+	for k, r := range file.Records {
+		s.records[k] = r
+	}
+	for k, until := range file.BackOff {
+		s.backOff[k] = until
+	}
+	return s, nil
+}
+
+// stateKey return the key of record of service, like "dnspod:www.example.com:A"
+func stateKey(service Service) string {
+	family := "A"
+	if service.GetType() == "6" {
+		family = "AAAA"
+	}
+	return service.GetName() + ":" + service.Target() + ":" + family
+}
+
+// statusName return the name of status saved in state file
+func statusName(status int) string {
+	switch status {
+	case Success:
+		return "Success"
+	case Unchanged:
+		return "Unchanged"
+	case Failed:
+		return "Failed"
+	case Timeout:
+		return "Timeout"
+	default:
+		return "NotExecute"
+	}
+}
+
+// Get return the state of record of service
+func (s *State) Get(service Service) (RecordState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[stateKey(service)]
+	return r, ok
+}
+
+// Unchanged return true if the ip of service was published successfully last time, so the update can be skipped
+func (s *State) Unchanged(service Service) bool {
+	r, ok := s.Get(service)
+	return ok && service.GetIP() != "" && r.IP == service.GetIP() &&
+		(r.Status == statusName(Success) || r.Status == statusName(Unchanged))
+}
+
+// Restore set the record id kept in state to service if it is RecordCacheable and the id is not known
+func (s *State) Restore(service Service) {
+	c, ok := service.(RecordCacheable)
+	if !ok || c.GetRecordId() != "" {
+		return
+	}
+	if r, ok := s.Get(service); ok && r.RecordId != "" {
+		c.SetRecordId(r.RecordId)
+	}
+}
+
+// Record record the result of request, requests not executed are ignored
+func (s *State) Record(request Request) {
+	status := request.Status().Status
+	if status == NotExecute {
+		return
+	}
+	service := request.ToParameters()
+	r := RecordState{
+		IP:     service.GetIP(),
+		Time:   time.Now(),
+		Status: statusName(status),
+	}
+	if c, ok := service.(RecordCacheable); ok {
+		r.RecordId = c.GetRecordId()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// keep the record id looked up before if the request failed before looking it up
+	if old, ok := s.records[stateKey(service)]; ok && r.RecordId == "" {
+		r.RecordId = old.RecordId
+	}
+	s.records[stateKey(service)] = r
+}
+
+// BackOffUntil return the time before which the server of key must not be updated, zero if it is not set
+func (s *State) BackOffUntil(key string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backOff[key]
+}
+
+// SetBackOff set the time before which the server of key must not be updated
+func (s *State) SetBackOff(key string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backOff[key] = until
+}
+
+// Save write state to file, the file is replaced as a whole so that it is never half written
+// back off which is over is not written
+func (s *State) Save() error {
+	s.mu.Lock()
+	file := stateFile{Records: s.records, BackOff: make(map[string]time.Time, len(s.backOff))}
+	now := time.Now()
+	for k, until := range s.backOff {
+		if until.After(now) {
+			file.BackOff[k] = until
+		}
+	}
+	content, err := json.Marshal(file)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(s.path), StateFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(content); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), s.path)
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeService struct {
+	target, ip, t, recordId string
+}
+
+func (f *fakeService) GetName() string                        { return "fake" }
+func (f *fakeService) SaveConfig(No uint) (ConfigStr, error)  { return ConfigStr{}, nil }
+func (f *fakeService) Target() string                         { return f.target }
+func (f *fakeService) ToRequest() (Request, error)            { return nil, nil }
+func (f *fakeService) SetValue(ip string)                     { f.ip = ip }
+func (f *fakeService) GetIP() string                          { return f.ip }
+func (f *fakeService) GetType() string                        { return f.t }
+func (f *fakeService) IsTypeSet() bool                        { return true }
+func (f *fakeService) GetRecordId() string                    { return f.recordId }
+func (f *fakeService) SetRecordId(id string)                  { f.recordId = id }
+func (f *fakeService) request(status int) *fakeServiceRequest { return &fakeServiceRequest{f, status} }
+
+type fakeServiceRequest struct {
+	service *fakeService
+	status  int
+}
+
+func (f *fakeServiceRequest) ToParameters() Service { return f.service }
+func (f *fakeServiceRequest) GetName() string       { return f.service.GetName() }
+func (f *fakeServiceRequest) MakeRequest() error    { return nil }
+func (f *fakeServiceRequest) Status() Status        { return Status{Status: f.status} }
+func (f *fakeServiceRequest) Target() string        { return f.service.target }
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StateFileName)
+	s, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	www4 := &fakeService{target: "www.example.com", ip: "1.2.3.4", t: "4", recordId: "1"}
+	www6 := &fakeService{target: "www.example.com", ip: "2001:db8::1", t: "6"}
+	ftp := &fakeService{target: "ftp.example.com", ip: "1.2.3.4", t: "4"}
+	s.Record(www4.request(Success))
+	s.Record(www6.request(Failed))
+	s.Record(ftp.request(NotExecute))
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Unchanged(www4) {
+		t.Error("www.example.com A should be unchanged")
+	}
+	if s.Unchanged(www6) {
+		t.Error("failed update should not be skipped")
+	}
+	if _, ok := s.Get(ftp); ok {
+		t.Error("request not executed should not be recorded")
+	}
+	www4.SetValue("5.6.7.8")
+	if s.Unchanged(www4) {
+		t.Error("new ip should not be skipped")
+	}
+
+	// record id is restored only if it is not known
+	restored := &fakeService{target: "www.example.com", t: "4"}
+	s.Restore(restored)
+	if restored.recordId != "1" {
+		t.Errorf("expect record id 1 restored, got %q", restored.recordId)
+	}
+	restored.recordId = "2"
+	s.Restore(restored)
+	if restored.recordId != "2" {
+		t.Errorf("known record id should be kept, got %q", restored.recordId)
+	}
+
+	// record id is kept if the request failed before looking it up
+	www4.recordId = ""
+	s.Record(www4.request(Failed))
+	if r, _ := s.Get(www4); r.RecordId != "1" || r.Status != "Failed" || r.IP != "5.6.7.8" {
+		t.Errorf("unexpected state %+v", r)
+	}
+}
+
+func TestStateBackOff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StateFileName)
+	s, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	until := time.Now().Add(time.Hour).Round(time.Second)
+	s.SetBackOff("server|user", until)
+	s.SetBackOff("expired", time.Now().Add(-time.Hour))
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.BackOffUntil("server|user"); !got.Equal(until) {
+		t.Errorf("expect back off until %s, got %s", until, got)
+	}
+	if !s.BackOffUntil("expired").IsZero() {
+		t.Error("back off which is over should not be saved")
+	}
+}
+
+func TestLoadStateInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StateFileName)
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadState(path)
+	if err == nil {
+		t.Error("expect error parsing invalid state file")
+	}
+	// an empty state is still usable
+	if s == nil || s.Unchanged(&fakeService{ip: "1.2.3.4", t: "4"}) {
+		t.Error("expect empty state")
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta is how long before the expiry a token is treated as expired,
+// so that it does not expire in the middle of a request
+const tokenExpiryDelta = 30 * time.Second
+
+// Token is an access token for services authenticating with OAuth2 etc.
+type Token struct {
+	AccessToken string
+	TokenType   string
+	Expiry      time.Time // zero means never expires
+}
+
+// Valid return whether the token is set and not about to expire
+func (t Token) Valid() bool {
+	if t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(t.Expiry)
+}
+
+// TokenCache cache tokens by key until they expire
+// services sharing the same credentials share the token
+type TokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]Token
+}
+
+// NewTokenCache return an empty TokenCache
+func NewTokenCache() *TokenCache {
+	return &TokenCache{tokens: make(map[string]Token)}
+}
+
+// MainTokenCache is a global TokenCache
+var MainTokenCache = NewTokenCache()
+
+// Get return the cached token of key, fetch a new one and cache it if there is no valid one
+// fetching is serialized, so the token is fetched only once by concurrent requests
+func (c *TokenCache) Get(key string, fetch func() (Token, error)) (Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.tokens[key]; ok && t.Valid() {
+		return t, nil
+	}
+	t, err := fetch()
+	if err != nil {
+		return Token{}, err
+	}
+	c.tokens[key] = t
+	return t, nil
+}
+
+// Invalidate remove the token of key, e.g. the token is rejected by the server
+func (c *TokenCache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, key)
+}
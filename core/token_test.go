@@ -0,0 +1,51 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenCache_Get(t *testing.T) {
+	c := NewTokenCache()
+	fetched := 0
+	fetch := func() (Token, error) {
+		fetched++
+		return Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		token, err := c.Get("key", fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.AccessToken != "token" {
+			t.Errorf("unexpected token %+v", token)
+		}
+	}
+	if fetched != 1 {
+		t.Errorf("token should be fetched once, got %d", fetched)
+	}
+
+	c.Invalidate("key")
+	if _, err := c.Get("key", fetch); err != nil || fetched != 2 {
+		t.Errorf("token should be fetched again after invalidated, got %d, %v", fetched, err)
+	}
+}
+
+func TestTokenCache_GetExpired(t *testing.T) {
+	c := NewTokenCache()
+	fetched := 0
+	_, _ = c.Get("key", func() (Token, error) {
+		fetched++
+		// expires within tokenExpiryDelta
+		return Token{AccessToken: "token", Expiry: time.Now().Add(time.Second)}, nil
+	})
+	_, err := c.Get("key", func() (Token, error) {
+		fetched++
+		return Token{}, errors.New("fetch failed")
+	})
+	if err == nil || fetched != 2 {
+		t.Errorf("expired token should be fetched again, got %d, %v", fetched, err)
+	}
+}
@@ -24,10 +24,11 @@ func init() {
 	core.ConfigFactoryList = append(core.ConfigFactoryList, ConfigFactoryInstance)
 }
 
-// Device contains a slice of device
-// implements Parameters and Config interface
+// Device contains a slice of device and the filter pipeline of ip collected from them
+// implements Parameters, Filterable and Config interface
 type Device struct {
 	Devices []string `KeyValue:"device"`
+	Filter  string   `KeyValue:"filter,filter pipeline of ip in order, e.g. remove-private,remove-loopback,global-unicast,select:0"`
 }
 
 // GetDevices returns the slice of device
@@ -35,6 +36,11 @@ func (d Device) GetDevices() []string {
 	return d.Devices
 }
 
+// GetFilter returns the filter pipeline of ip
+func (d Device) GetFilter() string {
+	return d.Filter
+}
+
 // SaveConfig saves the config of Device
 // returns a ConfigStr which contains the name and content of config and nil
 // should not return error
@@ -49,6 +55,7 @@ func (d Device) SaveConfig(no uint) (core.ConfigStr, error) {
 func (d Device) GenerateDefaultConfigInfo() (core.ConfigStr, error) {
 	return d.GenerateConfigInfo(Device{
 		Devices: []string{"interface1", "interface2", "..."},
+		Filter:  "remove-loopback,global-unicast",
 	}, 0)
 }
 
@@ -67,6 +74,12 @@ func (d Device) ReadConfig(sec ini.Section) ([]core.Parameters, error) { // todo
 
 	// remove [] and remove " "
 	d.Devices = strings.Fields(strings.Trim(strings.ReplaceAll(deviceList.String(), ",", " "), "[]"))
+
+	// optional filter pipeline
+	d.Filter = sec.Key("filter").String()
+	if d.Filter == "" {
+		d.Filter = sec.Key("Filter").String()
+	}
 	return []core.Parameters{d}, nil
 }
 
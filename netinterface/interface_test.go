@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"GodDns/core"
+	"gopkg.in/ini.v1"
 )
 
 func TestDevice_GenerateConfigInfo(t *testing.T) {
@@ -66,3 +67,30 @@ func TestConvert2StringSlice(t *testing.T) {
 		}
 	}
 }
+
+func TestDevice_ReadConfigFilter(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[Device]
+device = [eth0, eth1]
+filter = remove-private,remove-loopback,global-unicast,select:0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := Device{}.ReadConfig(*cfg.Section("Device"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := ps[0].(Device)
+	if d.GetFilter() != "remove-private,remove-loopback,global-unicast,select:0" {
+		t.Errorf("unexpected filter %q", d.GetFilter())
+	}
+
+	info, err := d.SaveConfig(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(info.Content, "filter=remove-private,remove-loopback,global-unicast,select:0") {
+		t.Errorf("filter should be saved, got %s", info.Content)
+	}
+}
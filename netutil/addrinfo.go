@@ -0,0 +1,134 @@
+package netutil
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InfiniteLifetime is the lifetime of a static address which never expires
+const InfiniteLifetime = time.Duration(math.MaxInt64)
+
+// AddrInfo is an ipv6 address of interface with its flags and lifetimes
+// flags and lifetimes are read from netlink on linux, on other platforms every address looks like a static one
+type AddrInfo struct {
+	IP           string
+	PrefixLength int
+	// Temporary is a privacy address (RFC 8981) which rotates every few hours
+	Temporary bool
+	// Deprecated address should not be used for new connections
+	Deprecated bool
+	// Tentative address is in duplicate address detection, DadFailed one failed it
+	Tentative bool
+	DadFailed bool
+	// Permanent address is configured statically instead of by SLAAC or DHCPv6
+	Permanent         bool
+	PreferredLifetime time.Duration
+	ValidLifetime     time.Duration
+}
+
+// Usable return true if the address is neither deprecated nor tentative and passed duplicate address detection
+func (a AddrInfo) Usable() bool {
+	return !a.Deprecated && !a.Tentative && !a.DadFailed
+}
+
+// IsEUI64 return true if the interface id is derived from a MAC address (modified EUI-64)
+func (a AddrInfo) IsEUI64() bool {
+	ip, err := netip.ParseAddr(a.IP)
+	if err != nil || !ip.Is6() {
+		return false
+	}
+	b := ip.As16()
+	return b[11] == 0xff && b[12] == 0xfe
+}
+
+// Policy select ipv6 addresses of a device by their flags and lifetimes, addresses are returned in order of preference
+type Policy func(infos []AddrInfo) []AddrInfo
+
+// policies are the Policies which can be declared in filter pipeline by name
+var policies = map[string]Policy{
+	"stable-only":      StableOnly,
+	"temporary-only":   TemporaryOnly,
+	"longest-lifetime": LongestLifetime,
+	"eui64-only":       EUI64Only,
+}
+
+// keep return infos which match
+func keep(infos []AddrInfo, match func(AddrInfo) bool) []AddrInfo {
+	var res []AddrInfo
+	for _, info := range infos {
+		if match(info) {
+			res = append(res, info)
+		}
+	}
+	return res
+}
+
+// StableOnly reserve usable addresses which are not temporary, they do not rotate and stay reachable
+var StableOnly Policy = func(infos []AddrInfo) []AddrInfo {
+	return keep(infos, func(a AddrInfo) bool {
+		return a.Usable() && !a.Temporary
+	})
+}
+
+// TemporaryOnly reserve usable temporary addresses
+var TemporaryOnly Policy = func(infos []AddrInfo) []AddrInfo {
+	return keep(infos, func(a AddrInfo) bool {
+		return a.Usable() && a.Temporary
+	})
+}
+
+// EUI64Only reserve usable addresses whose interface id is derived from a MAC address
+var EUI64Only Policy = func(infos []AddrInfo) []AddrInfo {
+	return keep(infos, func(a AddrInfo) bool {
+		return a.Usable() && a.IsEUI64()
+	})
+}
+
+// LongestLifetime reserve usable addresses and sort them by preferred lifetime, then valid lifetime, the longest first
+var LongestLifetime Policy = func(infos []AddrInfo) []AddrInfo {
+	res := keep(infos, AddrInfo.Usable)
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].PreferredLifetime != res[j].PreferredLifetime {
+			return res[i].PreferredLifetime > res[j].PreferredLifetime
+		}
+		return res[i].ValidLifetime > res[j].ValidLifetime
+	})
+	return res
+}
+
+// NewSuffixMatcher return a Policy reserving usable addresses whose interface id (the last 64 bits) is the one of suffix
+// e.g. suffix ::1:2:3:4 matches 2001:db8:1:2:1:2:3:4 but not 2001:db8:1:2:1:2:3:5
+func NewSuffixMatcher(suffix netip.Addr) Policy {
+	want := suffix.As16()
+	return func(infos []AddrInfo) []AddrInfo {
+		return keep(infos, func(a AddrInfo) bool {
+			ip, err := netip.ParseAddr(a.IP)
+			if err != nil || !ip.Is6() || !a.Usable() {
+				return false
+			}
+			got := ip.As16()
+			return [8]byte(got[8:]) == [8]byte(want[8:])
+		})
+	}
+}
+
+// parsePolicy parse the name of a Policy, "suffix:<ipv6>" matches the interface id
+// ok is false if name is not a Policy
+func parsePolicy(name string) (policy Policy, ok bool, err error) {
+	if suffix, found := strings.CutPrefix(name, "suffix:"); found {
+		ip, err := netip.ParseAddr(suffix)
+		if err != nil || !ip.Is6() || ip.Is4In6() {
+			return nil, true, fmt.Errorf("invalid ipv6 suffix %s", suffix)
+		}
+		return NewSuffixMatcher(ip), true, nil
+	}
+	policy, ok = policies[name]
+	return policy, ok, nil
+}
+
+// getAddrInfo is replaced in tests
+var getAddrInfo = GetAddrInfo
@@ -0,0 +1,114 @@
+//go:build linux
+
+package netutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	// ifaFlags is IFA_FLAGS, the 32-bit flags which supersede the 8-bit ones of ifaddrmsg
+	ifaFlags = 0x8
+	// ifaFlagDadFailed is IFA_F_DADFAILED
+	ifaFlagDadFailed = 0x8
+	// infiniteLifetime is INFINITY_LIFE_TIME of ifa_cacheinfo
+	infiniteLifetime = 0xffffffff
+)
+
+// GetAddrInfo return the ipv6 addresses of device with their flags and lifetimes read from netlink
+func GetAddrInfo(device string) ([]AddrInfo, error) {
+	iface, err := net.InterfaceByName(device)
+	if err != nil {
+		return nil, err
+	}
+	rib, err := syscall.NetlinkRIB(syscall.RTM_GETADDR, syscall.AF_INET6)
+	if err != nil {
+		return nil, fmt.Errorf("error dumping addresses from netlink: %w", err)
+	}
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing netlink messages: %w", err)
+	}
+	return parseAddrMessages(msgs, iface.Index)
+}
+
+// parseAddrMessages return the ipv6 addresses of interface index in RTM_NEWADDR messages
+func parseAddrMessages(msgs []syscall.NetlinkMessage, index int) ([]AddrInfo, error) {
+	var infos []AddrInfo
+	for i := range msgs {
+		m := &msgs[i]
+		switch m.Header.Type {
+		case syscall.NLMSG_DONE:
+			return infos, nil
+		case syscall.NLMSG_ERROR:
+			return nil, errors.New("netlink answered an error")
+		case syscall.RTM_NEWADDR:
+		default:
+			continue
+		}
+		if len(m.Data) < syscall.SizeofIfAddrmsg {
+			return nil, errors.New("truncated ifaddrmsg")
+		}
+		ifam := (*syscall.IfAddrmsg)(unsafe.Pointer(&m.Data[0]))
+		if ifam.Family != syscall.AF_INET6 || int(ifam.Index) != index {
+			continue
+		}
+		attrs, err := syscall.ParseNetlinkRouteAttr(m)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing address attributes: %w", err)
+		}
+
+		info := AddrInfo{
+			PrefixLength:      int(ifam.Prefixlen),
+			PreferredLifetime: InfiniteLifetime,
+			ValidLifetime:     InfiniteLifetime,
+		}
+		flags := uint32(ifam.Flags)
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case syscall.IFA_ADDRESS:
+				if len(attr.Value) == net.IPv6len {
+					info.IP = net.IP(attr.Value).String()
+				}
+			case ifaFlags:
+				if len(attr.Value) >= 4 {
+					flags = nativeUint32(attr.Value)
+				}
+			case syscall.IFA_CACHEINFO:
+				// struct ifa_cacheinfo { ifa_prefered, ifa_valid, cstamp, tstamp }
+				if len(attr.Value) >= 8 {
+					info.PreferredLifetime = lifetime(nativeUint32(attr.Value[0:4]))
+					info.ValidLifetime = lifetime(nativeUint32(attr.Value[4:8]))
+				}
+			}
+		}
+		if info.IP == "" {
+			continue
+		}
+		info.Temporary = flags&syscall.IFA_F_TEMPORARY != 0
+		info.Deprecated = flags&syscall.IFA_F_DEPRECATED != 0
+		info.Tentative = flags&syscall.IFA_F_TENTATIVE != 0
+		info.DadFailed = flags&ifaFlagDadFailed != 0
+		info.Permanent = flags&syscall.IFA_F_PERMANENT != 0
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// lifetime convert lifetime in seconds of ifa_cacheinfo to time.Duration
+func lifetime(seconds uint32) time.Duration {
+	if seconds == infiniteLifetime {
+		return InfiniteLifetime
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// nativeUint32 read uint32 in host byte order as netlink does
+func nativeUint32(b []byte) uint32 {
+	return *(*uint32)(unsafe.Pointer(&b[0]))
+}
@@ -0,0 +1,107 @@
+//go:build linux
+
+package netutil
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// rtattr build a route attribute padded to 4 bytes
+func rtattr(typ uint16, value []byte) []byte {
+	b := make([]byte, (syscall.SizeofRtAttr+len(value)+3)&^3)
+	*(*uint16)(unsafe.Pointer(&b[0])) = uint16(syscall.SizeofRtAttr + len(value))
+	*(*uint16)(unsafe.Pointer(&b[2])) = typ
+	copy(b[syscall.SizeofRtAttr:], value)
+	return b
+}
+
+func native32(v ...uint32) []byte {
+	b := make([]byte, 4*len(v))
+	for i := range v {
+		*(*uint32)(unsafe.Pointer(&b[4*i])) = v[i]
+	}
+	return b
+}
+
+// newAddrMessage build a RTM_NEWADDR message
+func newAddrMessage(index uint32, ip string, flags uint8, attrs ...[]byte) []byte {
+	msg := make([]byte, syscall.SizeofNlMsghdr+syscall.SizeofIfAddrmsg)
+	ifam := (*syscall.IfAddrmsg)(unsafe.Pointer(&msg[syscall.SizeofNlMsghdr]))
+	ifam.Family, ifam.Prefixlen, ifam.Flags, ifam.Index = syscall.AF_INET6, 64, flags, index
+	msg = append(msg, rtattr(syscall.IFA_ADDRESS, net.ParseIP(ip).To16())...)
+	for _, attr := range attrs {
+		msg = append(msg, attr...)
+	}
+	hdr := (*syscall.NlMsghdr)(unsafe.Pointer(&msg[0]))
+	hdr.Len, hdr.Type = uint32(len(msg)), syscall.RTM_NEWADDR
+	return msg
+}
+
+func TestParseAddrMessages(t *testing.T) {
+	var rib []byte
+	rib = append(rib, newAddrMessage(2, "2001:db8::1", syscall.IFA_F_PERMANENT)...)
+	// 32-bit IFA_FLAGS supersedes the flags of ifaddrmsg
+	rib = append(rib, newAddrMessage(2, "2001:db8::2", 0,
+		rtattr(ifaFlags, native32(syscall.IFA_F_TEMPORARY|syscall.IFA_F_DEPRECATED)),
+		rtattr(syscall.IFA_CACHEINFO, native32(0, 600, 1, 1)))...)
+	rib = append(rib, newAddrMessage(2, "2001:db8::3", syscall.IFA_F_TENTATIVE,
+		rtattr(syscall.IFA_CACHEINFO, native32(1800, 3600, 1, 1)))...)
+	// other interface
+	rib = append(rib, newAddrMessage(3, "2001:db8::4", 0)...)
+
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, err := parseAddrMessages(msgs, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []AddrInfo{
+		{IP: "2001:db8::1", PrefixLength: 64, Permanent: true, PreferredLifetime: InfiniteLifetime, ValidLifetime: InfiniteLifetime},
+		{IP: "2001:db8::2", PrefixLength: 64, Temporary: true, Deprecated: true, ValidLifetime: 600 * time.Second},
+		{IP: "2001:db8::3", PrefixLength: 64, Tentative: true, PreferredLifetime: 1800 * time.Second, ValidLifetime: 3600 * time.Second},
+	}
+	if len(infos) != len(expect) {
+		t.Fatalf("expect %v, got %v", expect, infos)
+	}
+	for i := range infos {
+		if infos[i] != expect[i] {
+			t.Errorf("expect %+v, got %+v", expect[i], infos[i])
+		}
+	}
+}
+
+func TestGetAddrInfoLoopback(t *testing.T) {
+	lo, err := net.InterfaceByName("lo")
+	if err != nil {
+		t.Skip("no loopback interface")
+	}
+	addrs, _ := lo.Addrs()
+	hasIPv6 := false
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(net.IPv6loopback) {
+			hasIPv6 = true
+		}
+	}
+	if !hasIPv6 {
+		t.Skip("no ::1 on loopback interface")
+	}
+	infos, err := GetAddrInfo("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		if info.IP == "::1" {
+			if !info.Permanent || info.PrefixLength != 128 || info.ValidLifetime != InfiniteLifetime {
+				t.Errorf("unexpected address info of ::1: %+v", info)
+			}
+			return
+		}
+	}
+	t.Errorf("::1 not found in %+v", infos)
+}
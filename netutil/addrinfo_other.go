@@ -0,0 +1,36 @@
+//go:build !linux
+
+package netutil
+
+import (
+	"net"
+)
+
+// GetAddrInfo return the ipv6 addresses of device
+// flags and lifetimes are not available, so every address looks like a static one
+func GetAddrInfo(device string) ([]AddrInfo, error) {
+	iface, err := net.InterfaceByName(device)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	var infos []AddrInfo
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() != nil {
+			continue
+		}
+		ones, _ := ipNet.Mask.Size()
+		infos = append(infos, AddrInfo{
+			IP:                ipNet.IP.String(),
+			PrefixLength:      ones,
+			Permanent:         true,
+			PreferredLifetime: InfiniteLifetime,
+			ValidLifetime:     InfiniteLifetime,
+		})
+	}
+	return infos, nil
+}
@@ -0,0 +1,119 @@
+package netutil
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+var testInfos = []AddrInfo{
+	{IP: "2001:db8::1:2:3:4", PreferredLifetime: time.Hour, ValidLifetime: 2 * time.Hour},
+	{IP: "2001:db8::a8b:ccff:fedd:eeff", PreferredLifetime: 3 * time.Hour, ValidLifetime: 3 * time.Hour},
+	{IP: "2001:db8::dead:beef", Temporary: true, PreferredLifetime: 5 * time.Hour, ValidLifetime: 5 * time.Hour},
+	{IP: "2001:db8::bad", Temporary: true, Deprecated: true, ValidLifetime: time.Hour},
+	{IP: "2001:db8::9", Tentative: true, PreferredLifetime: InfiniteLifetime, ValidLifetime: InfiniteLifetime},
+	{IP: "2001:db8::10", Permanent: true, PreferredLifetime: InfiniteLifetime, ValidLifetime: InfiniteLifetime},
+}
+
+func ipsOf(infos []AddrInfo) []string {
+	var ips []string
+	for _, info := range infos {
+		ips = append(ips, info.IP)
+	}
+	return ips
+}
+
+func equalIps(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPolicies(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy Policy
+		expect []string
+	}{
+		{"stable-only", StableOnly, []string{"2001:db8::1:2:3:4", "2001:db8::a8b:ccff:fedd:eeff", "2001:db8::10"}},
+		{"temporary-only", TemporaryOnly, []string{"2001:db8::dead:beef"}},
+		{"eui64-only", EUI64Only, []string{"2001:db8::a8b:ccff:fedd:eeff"}},
+		{"longest-lifetime", LongestLifetime, []string{"2001:db8::10", "2001:db8::dead:beef", "2001:db8::a8b:ccff:fedd:eeff", "2001:db8::1:2:3:4"}},
+		{"suffix", NewSuffixMatcher(netip.MustParseAddr("::1:2:3:4")), []string{"2001:db8::1:2:3:4"}},
+	}
+	for _, c := range cases {
+		if res := ipsOf(c.policy(testInfos)); !equalIps(res, c.expect) {
+			t.Errorf("%s: expect %v, got %v", c.name, c.expect, res)
+		}
+	}
+}
+
+// useAddrInfo replace the address info of devices, restore it after test
+func useAddrInfo(t *testing.T, infos map[string][]AddrInfo) {
+	old := getAddrInfo
+	getAddrInfo = func(device string) ([]AddrInfo, error) {
+		info, ok := infos[device]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return info, nil
+	}
+	t.Cleanup(func() { getAddrInfo = old })
+}
+
+func TestFilterDeviceIp(t *testing.T) {
+	useAddrInfo(t, map[string][]AddrInfo{"eth0": testInfos})
+	ips := append([]string{"fe80::1"}, ipsOf(testInfos)...)
+	cases := []struct {
+		filter string
+		expect []string
+	}{
+		{"", ips},
+		{"global-unicast,stable-only,select:0", []string{"2001:db8::1:2:3:4"}},
+		{"global-unicast,Longest-Lifetime,select:0", []string{"2001:db8::10"}},
+		{"remove-private,suffix:::a8b:ccff:fedd:eeff", []string{"2001:db8::a8b:ccff:fedd:eeff"}},
+		// fe80::1 has no address info and looks like a static address
+		{"stable-only,eui64-only", []string{"2001:db8::a8b:ccff:fedd:eeff"}},
+		{"temporary-only", []string{"2001:db8::dead:beef"}},
+	}
+	for _, c := range cases {
+		res, err := FilterDeviceIp("eth0", ips, c.filter)
+		if err != nil {
+			t.Errorf("%q: %s", c.filter, err)
+			continue
+		}
+		if !equalIps(res, c.expect) {
+			t.Errorf("%q: expect %v, got %v", c.filter, c.expect, res)
+		}
+	}
+
+	// ipv4 is kept and address info is not needed
+	res, err := FilterDeviceIp("eth1", []string{"192.168.1.2"}, "stable-only")
+	if err != nil || !equalIps(res, []string{"192.168.1.2"}) {
+		t.Errorf("expect [192.168.1.2], got %v %v", res, err)
+	}
+	if _, err := FilterDeviceIp("eth1", []string{"2001:db8::1"}, "stable-only"); err == nil {
+		t.Error("expect error without address info of device")
+	}
+}
+
+func TestParseFilterPolicy(t *testing.T) {
+	if err := ValidateFilter("remove-private,stable-only,suffix:::1:2:3:4,select:0"); err != nil {
+		t.Error(err)
+	}
+	for _, filter := range []string{"suffix:1.2.3.4", "suffix:", "suffix:::ffff:1.2.3.4"} {
+		if err := ValidateFilter(filter); err == nil {
+			t.Errorf("expect error validating %q", filter)
+		}
+	}
+	if _, err := ParseFilter("stable-only"); err == nil {
+		t.Error("expect error parsing policy without device")
+	}
+}
@@ -0,0 +1,196 @@
+package netutil
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// names of dns apis in ApiMap
+const (
+	OpenDNS   = "openDNS"
+	GoogleDNS = "googleDNS"
+)
+
+const dnsTimeout = 5 * time.Second
+
+// dnsApi get public ip by asking a dns server how it sees us
+// the query of ipv4 is sent over ipv4 and the query of ipv6 over ipv6, so the resolver should be reachable over both
+type dnsApi struct {
+	// name to query
+	name string
+	// ip is answered in TXT record instead of A/AAAA
+	txt bool
+	// resolver address like "resolver1.opendns.com:53"
+	resolver string
+}
+
+var dnsApisMu sync.RWMutex
+
+// dnsApis contains the dns apis, resolvers can be changed by SetDNSResolver
+var dnsApis = map[string]*dnsApi{
+	OpenDNS:   {name: "myip.opendns.com.", resolver: "resolver1.opendns.com:53"},
+	GoogleDNS: {name: "o-o.myaddr.l.google.com.", txt: true, resolver: "ns1.google.com:53"},
+}
+
+var getIPFromOpenDNSApi = Api{
+	Get: func(t Type) (string, error) {
+		return getIPFromDNS(OpenDNS, t)
+	},
+}
+
+var getIPFromGoogleDNSApi = Api{
+	Get: func(t Type) (string, error) {
+		return getIPFromDNS(GoogleDNS, t)
+	},
+}
+
+// SetDNSResolver set the resolver of a dns api, port 53 is used if addr has no port
+// a local dns stub can stand in for the resolver in this way
+func SetDNSResolver(api string, addr string) error {
+	dnsApisMu.Lock()
+	defer dnsApisMu.Unlock()
+	d, ok := dnsApis[api]
+	if !ok {
+		return fmt.Errorf("unknown dns api: %s", api)
+	}
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return fmt.Errorf("empty resolver of dns api %s", api)
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(strings.Trim(addr, "[]"), "53")
+	}
+	d.resolver = addr
+	return nil
+}
+
+// GetDNSResolver return the resolver of a dns api
+func GetDNSResolver(api string) (string, error) {
+	dnsApisMu.RLock()
+	defer dnsApisMu.RUnlock()
+	d, ok := dnsApis[api]
+	if !ok {
+		return "", fmt.Errorf("unknown dns api: %s", api)
+	}
+	return d.resolver, nil
+}
+
+// getIPFromDNS get ip of Type from dns api
+func getIPFromDNS(api string, Type uint8) (string, error) {
+	dnsApisMu.RLock()
+	d, ok := dnsApis[api]
+	if !ok {
+		dnsApisMu.RUnlock()
+		return "", fmt.Errorf("unknown dns api: %s", api)
+	}
+	query := *d
+	dnsApisMu.RUnlock()
+	return query.get(Type)
+}
+
+// get send the query to resolver over udp4 for A and udp6 for AAAA, return the ip in answer
+func (d dnsApi) get(Type uint8) (string, error) {
+	var network string
+	var qtype dnsmessage.Type
+	switch Type {
+	case A:
+		network, qtype = "udp4", dnsmessage.TypeA
+	case AAAA:
+		network, qtype = "udp6", dnsmessage.TypeAAAA
+	default:
+		return "", NewUnknownType(Type)
+	}
+	if d.txt {
+		qtype = dnsmessage.TypeTXT
+	}
+
+	name, err := dnsmessage.NewName(d.name)
+	if err != nil {
+		return "", err
+	}
+	id := newDNSId()
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id})
+	_ = b.StartQuestions()
+	if err := b.Question(dnsmessage.Question{Name: name, Type: qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return "", err
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := net.DialTimeout(network, d.resolver, dnsTimeout)
+	if err != nil {
+		return "", fmt.Errorf("error connecting to %s over %s: %w", d.resolver, network, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(dnsTimeout))
+	if _, err := conn.Write(msg); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 1232)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("error reading response from %s: %w", d.resolver, err)
+		}
+		var p dnsmessage.Parser
+		header, err := p.Start(buf[:n])
+		// drop stray packets
+		if err != nil || header.ID != id || !header.Response {
+			continue
+		}
+		if header.RCode != dnsmessage.RCodeSuccess {
+			return "", fmt.Errorf("query %s %s at %s failed: %s", d.name, qtype, d.resolver, header.RCode)
+		}
+		if err := p.SkipAllQuestions(); err != nil {
+			return "", err
+		}
+		answers, err := p.AllAnswers()
+		if err != nil {
+			return "", err
+		}
+		return ipInAnswers(answers, Type)
+	}
+}
+
+// ipInAnswers return the first ip of Type in answers, TXT like "1.2.3.4" is accepted too
+// other TXT records like "edns0-client-subnet 1.2.3.0/24" are ignored
+func ipInAnswers(answers []dnsmessage.Resource, Type uint8) (string, error) {
+	for _, answer := range answers {
+		var ip string
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			ip = netip.AddrFrom4(body.A).String()
+		case *dnsmessage.AAAAResource:
+			ip = netip.AddrFrom16(body.AAAA).String()
+		case *dnsmessage.TXTResource:
+			for _, s := range body.TXT {
+				if WhichType(s) == Type {
+					ip = s
+					break
+				}
+			}
+		}
+		if ip != "" && WhichType(ip) == Type {
+			return ip, nil
+		}
+	}
+	return "", errors.New("no ip in dns answer")
+}
+
+func newDNSId() uint16 {
+	b := make([]byte, 2)
+	_, _ = rand.Read(b)
+	return binary.BigEndian.Uint16(b)
+}
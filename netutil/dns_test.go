@@ -0,0 +1,123 @@
+package netutil
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsStub answers queries with the address of the client like OpenDNS and Google do
+func dnsStub(t *testing.T, network string, addr string) string {
+	conn, err := net.ListenPacket(network, addr)
+	if err != nil {
+		t.Skipf("can not listen on %s %s: %s", network, addr, err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, remote, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var p dnsmessage.Parser
+			header, err := p.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			q, err := p.Question()
+			if err != nil {
+				continue
+			}
+			client := remote.(*net.UDPAddr).AddrPort().Addr().Unmap()
+
+			b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true})
+			_ = b.StartQuestions()
+			_ = b.Question(q)
+			_ = b.StartAnswers()
+			h := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET}
+			switch {
+			case q.Type == dnsmessage.TypeTXT:
+				_ = b.TXTResource(h, dnsmessage.TXTResource{TXT: []string{"edns0-client-subnet 10.0.0.0/24"}})
+				_ = b.TXTResource(h, dnsmessage.TXTResource{TXT: []string{client.String()}})
+			case q.Type == dnsmessage.TypeA && client.Is4():
+				_ = b.AResource(h, dnsmessage.AResource{A: client.As4()})
+			case q.Type == dnsmessage.TypeAAAA && client.Is6():
+				_ = b.AAAAResource(h, dnsmessage.AAAAResource{AAAA: client.As16()})
+			}
+			res, _ := b.Finish()
+			_, _ = conn.WriteTo(res, remote)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+// useResolver set resolver of api and restore it after test
+func useResolver(t *testing.T, api string, resolver string) {
+	old, err := GetDNSResolver(api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDNSResolver(api, resolver); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetDNSResolver(api, old) })
+}
+
+func TestGetIPFromDNS(t *testing.T) {
+	cases := []struct {
+		network string
+		addr    string
+		Type    Type
+		expect  string
+	}{
+		{"udp4", "127.0.0.1:0", A, "127.0.0.1"},
+		{"udp6", "[::1]:0", AAAA, "::1"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.network, func(t *testing.T) {
+			resolver := dnsStub(t, c.network, c.addr)
+			for _, name := range [2]string{OpenDNS, GoogleDNS} {
+				useResolver(t, name, resolver)
+				api, err := ApiMap.GetApi(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ip, err := api.Get(c.Type)
+				if err != nil {
+					t.Fatalf("%s: %s", name, err)
+				}
+				if ip != c.expect {
+					t.Errorf("%s: expect %s, got %s", name, c.expect, ip)
+				}
+			}
+		})
+	}
+}
+
+func TestGetIPFromDNSWrongTransport(t *testing.T) {
+	// an ipv4 resolver can not be reached over ipv6
+	resolver := dnsStub(t, "udp4", "127.0.0.1:0")
+	useResolver(t, OpenDNS, resolver)
+	if ip, err := getIPFromDNS(OpenDNS, AAAA); err == nil {
+		t.Errorf("expect error, got %s", ip)
+	}
+}
+
+func TestSetDNSResolver(t *testing.T) {
+	useResolver(t, OpenDNS, "2620:119:35::35")
+	resolver, _ := GetDNSResolver(OpenDNS)
+	if resolver != "[2620:119:35::35]:53" {
+		t.Errorf("default port should be added, got %s", resolver)
+	}
+	if _, err := netip.ParseAddrPort(resolver); err != nil {
+		t.Error(err)
+	}
+
+	if err := SetDNSResolver("unknown", "127.0.0.1"); err == nil {
+		t.Error("expect error setting resolver of unknown api")
+	}
+}
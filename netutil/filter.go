@@ -0,0 +1,159 @@
+package netutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// filters are the IpHandlers which can be declared in filter pipeline by name
+var filters = map[string]IpHandler{
+	"remove-private":        RemovePrivate,
+	"private-only":          ReservePrivateOnly,
+	"remove-loopback":       RemoveLoopback,
+	"loopback-only":         ReserveLoopbackOnly,
+	"global-unicast":        ReserveGlobalUnicast,
+	"remove-global-unicast": RemoveGlobalUnicast,
+	"remove-invalid":        RemoveInvalid,
+}
+
+// filterStep is a step of filter pipeline, either an IpHandler or a Policy selecting ipv6 of a device
+type filterStep struct {
+	handler IpHandler
+	policy  Policy
+}
+
+// parseSteps parse filter pipeline into steps in order
+func parseSteps(filter string) ([]filterStep, error) {
+	fields := strings.Fields(strings.ReplaceAll(filter, ",", " "))
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	steps := make([]filterStep, 0, len(fields))
+	for _, field := range fields {
+		name := strings.ToLower(field)
+		if no, ok := strings.CutPrefix(name, "select:"); ok {
+			n, err := strconv.ParseUint(no, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %s: %w", field, err)
+			}
+			steps = append(steps, filterStep{handler: NewSelector(n)})
+			continue
+		}
+		if handler, ok := filters[name]; ok {
+			steps = append(steps, filterStep{handler: handler})
+			continue
+		}
+		policy, ok, err := parsePolicy(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %s: %w", field, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %s", field)
+		}
+		steps = append(steps, filterStep{policy: policy})
+	}
+	return steps, nil
+}
+
+// ParseFilter parse filter pipeline like "remove-private,remove-loopback,global-unicast,select:0" into IpHandlers in order
+// names are case-insensitive, "select:n" selects the n-th ip (start from 0) left
+// a selector is stateful, so parse the filter again for each list of ips
+// empty filter returns nil IpHandlers and nil error
+// policies like stable-only need the device of ips, they are rejected here, use FilterDeviceIp instead
+func ParseFilter(filter string) ([]IpHandler, error) {
+	steps, err := parseSteps(filter)
+	if err != nil {
+		return nil, err
+	}
+	var handlers []IpHandler
+	for _, step := range steps {
+		if step.handler == nil {
+			return nil, fmt.Errorf("filter %s selects ipv6 by address flags and needs the device of ips", filter)
+		}
+		handlers = append(handlers, step.handler)
+	}
+	return handlers, nil
+}
+
+// ValidateFilter check the names in filter pipeline, both IpHandlers and Policies are accepted
+func ValidateFilter(filter string) error {
+	_, err := parseSteps(filter)
+	return err
+}
+
+// FilterIp apply the filter pipeline to ips, return the ips left
+// ips are returned as they are if filter is empty
+func FilterIp(ips []string, filter string) ([]string, error) {
+	handlers, err := ParseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return HandleIp(ips, handlers...)
+}
+
+// FilterDeviceIp apply the filter pipeline to ips of device, return the ips left
+// policies like stable-only, temporary-only, longest-lifetime, eui64-only and suffix:<ipv6> select ipv6 by flags
+// and lifetimes of addresses of device, ipv4 is kept as it is by them
+func FilterDeviceIp(device string, ips []string, filter string) ([]string, error) {
+	steps, err := parseSteps(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos map[string]AddrInfo
+	var errs error
+	for _, step := range steps {
+		if step.handler != nil {
+			var err error
+			ips, err = HandleIp(ips, step.handler)
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if infos == nil && hasIPv6(ips) {
+			got, err := getAddrInfo(device)
+			if err != nil {
+				return nil, fmt.Errorf("error getting address info of %s: %w", device, err)
+			}
+			infos = make(map[string]AddrInfo, len(got))
+			for _, info := range got {
+				infos[info.IP] = info
+			}
+		}
+		ips = applyPolicy(ips, infos, step.policy)
+	}
+	return ips, errs
+}
+
+// hasIPv6 return true if there is any ipv6 in ips
+func hasIPv6(ips []string) bool {
+	for _, ip := range ips {
+		if WhichType(ip) == AAAA {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPolicy apply policy to ipv6 in ips, ipv6 without address info is treated as a static address
+// ipv4 is kept in front of the selected ipv6
+func applyPolicy(ips []string, infos map[string]AddrInfo, policy Policy) []string {
+	var res []string
+	var candidates []AddrInfo
+	for _, ip := range ips {
+		if WhichType(ip) != AAAA {
+			res = append(res, ip)
+			continue
+		}
+		info, ok := infos[ip]
+		if !ok {
+			info = AddrInfo{IP: ip, Permanent: true, PreferredLifetime: InfiniteLifetime, ValidLifetime: InfiniteLifetime}
+		}
+		candidates = append(candidates, info)
+	}
+	for _, info := range policy(candidates) {
+		res = append(res, info.IP)
+	}
+	return res
+}
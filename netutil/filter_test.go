@@ -0,0 +1,56 @@
+package netutil
+
+import (
+	"testing"
+)
+
+func TestFilterIp(t *testing.T) {
+	ips := []string{"127.0.0.1", "192.168.1.2", "8.8.8.8", "fe80::1", "::1", "fd00::1", "2001:db8::68", "2001:db8::69"}
+	cases := []struct {
+		filter string
+		expect []string
+	}{
+		{"", ips},
+		{"remove-private,remove-loopback,global-unicast", []string{"8.8.8.8", "2001:db8::68", "2001:db8::69"}},
+		{"Remove-Private, global-unicast, select:1", []string{"2001:db8::68"}},
+		{"private-only", []string{"192.168.1.2", "fd00::1"}},
+		{"loopback-only", []string{"127.0.0.1", "::1"}},
+		{"remove-loopback,select:0", []string{"192.168.1.2"}},
+		{"select:7", []string{"2001:db8::69"}},
+		{"select:8", nil},
+	}
+	for _, c := range cases {
+		res, err := FilterIp(ips, c.filter)
+		if err != nil {
+			t.Errorf("%q: %s", c.filter, err)
+			continue
+		}
+		if len(res) != len(c.expect) {
+			t.Errorf("%q: expect %v, got %v", c.filter, c.expect, res)
+			continue
+		}
+		for i := range res {
+			if res[i] != c.expect[i] {
+				t.Errorf("%q: expect %v, got %v", c.filter, c.expect, res)
+				break
+			}
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	for _, filter := range []string{"remove-public", "select:-1", "select:a", "select"} {
+		if _, err := ParseFilter(filter); err == nil {
+			t.Errorf("expect error parsing %q", filter)
+		}
+	}
+
+	// selector is not shared between parses
+	ips := []string{"1.1.1.1", "2.2.2.2"}
+	for i := 0; i < 2; i++ {
+		res, err := FilterIp(ips, "select:1")
+		if err != nil || len(res) != 1 || res[0] != "2.2.2.2" {
+			t.Errorf("expect [2.2.2.2], got %v %v", res, err)
+		}
+	}
+}
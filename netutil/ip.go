@@ -7,7 +7,9 @@ import (
 	"net"
 	"net/netip"
 	"regexp"
+	"strings"
 
+	"GodDns/util/collections"
 	"github.com/go-resty/resty/v2"
 )
 
@@ -53,6 +55,11 @@ var ApiMap = Apis{
 	a: map[string]Api{
 		"ipify":   getIPFromIpifyApi,
 		"identMe": getIPFromIdentMeApi,
+		OpenDNS:   getIPFromOpenDNSApi,
+		GoogleDNS: getIPFromGoogleDNSApi,
+		Stun:      getIPFromStunApi,
+		UPnP:      getIPFromUPnPApi,
+		NATPMP:    getIPFromNATPMPApi,
 	},
 }
 
@@ -414,10 +421,55 @@ func IsTypeValid(Type string) bool {
 	}
 }
 
+// ParseTypes parse types like "A,AAAA", "4,6" or "both" of a dual-stack section,
+// return "A" and "AAAA" in the order of appearance without duplicates, an invalid type is returned as ""
+func ParseTypes(types string) []string {
+	var res []string
+	for _, t := range strings.Fields(strings.ReplaceAll(types, ",", " ")) {
+		if strings.EqualFold(t, "both") {
+			res = collections.AppendUnique(res, "A", "AAAA")
+		} else {
+			res = collections.AppendUnique(res, Type2Str(t))
+		}
+	}
+	if len(res) == 0 {
+		return []string{""}
+	}
+	return res
+}
+
+// ValuesOfTypes assign values like "1.2.3.4,2001:db8::1" to types by ip family,
+// value is kept as is if there is only one type, and a type without matched value gets ""
+func ValuesOfTypes(value string, types []string) map[string]string {
+	res := make(map[string]string, len(types))
+	if len(types) == 1 {
+		res[types[0]] = value
+		return res
+	}
+	for _, v := range strings.Fields(strings.ReplaceAll(value, ",", " ")) {
+		if t := WhichTypeStr(v); t != "" {
+			if _, ok := res[t]; !ok {
+				res[t] = v
+			}
+		}
+	}
+	return res
+}
+
 func IsIpValid(ip string) bool {
 	return net.ParseIP(ip) != nil
 }
 
+// IpEqual check if two ip addresses are the same, like "2001:db8::1" and "2001:0db8:0:0:0:0:0:1"
+// compare as string if any of them is invalid
+func IpEqual(ip1, ip2 string) bool {
+	a, b := net.ParseIP(ip1), net.ParseIP(ip2)
+	if a == nil || b == nil {
+		return ip1 == ip2
+	}
+	return a.Equal(b)
+}
+
 // ----------------------------------------------------------- //
 
 // basicHandler is a basic handler
@@ -565,6 +617,13 @@ var ReserveGlobalUnicastOnly IpHandler = func(ip string) (string, error) {
 	}, r)
 }
 
+// ReserveGlobalUnicast reserve globalUnicast ip of both ipv4 and ipv6, remove link-local, loopback, multicast etc.
+// unlike ReserveGlobalUnicastOnly, ipv4 is kept if it is a globalUnicast ip
+var ReserveGlobalUnicast IpHandler = func(ip string) (string, error) {
+	r := globalUnicast(true)
+	return basicHandler(ip, r, r)
+}
+
 // RemoveGlobalUnicast remove globalUnicast ip
 var RemoveGlobalUnicast IpHandler = func(ip string) (string, error) {
 	r := globalUnicast(false)
@@ -151,6 +151,25 @@ func TestHandler(t *testing.T) {
 	fmt.Println(ips)
 }
 
+func TestIpEqual(t *testing.T) {
+	cases := []struct {
+		ip1, ip2 string
+		equal    bool
+	}{
+		{"1.2.3.4", "1.2.3.4", true},
+		{"1.2.3.4", "1.2.3.5", false},
+		{"2001:db8::1", "2001:0db8:0:0:0:0:0:1", true},
+		{"::ffff:1.2.3.4", "1.2.3.4", true},
+		{"", "1.2.3.4", false},
+		{"invalid", "invalid", true},
+	}
+	for _, c := range cases {
+		if IpEqual(c.ip1, c.ip2) != c.equal {
+			t.Errorf("IpEqual(%s, %s) should be %v", c.ip1, c.ip2, c.equal)
+		}
+	}
+}
+
 func isIpValid2(ip string) bool {
 	if _, err := netip.ParseAddr(ip); err != nil {
 		return false
@@ -171,3 +190,36 @@ func BenchmarkIsIpValid2(b *testing.B) {
 		_ = b
 	}
 }
+
+func TestParseTypes(t *testing.T) {
+	tests := map[string][]string{
+		"A":          {"A"},
+		"6":          {"AAAA"},
+		"A,AAAA":     {"A", "AAAA"},
+		"6, 4, AAAA": {"AAAA", "A"},
+		"both":       {"A", "AAAA"},
+		"A/AAAA/4/6": {""},
+		"":           {""},
+	}
+	for types, expected := range tests {
+		if res := ParseTypes(types); fmt.Sprint(res) != fmt.Sprint(expected) {
+			t.Errorf("ParseTypes(%q) = %v, expect %v", types, res, expected)
+		}
+	}
+}
+
+func TestValuesOfTypes(t *testing.T) {
+	values := ValuesOfTypes("2001:db8::1,1.2.3.4", []string{"A", "AAAA"})
+	if values["A"] != "1.2.3.4" || values["AAAA"] != "2001:db8::1" {
+		t.Errorf("unexpected values %v", values)
+	}
+	values = ValuesOfTypes("1.2.3.4", []string{"A", "AAAA"})
+	if values["A"] != "1.2.3.4" || values["AAAA"] != "" {
+		t.Errorf("unexpected values %v", values)
+	}
+	// kept as is with only one type
+	values = ValuesOfTypes("1.2.3.4", []string{"AAAA"})
+	if values["AAAA"] != "1.2.3.4" {
+		t.Errorf("unexpected values %v", values)
+	}
+}
@@ -0,0 +1,238 @@
+package netutil
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATPMP is the name of NAT-PMP/PCP api in ApiMap
+const NATPMP = "natpmp"
+
+const (
+	natpmpPort           = "5351"
+	natpmpDefaultTimeout = 3 * time.Second
+	// natpmpInitialRetry is the first interval of retransmission, doubled after each retry (RFC 6886 3.1)
+	natpmpInitialRetry = 250 * time.Millisecond
+
+	natpmpVersion          = 0
+	natpmpOpPublicAddress  = 0
+	natpmpResultSuccess    = 0
+	natpmpResultUnsuppVers = 1
+
+	pcpVersion       = 2
+	pcpOpMap         = 1
+	pcpResultSuccess = 0
+	pcpHeaderSize    = 24
+	pcpMapSize       = 36
+	// pcpMapLifetime is the lifetime of the mapping requested to learn the external address, it expires soon
+	pcpMapLifetime = 30
+	protocolUDP    = 17
+)
+
+// natpmpConfig contains the gateway asked for public address, "" means the default gateway
+type natpmpConfig struct {
+	mu      sync.RWMutex
+	gateway string
+	timeout time.Duration
+}
+
+var natpmpSettings = &natpmpConfig{
+	timeout: natpmpDefaultTimeout,
+}
+
+var getIPFromNATPMPApi = Api{
+	Get: getIPFromNATPMP,
+}
+
+// SetNATPMPGateway set the gateway asked for public address, port 5351 is used if addr has no port
+// "" means the default gateway
+func SetNATPMPGateway(addr string) {
+	addr = strings.TrimSpace(addr)
+	if addr != "" {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(strings.Trim(addr, "[]"), natpmpPort)
+		}
+	}
+	natpmpSettings.mu.Lock()
+	defer natpmpSettings.mu.Unlock()
+	natpmpSettings.gateway = addr
+}
+
+// GetNATPMPGateway return the gateway asked for public address, "" means the default gateway
+func GetNATPMPGateway() string {
+	natpmpSettings.mu.RLock()
+	defer natpmpSettings.mu.RUnlock()
+	return natpmpSettings.gateway
+}
+
+// SetNATPMPTimeout set the timeout of NAT-PMP/PCP request including retransmissions, default timeout is used if timeout <= 0
+func SetNATPMPTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = natpmpDefaultTimeout
+	}
+	natpmpSettings.mu.Lock()
+	defer natpmpSettings.mu.Unlock()
+	natpmpSettings.timeout = timeout
+}
+
+// GetNATPMPTimeout return the timeout of NAT-PMP/PCP request
+func GetNATPMPTimeout() time.Duration {
+	natpmpSettings.mu.RLock()
+	defer natpmpSettings.mu.RUnlock()
+	return natpmpSettings.timeout
+}
+
+// getIPFromNATPMP ask the gateway its public address by NAT-PMP (RFC 6886),
+// a PCP (RFC 6887) server answers with unsupported version, then a short MAP request is made to learn the assigned external address
+// only ipv4 is supported
+func getIPFromNATPMP(Type uint8) (string, error) {
+	switch Type {
+	case A:
+	case AAAA:
+		return "", errors.New("nat-pmp supports ipv4 only")
+	default:
+		return "", NewUnknownType(Type)
+	}
+
+	gateway, timeout := GetNATPMPGateway(), GetNATPMPTimeout()
+	if gateway == "" {
+		gw, err := defaultGateway()
+		if err != nil {
+			return "", fmt.Errorf("error finding default gateway, set it in settings: %w", err)
+		}
+		gateway = net.JoinHostPort(gw.String(), natpmpPort)
+	}
+
+	conn, err := net.DialTimeout("udp4", gateway, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(timeout)
+
+	res, err := natpmpExchange(conn, []byte{natpmpVersion, natpmpOpPublicAddress}, deadline, func(res []byte) bool {
+		return len(res) >= 2 && ((res[0] == natpmpVersion && res[1] == 128+natpmpOpPublicAddress) || res[0] == pcpVersion)
+	})
+	if err != nil {
+		return "", fmt.Errorf("error asking %s for public address: %w", gateway, err)
+	}
+
+	if res[0] == pcpVersion {
+		return pcpExternalAddress(conn, deadline)
+	}
+	if len(res) < 12 {
+		return "", errors.New("truncated nat-pmp response")
+	}
+	if result := binary.BigEndian.Uint16(res[2:]); result != natpmpResultSuccess {
+		return "", fmt.Errorf("nat-pmp error %d", result)
+	}
+	return netip.AddrFrom4([4]byte(res[8:12])).String(), nil
+}
+
+// pcpExternalAddress make a MAP request of the local udp port with a short lifetime, return the assigned external address
+func pcpExternalAddress(conn net.Conn, deadline time.Time) (string, error) {
+	local := conn.LocalAddr().(*net.UDPAddr).AddrPort()
+	var nonce [12]byte
+	_, _ = rand.Read(nonce[:])
+
+	req := make([]byte, pcpHeaderSize+pcpMapSize)
+	req[0], req[1] = pcpVersion, pcpOpMap
+	binary.BigEndian.PutUint32(req[4:], pcpMapLifetime)
+	clientIP := local.Addr().As16()
+	copy(req[8:24], clientIP[:])
+	m := req[pcpHeaderSize:]
+	copy(m[0:12], nonce[:])
+	m[12] = protocolUDP
+	binary.BigEndian.PutUint16(m[16:], local.Port())
+	// suggested external port and address are left zero
+	unspecified := netip.IPv4Unspecified().As16()
+	copy(m[20:36], unspecified[:])
+
+	res, err := natpmpExchange(conn, req, deadline, func(res []byte) bool {
+		return len(res) >= pcpHeaderSize+pcpMapSize && res[0] == pcpVersion && res[1] == 0x80|pcpOpMap &&
+			bytes.Equal(res[pcpHeaderSize:pcpHeaderSize+12], nonce[:])
+	})
+	if err != nil {
+		return "", fmt.Errorf("error making pcp map request: %w", err)
+	}
+	if result := res[3]; result != pcpResultSuccess {
+		return "", fmt.Errorf("pcp error %d", result)
+	}
+	addr := netip.AddrFrom16([16]byte(res[pcpHeaderSize+20 : pcpHeaderSize+36])).Unmap()
+	if !addr.Is4() {
+		return "", fmt.Errorf("invalid external ip %s", addr)
+	}
+	return addr.String(), nil
+}
+
+// natpmpExchange send req and retransmit it with doubled interval until a response matched or deadline
+func natpmpExchange(conn net.Conn, req []byte, deadline time.Time, match func([]byte) bool) ([]byte, error) {
+	buf := make([]byte, 1100)
+	for retry := natpmpInitialRetry; ; retry *= 2 {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		wait := time.Now().Add(retry)
+		if wait.After(deadline) {
+			wait = deadline
+		}
+		_ = conn.SetReadDeadline(wait)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() && time.Now().Before(deadline) {
+					break
+				}
+				return nil, err
+			}
+			if match(buf[:n]) {
+				return buf[:n], nil
+			}
+		}
+	}
+}
+
+// defaultGateway return the ipv4 default gateway in /proc/net/route, only linux is supported
+func defaultGateway() (netip.Addr, error) {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	defer f.Close()
+	return parseRoute(f)
+}
+
+// parseRoute parse the routing table like /proc/net/route, gateway is in little-endian hex
+// Iface	Destination	Gateway 	Flags	...
+// eth0	00000000	0101A8C0	0003	...
+func parseRoute(r io.Reader) (netip.Addr, error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		b, err := hex.DecodeString(fields[2])
+		if err != nil || len(b) != 4 {
+			continue
+		}
+		gw := netip.AddrFrom4([4]byte{b[3], b[2], b[1], b[0]})
+		if !gw.IsUnspecified() {
+			return gw, nil
+		}
+	}
+	return netip.Addr{}, errors.New("no default gateway")
+}
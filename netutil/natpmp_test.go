@@ -0,0 +1,164 @@
+package netutil
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeGateway is an in-process NAT-PMP or PCP server
+type fakeGateway struct {
+	t  *testing.T
+	ip netip.Addr
+	// answer as a PCP server which does not support NAT-PMP
+	pcp bool
+	// drop the first request to test retransmission
+	drop bool
+}
+
+func (g *fakeGateway) serve() string {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		g.t.Fatal(err)
+	}
+	g.t.Cleanup(func() { _ = conn.Close() })
+	var received atomic.Int32
+	go func() {
+		buf := make([]byte, 1100)
+		for {
+			n, remote, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if received.Add(1) == 1 && g.drop {
+				continue
+			}
+			if res := g.response(buf[:n], remote.(*net.UDPAddr).AddrPort()); res != nil {
+				_, _ = conn.WriteTo(res, remote)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func (g *fakeGateway) response(req []byte, client netip.AddrPort) []byte {
+	switch {
+	case len(req) == 2 && req[0] == natpmpVersion && req[1] == natpmpOpPublicAddress:
+		if g.pcp {
+			res := make([]byte, pcpHeaderSize)
+			res[0], res[1], res[3] = pcpVersion, 0x80|natpmpOpPublicAddress, natpmpResultUnsuppVers
+			return res
+		}
+		res := make([]byte, 12)
+		res[1] = 128 + natpmpOpPublicAddress
+		binary.BigEndian.PutUint32(res[4:], 1000)
+		ip := g.ip.As4()
+		copy(res[8:], ip[:])
+		return res
+	case len(req) == pcpHeaderSize+pcpMapSize && req[0] == pcpVersion && req[1] == pcpOpMap:
+		clientIP := netip.AddrFrom16([16]byte(req[8:24])).Unmap()
+		if clientIP != client.Addr() || binary.BigEndian.Uint16(req[pcpHeaderSize+16:]) != client.Port() {
+			g.t.Errorf("client address in MAP request should be %s, got %s:%d", client, clientIP, binary.BigEndian.Uint16(req[pcpHeaderSize+16:]))
+		}
+		if lifetime := binary.BigEndian.Uint32(req[4:]); lifetime != pcpMapLifetime {
+			g.t.Errorf("unexpected lifetime %d", lifetime)
+		}
+		res := make([]byte, pcpHeaderSize+pcpMapSize)
+		res[0], res[1] = pcpVersion, 0x80|pcpOpMap
+		binary.BigEndian.PutUint32(res[4:], pcpMapLifetime)
+		copy(res[pcpHeaderSize:], req[pcpHeaderSize:pcpHeaderSize+20])
+		ip := g.ip.As16()
+		copy(res[pcpHeaderSize+20:], ip[:])
+		return res
+	default:
+		g.t.Errorf("unexpected request %v", req)
+		return nil
+	}
+}
+
+// useGateway set the NAT-PMP gateway and timeout, restore them after test
+func useGateway(t *testing.T, gateway string, timeout time.Duration) {
+	oldGateway, oldTimeout := GetNATPMPGateway(), GetNATPMPTimeout()
+	SetNATPMPGateway(gateway)
+	SetNATPMPTimeout(timeout)
+	t.Cleanup(func() {
+		SetNATPMPGateway(oldGateway)
+		SetNATPMPTimeout(oldTimeout)
+	})
+}
+
+func TestGetIPFromNATPMP(t *testing.T) {
+	cases := []struct {
+		name string
+		g    fakeGateway
+	}{
+		{"nat-pmp", fakeGateway{ip: netip.MustParseAddr("203.0.113.8")}},
+		{"pcp", fakeGateway{ip: netip.MustParseAddr("203.0.113.9"), pcp: true}},
+		{"retransmission", fakeGateway{ip: netip.MustParseAddr("203.0.113.10"), drop: true}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			c.g.t = t
+			useGateway(t, c.g.serve(), 2*time.Second)
+			api, err := ApiMap.GetApi(NATPMP)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ip, err := api.Get(A)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ip != c.g.ip.String() {
+				t.Errorf("expect %s, got %s", c.g.ip, ip)
+			}
+		})
+	}
+}
+
+func TestGetIPFromNATPMPTimeout(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	useGateway(t, conn.LocalAddr().String(), 300*time.Millisecond)
+	start := time.Now()
+	if _, err := getIPFromNATPMP(A); err == nil {
+		t.Error("expect error when gateway does not answer")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("should give up after timeout, took %s", time.Since(start))
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	route := `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	0001A8C0	00000000	0001	0	0	0	00FFFFFF	0	0	0
+eth0	00000000	0101A8C0	0003	0	0	0	00000000	0	0	0
+`
+	gw, err := parseRoute(strings.NewReader(route))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gw.String() != "192.168.1.1" {
+		t.Errorf("expect 192.168.1.1, got %s", gw)
+	}
+	if _, err := parseRoute(strings.NewReader("Iface	Destination	Gateway\n")); err == nil {
+		t.Error("expect error without default route")
+	}
+}
+
+func TestSetNATPMPGateway(t *testing.T) {
+	useGateway(t, "192.168.1.1", 0)
+	if g := GetNATPMPGateway(); g != "192.168.1.1:5351" {
+		t.Errorf("default port should be added, got %s", g)
+	}
+	if GetNATPMPTimeout() != natpmpDefaultTimeout {
+		t.Errorf("default timeout should be used, got %s", GetNATPMPTimeout())
+	}
+}
@@ -0,0 +1,64 @@
+package netutil
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// DefaultPrefixLength is used when a suffix is declared without prefix length
+const DefaultPrefixLength = 64
+
+// ComposeIPv6 compose an ipv6 of the first prefixLength bits of prefix and the remaining bits of suffix
+// e.g. prefix 2001:db8:1:2::1, suffix ::1:2:3:4 and prefixLength 64 compose 2001:db8:1:2:1:2:3:4
+// DefaultPrefixLength is used if prefixLength is 0
+func ComposeIPv6(prefix string, suffix string, prefixLength int) (string, error) {
+	if prefixLength == 0 {
+		prefixLength = DefaultPrefixLength
+	}
+	s, err := parseSuffix(suffix, prefixLength)
+	if err != nil {
+		return "", err
+	}
+	p, err := netip.ParseAddr(strings.TrimSpace(prefix))
+	if err != nil || !p.Is6() || p.Is4In6() {
+		return "", fmt.Errorf("invalid ipv6 prefix %q", prefix)
+	}
+
+	pb, sb := p.As16(), s.As16()
+	var res [16]byte
+	for i := range res {
+		// bits of the i-th byte taken from prefix
+		bits := prefixLength - 8*i
+		switch {
+		case bits >= 8:
+			res[i] = pb[i]
+		case bits <= 0:
+			res[i] = sb[i]
+		default:
+			mask := byte(0xff) << (8 - bits)
+			res[i] = pb[i]&mask | sb[i]&^mask
+		}
+	}
+	return netip.AddrFrom16(res).String(), nil
+}
+
+// ValidateSuffix check the ipv6 suffix and prefix length, DefaultPrefixLength is used if prefixLength is 0
+func ValidateSuffix(suffix string, prefixLength int) error {
+	if prefixLength == 0 {
+		prefixLength = DefaultPrefixLength
+	}
+	_, err := parseSuffix(suffix, prefixLength)
+	return err
+}
+
+func parseSuffix(suffix string, prefixLength int) (netip.Addr, error) {
+	if prefixLength < 0 || prefixLength > 128 {
+		return netip.Addr{}, fmt.Errorf("invalid prefix length %d", prefixLength)
+	}
+	s, err := netip.ParseAddr(strings.TrimSpace(suffix))
+	if err != nil || !s.Is6() || s.Is4In6() {
+		return netip.Addr{}, fmt.Errorf("invalid ipv6 suffix %q", suffix)
+	}
+	return s, nil
+}
@@ -0,0 +1,41 @@
+package netutil
+
+import (
+	"testing"
+)
+
+func TestComposeIPv6(t *testing.T) {
+	cases := []struct {
+		prefix, suffix string
+		length         int
+		expect         string
+	}{
+		{"2001:db8:1:2::1", "::1:2:3:4", 64, "2001:db8:1:2:1:2:3:4"},
+		{"2001:db8:1:2::1", "::1:2:3:4", 0, "2001:db8:1:2:1:2:3:4"},
+		// a /56 prefix, subnet id of the LAN is in the suffix
+		{"2001:db8:1:ff::1", "::10:1:2:3:4", 56, "2001:db8:1:10:1:2:3:4"},
+		// prefix length not on a byte boundary
+		{"2001:db8:1:ff::1", "::f:1:2:3:4", 60, "2001:db8:1:ff:1:2:3:4"},
+		{"2001:db8:1:f0::1", "::5:1:2:3:4", 60, "2001:db8:1:f5:1:2:3:4"},
+		{"2001:db8::1", "::2", 128, "2001:db8::1"},
+	}
+	for _, c := range cases {
+		res, err := ComposeIPv6(c.prefix, c.suffix, c.length)
+		if err != nil {
+			t.Errorf("%s %s/%d: %s", c.prefix, c.suffix, c.length, err)
+			continue
+		}
+		if res != c.expect {
+			t.Errorf("%s %s/%d: expect %s, got %s", c.prefix, c.suffix, c.length, c.expect, res)
+		}
+	}
+
+	for _, c := range [][2]string{{"1.2.3.4", "::1"}, {"2001:db8::1", "1.2.3.4"}, {"2001:db8::1", ""}} {
+		if _, err := ComposeIPv6(c[0], c[1], 64); err == nil {
+			t.Errorf("expect error composing %s and %s", c[0], c[1])
+		}
+	}
+	if err := ValidateSuffix("::1", 129); err == nil {
+		t.Error("expect error with prefix length 129")
+	}
+}
@@ -0,0 +1,251 @@
+package netutil
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stun is the name of stun api in ApiMap
+const Stun = "stun"
+
+const (
+	stunMagicCookie     uint32 = 0x2112A442
+	stunHeaderSize             = 20
+	stunBindingRequest  uint16 = 0x0001
+	stunBindingResponse uint16 = 0x0101
+	stunBindingError    uint16 = 0x0111
+
+	stunAttrMappedAddress    uint16 = 0x0001
+	stunAttrErrorCode        uint16 = 0x0009
+	stunAttrXorMappedAddress uint16 = 0x0020
+
+	stunFamilyIPv4 = 0x01
+	stunFamilyIPv6 = 0x02
+
+	stunDefaultPort    = "3478"
+	stunDefaultTimeout = 3 * time.Second
+)
+
+// stunConfig contains the servers tried in order and the timeout of each server
+type stunConfig struct {
+	mu      sync.RWMutex
+	servers []string
+	timeout time.Duration
+}
+
+var stunSettings = &stunConfig{
+	servers: []string{
+		"stun.l.google.com:19302",
+		"stun.cloudflare.com:3478",
+	},
+	timeout: stunDefaultTimeout,
+}
+
+var getIPFromStunApi = Api{
+	Get: getIPFromStun,
+}
+
+// SetStunServers set the stun servers, they are tried in order until one answers
+// port 3478 is used if a server has no port
+func SetStunServers(servers ...string) error {
+	res := make([]string, 0, len(servers))
+	for _, s := range servers {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(s); err != nil {
+			s = net.JoinHostPort(strings.Trim(s, "[]"), stunDefaultPort)
+		}
+		res = append(res, s)
+	}
+	if len(res) == 0 {
+		return errors.New("no stun server")
+	}
+	stunSettings.mu.Lock()
+	defer stunSettings.mu.Unlock()
+	stunSettings.servers = res
+	return nil
+}
+
+// GetStunServers return a copy of the stun servers
+func GetStunServers() []string {
+	stunSettings.mu.RLock()
+	defer stunSettings.mu.RUnlock()
+	return append([]string(nil), stunSettings.servers...)
+}
+
+// SetStunTimeout set the timeout of each stun server, default timeout is used if timeout <= 0
+func SetStunTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = stunDefaultTimeout
+	}
+	stunSettings.mu.Lock()
+	defer stunSettings.mu.Unlock()
+	stunSettings.timeout = timeout
+}
+
+// GetStunTimeout return the timeout of each stun server
+func GetStunTimeout() time.Duration {
+	stunSettings.mu.RLock()
+	defer stunSettings.mu.RUnlock()
+	return stunSettings.timeout
+}
+
+// getIPFromStun send Binding request to stun servers in order, over udp4 for A and udp6 for AAAA
+// return the first mapped address, errors of all servers are joined if none answers
+func getIPFromStun(Type uint8) (string, error) {
+	var network string
+	switch Type {
+	case A:
+		network = "udp4"
+	case AAAA:
+		network = "udp6"
+	default:
+		return "", NewUnknownType(Type)
+	}
+
+	servers, timeout := GetStunServers(), GetStunTimeout()
+	var errs error
+	for _, server := range servers {
+		addr, err := stunBinding(network, server, timeout)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("stun server %s: %w", server, err))
+			continue
+		}
+		if WhichType(addr.String()) != Type {
+			errs = errors.Join(errs, fmt.Errorf("stun server %s: mapped address %s is not of type %d", server, addr, Type))
+			continue
+		}
+		return addr.String(), nil
+	}
+	if errs == nil {
+		return "", errors.New("no stun server")
+	}
+	return "", errs
+}
+
+// stunBinding send a Binding request to server and return the mapped address in response
+func stunBinding(network string, server string, timeout time.Duration) (netip.Addr, error) {
+	conn, err := net.DialTimeout(network, server, timeout)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	var tid [12]byte
+	_, _ = rand.Read(tid[:])
+	if _, err := conn.Write(newStunBindingRequest(tid)); err != nil {
+		return netip.Addr{}, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return netip.Addr{}, err
+		}
+		addr, err := parseStunResponse(buf[:n], tid)
+		// drop stray packets of other transactions
+		if errors.Is(err, errStunTransaction) {
+			continue
+		}
+		return addr, err
+	}
+}
+
+// newStunBindingRequest build a Binding request without attributes
+func newStunBindingRequest(tid [12]byte) []byte {
+	msg := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(msg[0:], stunBindingRequest)
+	binary.BigEndian.PutUint16(msg[2:], 0)
+	binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+	copy(msg[8:], tid[:])
+	return msg
+}
+
+var errStunTransaction = errors.New("not a response of the transaction")
+
+// parseStunResponse parse Binding response, XOR-MAPPED-ADDRESS is preferred, MAPPED-ADDRESS of RFC 3489 servers is accepted too
+func parseStunResponse(msg []byte, tid [12]byte) (netip.Addr, error) {
+	if len(msg) < stunHeaderSize || binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie || !bytes.Equal(msg[8:20], tid[:]) {
+		return netip.Addr{}, errStunTransaction
+	}
+	length := int(binary.BigEndian.Uint16(msg[2:]))
+	if stunHeaderSize+length > len(msg) {
+		return netip.Addr{}, errors.New("truncated stun message")
+	}
+	msgType := binary.BigEndian.Uint16(msg[0:])
+
+	var mapped, xorMapped netip.Addr
+	attrs := msg[stunHeaderSize : stunHeaderSize+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+attrLen > len(attrs) {
+			return netip.Addr{}, errors.New("truncated stun attribute")
+		}
+		value := attrs[4 : 4+attrLen]
+		switch attrType {
+		case stunAttrXorMappedAddress:
+			xorMapped = stunAddress(value, true, msg[4:20])
+		case stunAttrMappedAddress:
+			mapped = stunAddress(value, false, nil)
+		case stunAttrErrorCode:
+			if msgType == stunBindingError && len(value) >= 4 {
+				code := int(value[2]&0x07)*100 + int(value[3])
+				return netip.Addr{}, fmt.Errorf("stun error %d: %s", code, value[4:])
+			}
+		}
+		// attributes are padded to 4 bytes
+		attrs = attrs[4+(attrLen+3)&^3:]
+	}
+
+	if msgType != stunBindingResponse {
+		return netip.Addr{}, fmt.Errorf("unexpected stun message type 0x%04x", msgType)
+	}
+	if xorMapped.IsValid() {
+		return xorMapped, nil
+	}
+	if mapped.IsValid() {
+		return mapped, nil
+	}
+	return netip.Addr{}, errors.New("no mapped address in stun response")
+}
+
+// stunAddress parse the value of (XOR-)MAPPED-ADDRESS, key is magic cookie followed by transaction id
+// return invalid Addr if value is malformed
+func stunAddress(value []byte, xor bool, key []byte) netip.Addr {
+	if len(value) < 4 {
+		return netip.Addr{}
+	}
+	var ip []byte
+	switch value[1] {
+	case stunFamilyIPv4:
+		ip = make([]byte, 4)
+	case stunFamilyIPv6:
+		ip = make([]byte, 16)
+	default:
+		return netip.Addr{}
+	}
+	if len(value) < 4+len(ip) {
+		return netip.Addr{}
+	}
+	copy(ip, value[4:])
+	if xor {
+		for i := range ip {
+			ip[i] ^= key[i]
+		}
+	}
+	addr, _ := netip.AddrFromSlice(ip)
+	return addr
+}
@@ -0,0 +1,177 @@
+package netutil
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// stunResponder is a tiny stun server answering Binding requests with the address of the client
+type stunResponder struct {
+	// answer MAPPED-ADDRESS of RFC 3489 instead of XOR-MAPPED-ADDRESS
+	legacy bool
+	// answer Binding error response with the code
+	errorCode int
+	// do not answer at all
+	silent bool
+}
+
+func (s stunResponder) serve(t *testing.T, network string, addr string) string {
+	conn, err := net.ListenPacket(network, addr)
+	if err != nil {
+		t.Skipf("can not listen on %s %s: %s", network, addr, err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, remote, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if s.silent || n < stunHeaderSize || binary.BigEndian.Uint16(buf) != stunBindingRequest {
+				continue
+			}
+			_, _ = conn.WriteTo(s.response(buf[:stunHeaderSize], remote.(*net.UDPAddr).AddrPort()), remote)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func (s stunResponder) response(request []byte, client netip.AddrPort) []byte {
+	res := make([]byte, stunHeaderSize)
+	copy(res, request)
+	binary.BigEndian.PutUint16(res, stunBindingResponse)
+
+	// SOFTWARE attribute with padding, should be skipped
+	res = appendStunAttr(res, 0x8022, []byte("GodDns"))
+	if s.errorCode != 0 {
+		binary.BigEndian.PutUint16(res, stunBindingError)
+		res = appendStunAttr(res, stunAttrErrorCode, append([]byte{0, 0, byte(s.errorCode / 100), byte(s.errorCode % 100)}, "Bad Request"...))
+	} else {
+		ip := client.Addr().Unmap()
+		family := byte(stunFamilyIPv4)
+		if ip.Is6() {
+			family = stunFamilyIPv6
+		}
+		value := []byte{0, family, 0, 0}
+		binary.BigEndian.PutUint16(value[2:], client.Port())
+		value = append(value, ip.AsSlice()...)
+		if s.legacy {
+			res = appendStunAttr(res, stunAttrMappedAddress, value)
+		} else {
+			key := res[4:20]
+			for i := 2; i < 4; i++ {
+				value[i] ^= key[i-2]
+			}
+			for i := 4; i < len(value); i++ {
+				value[i] ^= key[i-4]
+			}
+			res = appendStunAttr(res, stunAttrXorMappedAddress, value)
+		}
+	}
+	binary.BigEndian.PutUint16(res[2:], uint16(len(res)-stunHeaderSize))
+	return res
+}
+
+func appendStunAttr(msg []byte, attrType uint16, value []byte) []byte {
+	msg = binary.BigEndian.AppendUint16(msg, attrType)
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(value)))
+	msg = append(msg, value...)
+	for len(msg)%4 != 0 {
+		msg = append(msg, 0)
+	}
+	return msg
+}
+
+// useStun set stun servers and timeout, restore them after test
+func useStun(t *testing.T, timeout time.Duration, servers ...string) {
+	oldServers, oldTimeout := GetStunServers(), GetStunTimeout()
+	if err := SetStunServers(servers...); err != nil {
+		t.Fatal(err)
+	}
+	SetStunTimeout(timeout)
+	t.Cleanup(func() {
+		_ = SetStunServers(oldServers...)
+		SetStunTimeout(oldTimeout)
+	})
+}
+
+func TestGetIPFromStun(t *testing.T) {
+	cases := []struct {
+		network string
+		addr    string
+		Type    Type
+		expect  string
+	}{
+		{"udp4", "127.0.0.1:0", A, "127.0.0.1"},
+		{"udp6", "[::1]:0", AAAA, "::1"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.network, func(t *testing.T) {
+			for _, legacy := range [2]bool{false, true} {
+				useStun(t, time.Second, stunResponder{legacy: legacy}.serve(t, c.network, c.addr))
+				api, err := ApiMap.GetApi(Stun)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ip, err := api.Get(c.Type)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ip != c.expect {
+					t.Errorf("legacy=%v: expect %s, got %s", legacy, c.expect, ip)
+				}
+			}
+		})
+	}
+}
+
+func TestGetIPFromStunFallback(t *testing.T) {
+	silent := stunResponder{silent: true}.serve(t, "udp4", "127.0.0.1:0")
+	bad := stunResponder{errorCode: 400}.serve(t, "udp4", "127.0.0.1:0")
+	good := stunResponder{}.serve(t, "udp4", "127.0.0.1:0")
+
+	useStun(t, 100*time.Millisecond, silent, bad, good)
+	start := time.Now()
+	ip, err := getIPFromStun(A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip != "127.0.0.1" {
+		t.Errorf("expect 127.0.0.1, got %s", ip)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("silent server should time out after 100ms, took %s", time.Since(start))
+	}
+
+	useStun(t, 100*time.Millisecond, silent, bad)
+	_, err = getIPFromStun(A)
+	if err == nil {
+		t.Fatal("expect error when no server answers")
+	}
+	t.Log(err)
+}
+
+func TestSetStunServers(t *testing.T) {
+	useStun(t, 0, "stun.example.com", "2001:db8::1", "[2001:db8::2]:19302")
+	servers := GetStunServers()
+	expected := []string{"stun.example.com:3478", "[2001:db8::1]:3478", "[2001:db8::2]:19302"}
+	if len(servers) != len(expected) {
+		t.Fatalf("expect %v, got %v", expected, servers)
+	}
+	for i := range expected {
+		if servers[i] != expected[i] {
+			t.Errorf("expect %s, got %s", expected[i], servers[i])
+		}
+	}
+	if GetStunTimeout() != stunDefaultTimeout {
+		t.Errorf("default timeout should be used, got %s", GetStunTimeout())
+	}
+	if err := SetStunServers(" "); err == nil {
+		t.Error("expect error setting no stun server")
+	}
+}
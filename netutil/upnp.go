@@ -0,0 +1,305 @@
+package netutil
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// UPnP is the name of UPnP IGD api in ApiMap
+const UPnP = "upnp"
+
+const (
+	ssdpDefaultAddress = "239.255.255.250:1900"
+	upnpDefaultTimeout = 3 * time.Second
+	// ssdpGrace is how long to wait for other IGDs after the first one answered
+	ssdpGrace = 300 * time.Millisecond
+)
+
+// searchTargets are the ST of M-SEARCH, both IGD v1 and v2 answer WANIPConnection:1
+var searchTargets = [...]string{
+	"urn:schemas-upnp-org:device:InternetGatewayDevice:1",
+	"urn:schemas-upnp-org:device:InternetGatewayDevice:2",
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+}
+
+// wanServiceTypes are the services providing GetExternalIPAddress
+var wanServiceTypes = [...]string{
+	"urn:schemas-upnp-org:service:WANIPConnection:2",
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+	"urn:schemas-upnp-org:service:WANPPPConnection:1",
+}
+
+// upnpConfig contains the address M-SEARCH is sent to and the timeout of discovery and each http request
+type upnpConfig struct {
+	mu      sync.RWMutex
+	ssdp    string
+	timeout time.Duration
+}
+
+var upnpSettings = &upnpConfig{
+	ssdp:    ssdpDefaultAddress,
+	timeout: upnpDefaultTimeout,
+}
+
+var getIPFromUPnPApi = Api{
+	Get: getIPFromUPnP,
+}
+
+// SetSSDPAddress set the address M-SEARCH is sent to, port 1900 is used if addr has no port
+// an in-process IGD can stand in for the router in this way
+func SetSSDPAddress(addr string) error {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return errors.New("empty ssdp address")
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(strings.Trim(addr, "[]"), "1900")
+	}
+	upnpSettings.mu.Lock()
+	defer upnpSettings.mu.Unlock()
+	upnpSettings.ssdp = addr
+	return nil
+}
+
+// GetSSDPAddress return the address M-SEARCH is sent to
+func GetSSDPAddress() string {
+	upnpSettings.mu.RLock()
+	defer upnpSettings.mu.RUnlock()
+	return upnpSettings.ssdp
+}
+
+// SetUPnPTimeout set the timeout of discovery and each http request, default timeout is used if timeout <= 0
+func SetUPnPTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = upnpDefaultTimeout
+	}
+	upnpSettings.mu.Lock()
+	defer upnpSettings.mu.Unlock()
+	upnpSettings.timeout = timeout
+}
+
+// GetUPnPTimeout return the timeout of discovery and each http request
+func GetUPnPTimeout() time.Duration {
+	upnpSettings.mu.RLock()
+	defer upnpSettings.mu.RUnlock()
+	return upnpSettings.timeout
+}
+
+// getIPFromUPnP discover the IGD by SSDP and ask its WAN ip by GetExternalIPAddress
+// only ipv4 is supported, the WAN connection services of IGD know nothing about ipv6
+func getIPFromUPnP(Type uint8) (string, error) {
+	switch Type {
+	case A:
+	case AAAA:
+		return "", errors.New("upnp igd supports ipv4 only")
+	default:
+		return "", NewUnknownType(Type)
+	}
+
+	ssdp, timeout := GetSSDPAddress(), GetUPnPTimeout()
+	locations, err := ssdpSearch(ssdp, timeout)
+	if err != nil {
+		return "", err
+	}
+
+	var errs error
+	client := resty.New().SetTimeout(timeout)
+	for _, location := range locations {
+		serviceType, controlURL, err := findWanService(client, location)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("igd %s: %w", location, err))
+			continue
+		}
+		ip, err := getExternalIPAddress(client, controlURL, serviceType)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("igd %s: %w", location, err))
+			continue
+		}
+		return ip, nil
+	}
+	return "", errs
+}
+
+// ssdpSearch send M-SEARCH to addr and return LOCATIONs of responses received in timeout without duplicates
+func ssdpSearch(addr string, timeout time.Duration) ([]string, error) {
+	raddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	for _, st := range searchTargets {
+		msg := "M-SEARCH * HTTP/1.1\r\n" +
+			"HOST: " + ssdpDefaultAddress + "\r\n" +
+			"MAN: \"ssdp:discover\"\r\n" +
+			"MX: 2\r\n" +
+			"ST: " + st + "\r\n\r\n"
+		if _, err := conn.WriteTo([]byte(msg), raddr); err != nil {
+			return nil, fmt.Errorf("error sending M-SEARCH to %s: %w", addr, err)
+		}
+	}
+
+	// collect responses until timeout, or a short while after the first one as an IGD may answer every search target
+	deadline := time.Now().Add(timeout)
+	_ = conn.SetReadDeadline(deadline)
+	var locations []string
+	seen := make(map[string]bool)
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		location := ssdpLocation(string(buf[:n]))
+		if location != "" && !seen[location] {
+			seen[location] = true
+			locations = append(locations, location)
+			if len(locations) == 1 {
+				if grace := time.Now().Add(ssdpGrace); grace.Before(deadline) {
+					_ = conn.SetReadDeadline(grace)
+				}
+			}
+		}
+	}
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("no igd answered M-SEARCH to %s in %s", addr, timeout)
+	}
+	return locations, nil
+}
+
+// ssdpLocation return LOCATION header of a M-SEARCH response, "" if it is not a successful response
+func ssdpLocation(res string) string {
+	lines := strings.Split(res, "\r\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "HTTP/1.1 200") {
+		return ""
+	}
+	for _, line := range lines[1:] {
+		k, v, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(k), "LOCATION") {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// upnpDevice is the device in device description, services of embedded devices are nested
+type upnpDevice struct {
+	Services []struct {
+		ServiceType string `xml:"serviceType"`
+		ControlURL  string `xml:"controlURL"`
+	} `xml:"serviceList>service"`
+	Devices []upnpDevice `xml:"deviceList>device"`
+}
+
+type upnpDescription struct {
+	URLBase string     `xml:"URLBase"`
+	Device  upnpDevice `xml:"device"`
+}
+
+// findService return control url of service of serviceType in device and its embedded devices
+func (d upnpDevice) findService(serviceType string) (string, bool) {
+	for _, s := range d.Services {
+		if s.ServiceType == serviceType {
+			return s.ControlURL, true
+		}
+	}
+	for _, device := range d.Devices {
+		if u, ok := device.findService(serviceType); ok {
+			return u, ok
+		}
+	}
+	return "", false
+}
+
+// findWanService get device description at location, return the type and absolute control url of WAN connection service
+func findWanService(client *resty.Client, location string) (serviceType string, controlURL string, err error) {
+	res, err := client.R().Get(location)
+	if err != nil {
+		return "", "", err
+	}
+	if res.StatusCode() != http.StatusOK {
+		return "", "", fmt.Errorf("error getting device description: %s", res.Status())
+	}
+	var desc upnpDescription
+	if err := xml.Unmarshal(res.Body(), &desc); err != nil {
+		return "", "", fmt.Errorf("error parsing device description: %w", err)
+	}
+
+	base, err := url.Parse(location)
+	if err != nil {
+		return "", "", err
+	}
+	if desc.URLBase != "" {
+		if base, err = url.Parse(desc.URLBase); err != nil {
+			return "", "", err
+		}
+	}
+	for _, t := range wanServiceTypes {
+		if u, ok := desc.Device.findService(t); ok {
+			ref, err := url.Parse(u)
+			if err != nil {
+				return "", "", err
+			}
+			return t, base.ResolveReference(ref).String(), nil
+		}
+	}
+	return "", "", errors.New("no WAN connection service in device description")
+}
+
+type soapExternalIPResponse struct {
+	Body struct {
+		Response struct {
+			NewExternalIPAddress string `xml:"NewExternalIPAddress"`
+		} `xml:"GetExternalIPAddressResponse"`
+		Fault *struct {
+			FaultString string `xml:"faultstring"`
+			ErrorCode   int    `xml:"detail>UPnPError>errorCode"`
+			Description string `xml:"detail>UPnPError>errorDescription"`
+		} `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+// getExternalIPAddress call GetExternalIPAddress action of service at controlURL
+func getExternalIPAddress(client *resty.Client, controlURL string, serviceType string) (string, error) {
+	body := `<?xml version="1.0"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">` +
+		`<s:Body><u:GetExternalIPAddress xmlns:u="` + serviceType + `"></u:GetExternalIPAddress></s:Body>` +
+		`</s:Envelope>`
+	res, err := client.R().
+		SetHeader("Content-Type", `text/xml; charset="utf-8"`).
+		SetHeader("SOAPAction", `"`+serviceType+`#GetExternalIPAddress"`).
+		SetBody(body).
+		Post(controlURL)
+	if err != nil {
+		return "", err
+	}
+
+	var envelope soapExternalIPResponse
+	if err := xml.Unmarshal(res.Body(), &envelope); err != nil {
+		return "", fmt.Errorf("error parsing response of GetExternalIPAddress: %w", err)
+	}
+	if fault := envelope.Body.Fault; fault != nil {
+		return "", fmt.Errorf("GetExternalIPAddress failed: %s %d %s", fault.FaultString, fault.ErrorCode, fault.Description)
+	}
+	if res.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("GetExternalIPAddress failed: %s", res.Status())
+	}
+	ip := strings.TrimSpace(envelope.Body.Response.NewExternalIPAddress)
+	if WhichType(ip) != A {
+		return "", fmt.Errorf("invalid external ip %q, the WAN connection may be down", ip)
+	}
+	return ip, nil
+}
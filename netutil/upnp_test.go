@@ -0,0 +1,171 @@
+package netutil
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+    <serviceList>
+      <service>
+        <serviceType>urn:schemas-upnp-org:service:Layer3Forwarding:1</serviceType>
+        <controlURL>/ctl/L3F</controlURL>
+      </service>
+    </serviceList>
+    <deviceList>
+      <device>
+        <deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+        <deviceList>
+          <device>
+            <deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+            <serviceList>
+              <service>
+                <serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+                <controlURL>/ctl/IPConn</controlURL>
+              </service>
+            </serviceList>
+          </device>
+        </deviceList>
+      </device>
+    </deviceList>
+  </device>
+</root>`
+
+// fakeIGD is an in-process internet gateway device answering M-SEARCH and GetExternalIPAddress
+type fakeIGD struct {
+	t *testing.T
+	// external ip answered, a fault is answered if it is ""
+	ip string
+}
+
+// serve start the ssdp responder and the http server, return the ssdp address
+func (f fakeIGD) serve() string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rootDesc.xml":
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = io.WriteString(w, fakeDescription)
+		case "/ctl/IPConn":
+			action := r.Header.Get("SOAPAction")
+			if action != `"urn:schemas-upnp-org:service:WANIPConnection:1#GetExternalIPAddress"` {
+				f.t.Errorf("unexpected SOAPAction %s", action)
+			}
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), "GetExternalIPAddress") {
+				f.t.Errorf("unexpected body %s", body)
+			}
+			w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+			if f.ip == "" {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = io.WriteString(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`+
+					`<s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail>`+
+					`<UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>501</errorCode><errorDescription>Action Failed</errorDescription></UPnPError>`+
+					`</detail></s:Fault></s:Body></s:Envelope>`)
+				return
+			}
+			_, _ = io.WriteString(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`+
+				`<u:GetExternalIPAddressResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1">`+
+				`<NewExternalIPAddress>`+f.ip+`</NewExternalIPAddress></u:GetExternalIPAddressResponse></s:Body></s:Envelope>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	f.t.Cleanup(server.Close)
+
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, remote, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			msg := string(buf[:n])
+			if !strings.HasPrefix(msg, "M-SEARCH * HTTP/1.1") || !strings.Contains(msg, `MAN: "ssdp:discover"`) {
+				continue
+			}
+			res := fmt.Sprintf("HTTP/1.1 200 OK\r\nCACHE-CONTROL: max-age=120\r\nST: urn:schemas-upnp-org:device:InternetGatewayDevice:1\r\n"+
+				"USN: uuid:fake::urn:schemas-upnp-org:device:InternetGatewayDevice:1\r\nEXT:\r\nLocation: %s/rootDesc.xml\r\n\r\n", server.URL)
+			_, _ = conn.WriteTo([]byte(res), remote)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+// useSSDP set the ssdp address and timeout, restore them after test
+func useSSDP(t *testing.T, addr string, timeout time.Duration) {
+	oldAddr, oldTimeout := GetSSDPAddress(), GetUPnPTimeout()
+	if err := SetSSDPAddress(addr); err != nil {
+		t.Fatal(err)
+	}
+	SetUPnPTimeout(timeout)
+	t.Cleanup(func() {
+		_ = SetSSDPAddress(oldAddr)
+		SetUPnPTimeout(oldTimeout)
+	})
+}
+
+func TestGetIPFromUPnP(t *testing.T) {
+	useSSDP(t, fakeIGD{t: t, ip: "203.0.113.7"}.serve(), 2*time.Second)
+	api, err := ApiMap.GetApi(UPnP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	ip, err := api.Get(A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip != "203.0.113.7" {
+		t.Errorf("expect 203.0.113.7, got %s", ip)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("discovery should not wait until timeout after igd answered, took %s", time.Since(start))
+	}
+
+	if _, err := api.Get(AAAA); err == nil {
+		t.Error("expect error getting ipv6 from igd")
+	}
+}
+
+func TestGetIPFromUPnPFault(t *testing.T) {
+	useSSDP(t, fakeIGD{t: t}.serve(), time.Second)
+	_, err := getIPFromUPnP(A)
+	if err == nil || !strings.Contains(err.Error(), "501") {
+		t.Errorf("expect error of the fault, got %v", err)
+	}
+}
+
+func TestGetIPFromUPnPNoIGD(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	useSSDP(t, conn.LocalAddr().String(), 200*time.Millisecond)
+	if _, err := getIPFromUPnP(A); err == nil {
+		t.Error("expect error when no igd answers")
+	}
+}
+
+func TestSsdpLocation(t *testing.T) {
+	if l := ssdpLocation("HTTP/1.1 200 OK\r\nlocation:  http://192.168.1.1:5000/rootDesc.xml \r\n\r\n"); l != "http://192.168.1.1:5000/rootDesc.xml" {
+		t.Errorf("unexpected location %q", l)
+	}
+	if l := ssdpLocation("NOTIFY * HTTP/1.1\r\nLOCATION: http://192.168.1.1:5000/rootDesc.xml\r\n\r\n"); l != "" {
+		t.Errorf("location of NOTIFY should be ignored, got %q", l)
+	}
+}
@@ -0,0 +1,128 @@
+package netutil
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrWatchUnsupported is returned by WatchAddr on platforms without netlink, check ip change by polling instead
+var ErrWatchUnsupported = errors.New("watching address change is not supported on this platform")
+
+// AddrWatcher report devices whose addresses were added or removed
+type AddrWatcher struct {
+	// C receives the name of device once its addresses stop changing for the debounce window
+	C <-chan string
+
+	done      chan struct{}
+	stop      func()
+	debouncer *debouncer
+	once      sync.Once
+}
+
+// WatchAddr start watching address change of all devices
+// changes of a device are coalesced until it is quiet for debounce, so a flapping link is reported once
+func WatchAddr(debounce time.Duration) (*AddrWatcher, error) {
+	events, stop, err := subscribeAddr()
+	if err != nil {
+		return nil, err
+	}
+
+	c := make(chan string, 16)
+	w := &AddrWatcher{C: c, done: make(chan struct{}), stop: stop}
+	w.debouncer = newDebouncer(debounce, func(device string) {
+		select {
+		case c <- device:
+		case <-w.done:
+		}
+	})
+	go func() {
+		for device := range events {
+			w.debouncer.trigger(device)
+		}
+	}()
+	return w, nil
+}
+
+// Close stop watching, changes pending in debounce window are dropped
+func (w *AddrWatcher) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+		w.stop()
+		w.debouncer.close()
+	})
+	return nil
+}
+
+// debouncer call fire with a key once trigger of the key is not called for window
+type debouncer struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string]*pendingWindow
+	fire    func(key string)
+}
+
+// pendingWindow is the window of a key which is not over
+type pendingWindow struct {
+	timer *time.Timer
+	// deadline is moved forward by trigger, the timer is never reset
+	// because resetting a timer which has fired but whose callback is waiting for the lock arms it again
+	deadline time.Time
+}
+
+func newDebouncer(window time.Duration, fire func(key string)) *debouncer {
+	return &debouncer{
+		window:  window,
+		pending: make(map[string]*pendingWindow),
+		fire:    fire,
+	}
+}
+
+// trigger start the window of key, or extend it if it is not over
+func (d *debouncer) trigger(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.triggerLocked(key)
+}
+
+// triggerLocked is trigger with d.mu held
+func (d *debouncer) triggerLocked(key string) {
+	if d.pending == nil {
+		return
+	}
+	if w, ok := d.pending[key]; ok {
+		w.deadline = time.Now().Add(d.window)
+		return
+	}
+	w := &pendingWindow{deadline: time.Now().Add(d.window)}
+	w.timer = time.AfterFunc(d.window, func() { d.expire(key, w) })
+	d.pending[key] = w
+}
+
+// expire fire key if the deadline of w is over, or wait for the rest of window
+func (d *debouncer) expire(key string, w *pendingWindow) {
+	d.mu.Lock()
+	if d.pending[key] != w {
+		// closed
+		d.mu.Unlock()
+		return
+	}
+	if rest := time.Until(w.deadline); rest > 0 {
+		w.timer = time.AfterFunc(rest, func() { d.expire(key, w) })
+		d.mu.Unlock()
+		return
+	}
+	delete(d.pending, key)
+	d.mu.Unlock()
+	d.fire(key)
+}
+
+// close stop all pending windows, trigger does nothing after close
+func (d *debouncer) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, w := range d.pending {
+		w.timer.Stop()
+	}
+	d.pending = nil
+}
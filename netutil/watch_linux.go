@@ -0,0 +1,106 @@
+//go:build linux
+
+package netutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	// watchPollInterval is how often the netlink reader checks whether it is stopped
+	watchPollInterval = 500 * time.Millisecond
+	// rtmgrpIPv4Ifaddr and rtmgrpIPv6Ifaddr are RTMGRP_IPV4_IFADDR and RTMGRP_IPV6_IFADDR
+	rtmgrpIPv4Ifaddr = 0x10
+	rtmgrpIPv6Ifaddr = 0x100
+)
+
+// subscribeAddr subscribe RTM_NEWADDR and RTM_DELADDR of ipv4 and ipv6, names of changed devices are sent to events
+// events is closed after stop is called
+func subscribeAddr() (events <-chan string, stop func(), err error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening netlink socket: %w", err)
+	}
+	sa := &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: rtmgrpIPv4Ifaddr | rtmgrpIPv6Ifaddr,
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		_ = syscall.Close(fd)
+		return nil, nil, fmt.Errorf("error subscribing address change: %w", err)
+	}
+	// wake up periodically to check whether it is stopped, closing the socket does not interrupt recvfrom
+	tv := syscall.NsecToTimeval(watchPollInterval.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		_ = syscall.Close(fd)
+		return nil, nil, err
+	}
+
+	c := make(chan string, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(c)
+		defer syscall.Close(fd)
+		buf := make([]byte, 1<<16)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			n, _, err := syscall.Recvfrom(fd, buf, 0)
+			if err != nil {
+				// ENOBUFS means some events are lost, the next poll catches them up
+				if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) || errors.Is(err, syscall.ENOBUFS) {
+					continue
+				}
+				return
+			}
+			msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+			if err != nil {
+				continue
+			}
+			for _, index := range changedIndexes(msgs) {
+				iface, err := net.InterfaceByIndex(index)
+				if err != nil {
+					// the device is removed
+					continue
+				}
+				select {
+				case c <- iface.Name:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return c, func() { once.Do(func() { close(done) }) }, nil
+}
+
+// changedIndexes return the interface indexes of RTM_NEWADDR and RTM_DELADDR messages without duplicates
+func changedIndexes(msgs []syscall.NetlinkMessage) []int {
+	var indexes []int
+	seen := make(map[int]bool)
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWADDR && m.Header.Type != syscall.RTM_DELADDR {
+			continue
+		}
+		if len(m.Data) < syscall.SizeofIfAddrmsg {
+			continue
+		}
+		ifam := (*syscall.IfAddrmsg)(unsafe.Pointer(&m.Data[0]))
+		if index := int(ifam.Index); !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,42 @@
+//go:build linux
+
+package netutil
+
+import (
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestChangedIndexes(t *testing.T) {
+	var rib []byte
+	rib = append(rib, newAddrMessage(2, "2001:db8::1", 0)...)
+	del := newAddrMessage(3, "2001:db8::2", 0)
+	(*syscall.NlMsghdr)(unsafe.Pointer(&del[0])).Type = syscall.RTM_DELADDR
+	rib = append(rib, del...)
+	rib = append(rib, newAddrMessage(2, "2001:db8::3", 0)...)
+	link := newAddrMessage(4, "2001:db8::4", 0)
+	(*syscall.NlMsghdr)(unsafe.Pointer(&link[0])).Type = syscall.RTM_NEWLINK
+	rib = append(rib, link...)
+
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexes := changedIndexes(msgs)
+	if len(indexes) != 2 || indexes[0] != 2 || indexes[1] != 3 {
+		t.Errorf("expect [2 3], got %v", indexes)
+	}
+}
+
+func TestWatchAddrClose(t *testing.T) {
+	w, err := WatchAddr(100 * time.Millisecond)
+	if err != nil {
+		t.Skip(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package netutil
+
+// subscribeAddr is not supported without netlink
+func subscribeAddr() (events <-chan string, stop func(), err error) {
+	return nil, nil, ErrWatchUnsupported
+}
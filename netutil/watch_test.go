@@ -0,0 +1,95 @@
+package netutil
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncer(t *testing.T) {
+	var mu sync.Mutex
+	fired := make(map[string]int)
+	d := newDebouncer(100*time.Millisecond, func(key string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fired[key]++
+	})
+
+	// a flapping link keeps restarting the window and is fired once
+	for i := 0; i < 5; i++ {
+		d.trigger("eth0")
+		time.Sleep(30 * time.Millisecond)
+	}
+	d.trigger("wlan0")
+	time.Sleep(250 * time.Millisecond)
+
+	mu.Lock()
+	if fired["eth0"] != 1 || fired["wlan0"] != 1 {
+		t.Errorf("expect each device fired once, got %v", fired)
+	}
+	mu.Unlock()
+
+	// fired again after the window is over
+	d.trigger("eth0")
+	time.Sleep(200 * time.Millisecond)
+	d.trigger("eth0")
+	d.close()
+	d.trigger("eth0")
+	time.Sleep(200 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fired["eth0"] != 2 {
+		t.Errorf("expect eth0 fired twice, pending window dropped after close, got %d", fired["eth0"])
+	}
+}
+
+func TestDebouncerBurst(t *testing.T) {
+	var fired int32
+	// the window is much longer than gaps between events so that a slow scheduler does not split the burst
+	d := newDebouncer(60*time.Millisecond, func(key string) {
+		atomic.AddInt32(&fired, 1)
+	})
+	defer d.close()
+
+	// events of one burst from several goroutines
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				d.trigger("eth0")
+				time.Sleep(time.Duration(i%3) * 5 * time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+	time.Sleep(300 * time.Millisecond)
+
+	if n := atomic.LoadInt32(&fired); n != 1 {
+		t.Errorf("expect one delivery of a burst, got %d", n)
+	}
+}
+
+func TestDebouncerTriggerWhileFiring(t *testing.T) {
+	var fired int32
+	window := 20 * time.Millisecond
+	d := newDebouncer(window, func(key string) {
+		atomic.AddInt32(&fired, 1)
+	})
+	defer d.close()
+
+	d.trigger("eth0")
+	// the window is over and the callback is waiting for the lock when the next event of the burst arrives
+	d.mu.Lock()
+	time.Sleep(2 * window)
+	d.triggerLocked("eth0")
+	d.mu.Unlock()
+	time.Sleep(5 * window)
+
+	if n := atomic.LoadInt32(&fired); n != 1 {
+		t.Errorf("expect one delivery of a burst, got %d", n)
+	}
+}
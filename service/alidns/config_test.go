@@ -0,0 +1,118 @@
+package alidns
+
+import (
+	"strings"
+	"testing"
+
+	"GodDns/core"
+	"gopkg.in/ini.v1"
+)
+
+func TestConfig_GenerateDefaultConfigInfo(t *testing.T) {
+	config, err := Config{}.GenerateDefaultConfigInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(config.Content)
+}
+
+func TestConfig_ReadConfig(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[AliDNS]
+AccessKeyId=ID
+AccessKeySecret=SECRET
+Domain=example.com
+SubDomain=www,@,www
+RecordId=record1
+Value=1.2.3.4
+TTL=600
+Type=4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("AliDNS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 {
+		t.Fatalf("expect 2 parameters, got %d", len(ps))
+	}
+	for _, p := range ps {
+		p := p.(*Parameters)
+		if p.Type != "A" || p.getLine() != "default" || p.getEndpoint() != DefaultEndpoint {
+			t.Errorf("unexpected parameters %+v", p)
+		}
+		if p.RecordId != "" {
+			t.Error("record id should not be shared by multiple records")
+		}
+		t.Log(p.Target())
+	}
+}
+
+func TestConfig_ReadConfigMissingKey(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[AliDNS]
+AccessKeyId=ID
+Domain=example.com
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (Config{}).ReadConfig(*cfg.Section("AliDNS")); err == nil {
+		t.Error("should return error when AccessKeySecret is missing")
+	}
+}
+
+func TestConfig_SaveMerged(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[AliDNS]
+AccessKeyId=ID
+AccessKeySecret=SECRET
+Domain=example.com
+SubDomain=www,mail
+Value=1.2.3.4
+TTL=600
+Type=A
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := Config{}.ReadConfig(*cfg.Section("AliDNS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// record ids looked up are not saved for multiple records
+	ps[0].(*Parameters).RecordId = "record1"
+
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("expect 1 merged parameters, got %d", len(merged))
+	}
+	saved, err := merged[0].SaveConfig(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(saved.Content, "SubDomain=www,mail\n") || strings.Contains(saved.Content, "record1") {
+		t.Errorf("unexpected config:\n%s", saved.Content)
+	}
+
+	cfg, err = ini.Load([]byte(saved.Content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Config{}.ReadConfig(*cfg.Section("AliDNS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 2 || again[0].(*Parameters).SubDomain != "www" || again[1].(*Parameters).SubDomain != "mail" {
+		t.Errorf("expect www and mail read again, got %d parameters", len(again))
+	}
+
+	// a single record keeps its record id
+	single := core.MergeParameters(ps[0])
+	if single[0].(*Parameters).RecordId != "record1" {
+		t.Errorf("expect record id of a single record kept, got %+v", single[0])
+	}
+}
@@ -0,0 +1,151 @@
+// Package alidns use Alibaba Cloud DNS API to update DNS record
+package alidns
+
+import (
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util/collections"
+)
+
+const serviceName = "AliDNS"
+
+// DefaultEndpoint is the endpoint of Alibaba Cloud DNS API
+const DefaultEndpoint = "https://alidns.aliyuncs.com"
+
+// Parameters implements DeviceOverridable and Mergeable
+// AccessKeyId and AccessKeySecret are used to sign the request
+// RecordId will be looked up by SubDomain and Type by DescribeSubDomainRecords
+// Device is Device name when overriding ip with specific Device/interface
+type Parameters struct {
+	AccessKeyId     string `KeyValue:"AccessKeyId,get from https://ram.console.aliyun.com/manage/ak"`
+	AccessKeySecret string `KeyValue:"AccessKeySecret,secret of the AccessKey"`
+	Domain          string `KeyValue:"Domain,domain name like example.com"`
+	SubDomain       string `KeyValue:"SubDomain,record name(RR) like www, use @ for the root domain, if you have multiple records to update, set like SubDomain=www,ftp,mail"`
+	RecordId        string `KeyValue:"RecordId,record id, looked up by SubDomain and Type"`
+	Line            string `KeyValue:"Line,resolution line, default(default)"`
+	Value           string `KeyValue:"Value,IP address like 6.6.6.6"`
+	TTL             uint64 `KeyValue:"TTL,Time-To-Live, 600(default)"`
+	Type            string `KeyValue:"Type,A/AAAA/4/6"`
+	Endpoint        string `KeyValue:"Endpoint,API endpoint, https://alidns.aliyuncs.com(default)"`
+	Device          string `KeyValue:"Device,device/net interface name"`
+	core.DeviceOptions
+}
+
+// GenerateDefaultConfigInfo return Default config
+func GenerateDefaultConfigInfo() Parameters {
+	return Parameters{
+		AccessKeyId:     "AccessKeyId",
+		AccessKeySecret: "AccessKeySecret",
+		Domain:          "example.com",
+		SubDomain:       "www,mail,ftp...",
+		Line:            "default",
+		Value:           "1.2.3.4",
+		TTL:             600,
+		Type:            "A/AAAA/4/6",
+		Device:          "your device/net interface name",
+	}
+}
+
+// GetName return "AliDNS"
+func (p *Parameters) GetName() string {
+	return serviceName
+}
+
+// SaveConfig return core.ConfigStr
+func (p *Parameters) SaveConfig(No uint) (core.ConfigStr, error) {
+	return configInstance.GenerateConfigInfo(p, No)
+}
+
+// Target return the full record name
+func (p *Parameters) Target() string {
+	return p.getTotalDomain()
+}
+
+// ToRequest Convert to core.Request
+func (p *Parameters) ToRequest() (core.Request, error) {
+	r := new(Request)
+	r.Init(*p)
+	return r, nil
+}
+
+// SetValue set ip
+func (p *Parameters) SetValue(value string) {
+	p.Value = value
+}
+
+// GetIP return ip value
+func (p *Parameters) GetIP() string {
+	return p.Value
+}
+
+// GetType return Type like "4" or "6" and "" if invalid type
+func (p *Parameters) GetType() string {
+	return netutil.Type2Num(p.Type)
+}
+
+// IsTypeSet return whether the type is set correctly
+func (p *Parameters) IsTypeSet() bool {
+	return p.Type == "A" || p.Type == "AAAA"
+}
+
+// GetDevice return Device name
+func (p *Parameters) GetDevice() string {
+	return p.Device
+}
+
+// IsDeviceSet return whether the Device is set
+func (p *Parameters) IsDeviceSet() bool {
+	return p.Device != ""
+}
+
+// MergeableWith return true if the two Parameters differ only by SubDomain and RecordId
+func (p *Parameters) MergeableWith(other core.Parameters) bool {
+	o, ok := other.(*Parameters)
+	if !ok {
+		return false
+	}
+	return p.AccessKeyId == o.AccessKeyId && p.AccessKeySecret == o.AccessKeySecret && p.Domain == o.Domain &&
+		p.Line == o.Line && p.Value == o.Value && p.TTL == o.TTL && p.Type == o.Type && p.Endpoint == o.Endpoint &&
+		p.Device == o.Device && p.DeviceOptions == o.DeviceOptions
+}
+
+// Merge return a new Parameters whose SubDomain is like "www,ftp"
+// RecordId is kept only if there is one subdomain, as it is only meaningful for exactly one record
+func (p *Parameters) Merge(others ...core.Parameters) core.Parameters {
+	merged := *p
+	subdomains := []string{p.SubDomain}
+	for _, other := range others {
+		subdomains = collections.AppendUnique(subdomains, other.(*Parameters).SubDomain)
+	}
+	merged.SubDomain = strings.Join(subdomains, ",")
+	if len(subdomains) > 1 {
+		merged.RecordId = ""
+	}
+	return &merged
+}
+
+// getTotalDomain return subdomain+domain, "@" stands for the root domain
+func (p *Parameters) getTotalDomain() string {
+	if p.SubDomain == "@" || p.SubDomain == "" {
+		return p.Domain
+	}
+	return p.SubDomain + "." + p.Domain
+}
+
+// getEndpoint return Endpoint or DefaultEndpoint if not set
+func (p *Parameters) getEndpoint() string {
+	if p.Endpoint == "" {
+		return DefaultEndpoint
+	}
+	return p.Endpoint
+}
+
+// getLine return Line or "default" if not set
+func (p *Parameters) getLine() string {
+	if p.Line == "" {
+		return "default"
+	}
+	return p.Line
+}
@@ -0,0 +1,311 @@
+package alidns
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	"GodDns/netutil"
+	json "GodDns/util/json"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	apiVersion       = "2015-01-09"
+	signatureMethod  = "HMAC-SHA1"
+	signatureVersion = "1.0"
+	requestTimeout   = 20 * time.Second
+)
+
+// usage
+// r:=alidns.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request and core.ThroughProxy
+type Request struct {
+	parameters Parameters
+	status     core.Status
+}
+
+// Init set parameter
+func (r *Request) Init(parameters Parameters) {
+	r.parameters = parameters
+}
+
+// Target return target domain
+func (r *Request) Target() string {
+	return r.parameters.getTotalDomain()
+}
+
+// GetName return "AliDNS"
+func (r *Request) GetName() string {
+	return serviceName
+}
+
+// ToParameters return core.Service
+func (r *Request) ToParameters() core.Service {
+	return &r.parameters
+}
+
+// Status return core.Status which contains execution result etc.
+func (r *Request) Status() core.Status {
+	return r.status
+}
+
+func newStatus() *core.Status {
+	return &core.Status{
+		Name:   serviceName,
+		Status: core.NotExecute,
+		MG:     core.NewDefaultMsgGroup(),
+	}
+}
+
+// MakeRequest 1.DescribeSubDomainRecords to get RecordId 2.UpdateDomainRecord
+func (r *Request) MakeRequest() error {
+	client := core.MainClientPool.Get().(*resty.Client)
+	defer core.MainClientPool.Put(client)
+	return r.execute(client)
+}
+
+// RequestThroughProxy make request through the proxies in netutil.GlobalProxies one by one until one succeeds
+func (r *Request) RequestThroughProxy() error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	iter := netutil.GlobalProxies.GetProxyIter()
+	if iter.Len() == 0 {
+		r.status.Status = core.Failed
+		r.status.MG.AddError("no proxy available")
+		return errors.New("no proxy available")
+	}
+
+	var err error
+	for iter.NotLast() {
+		proxy := iter.Next()
+		// never set proxy on a pooled client
+		client := core.MainClientPool.New().(*resty.Client).SetProxy(proxy)
+		err = r.execute(client)
+		if err == nil {
+			return nil
+		}
+		errMsg := fmt.Sprintf("request error through proxy %s: %v", proxy, err)
+		r.status.MG.AddError(errMsg)
+		log.Errorf(errMsg)
+	}
+	return err
+}
+
+func (r *Request) execute(client *resty.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	status, err := r.GetRecordId(ctx, client)
+	if err != nil || status.Status != core.Success {
+		r.status = *status
+		if err == nil {
+			err = errors.New("failed to get record id")
+		}
+		r.status.Status = core.Failed
+		r.status.MG.AddError(fmt.Sprintf("error getting record id of %s: %s", r.Target(), err.Error()))
+		return err
+	}
+
+	res := &updateDomainRecordResponse{}
+	httpStatus, err := r.call(ctx, client, "UpdateDomainRecord", map[string]string{
+		"RecordId": r.parameters.RecordId,
+		"RR":       r.rr(),
+		"Type":     r.parameters.Type,
+		"Value":    r.parameters.Value,
+		"TTL":      strconv.FormatUint(r.parameters.TTL, 10),
+		"Line":     r.parameters.getLine(),
+	}, res)
+	r.status = *code2status(httpStatus, res.Code)
+	if err != nil {
+		r.status.Status = core.Failed
+		r.status.MG.AddError(err.Error())
+		return err
+	}
+
+	if r.status.Status == core.Success {
+		r.status.MG.AddInfo(fmt.Sprintf("record updated %s %s, RequestId: %s", r.Target(), r.parameters.Value, res.RequestId))
+		return nil
+	}
+	r.status.MG.AddError(fmt.Sprintf("%s at %s, RequestId: %s", res.Message, r.Target(), res.RequestId))
+	return fmt.Errorf("status code:%d %s", httpStatus, res.Code)
+}
+
+// GetRecordId make request DescribeSubDomainRecords to get RecordId and set Parameters.RecordId
+func (r *Request) GetRecordId(ctx context.Context, client *resty.Client) (*core.Status, error) {
+	res := &describeSubDomainRecordsResponse{}
+	params := map[string]string{
+		"SubDomain": r.Target(),
+		"Type":      r.parameters.Type,
+	}
+	if r.parameters.Line != "" {
+		params["Line"] = r.parameters.Line
+	}
+	httpStatus, err := r.call(ctx, client, "DescribeSubDomainRecords", params, res)
+	status := code2status(httpStatus, res.Code)
+	if err != nil {
+		return status, err
+	}
+
+	if status.Status != core.Success {
+		status.MG.AddError(fmt.Sprintf("%s at %s, RequestId: %s", res.Message, r.Target(), res.RequestId))
+		return status, fmt.Errorf("status code:%d %s", httpStatus, res.Code)
+	}
+
+	recordId, err := r.selectRecord(res.DomainRecords.Record)
+	if err != nil {
+		status.Status = core.Failed
+		status.MG.AddError(fmt.Sprintf("%s at %s", err.Error(), r.Target()))
+		return status, err
+	}
+
+	r.parameters.RecordId = recordId
+	return status, nil
+}
+
+// selectRecord return the id of the only record on Line
+// error if no record matches, or more than one record matches and it is ambiguous which one to update
+func (r *Request) selectRecord(records []record) (string, error) {
+	var ids []string
+	for _, rec := range records {
+		if rec.Line == r.parameters.getLine() {
+			ids = append(ids, rec.RecordId)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", errors.New("no record found")
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%d records found on line %s: %s, delete the extra records or set another Line",
+			len(ids), r.parameters.getLine(), strings.Join(ids, " "))
+	}
+}
+
+// call make a signed request of action with params, unmarshal the response to result and return the http status code
+func (r *Request) call(ctx context.Context, client *resty.Client, action string, params map[string]string, result any) (int, error) {
+	query := url.Values{}
+	query.Set("Action", action)
+	query.Set("Format", "JSON")
+	query.Set("Version", apiVersion)
+	query.Set("AccessKeyId", r.parameters.AccessKeyId)
+	query.Set("SignatureMethod", signatureMethod)
+	query.Set("SignatureVersion", signatureVersion)
+	query.Set("SignatureNonce", nonce())
+	query.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	canonicalized := canonicalize(query)
+	signature := sign("GET", canonicalized, r.parameters.AccessKeySecret)
+	URL := r.parameters.getEndpoint() + "/?" + canonicalized + "&Signature=" + percentEncode(signature)
+
+	log.Debugf("action:%s", action)
+	response, err := client.R().SetContext(ctx).Get(URL)
+	if err != nil {
+		return 0, err
+	}
+	log.Tracef("response: %v", response)
+
+	err = json.Unmarshal(response.Body(), result)
+	if err != nil {
+		return response.StatusCode(), fmt.Errorf("error unmarshalling response %s: %w", response.String(), err)
+	}
+	return response.StatusCode(), nil
+}
+
+// rr return the host record, "@" for the root domain
+func (r *Request) rr() string {
+	if r.parameters.SubDomain == "" {
+		return "@"
+	}
+	return r.parameters.SubDomain
+}
+
+// canonicalize sort the query by key and percent-encode each key and value
+func canonicalize(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pieces := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pieces = append(pieces, percentEncode(k)+"="+percentEncode(query.Get(k)))
+	}
+	return strings.Join(pieces, "&")
+}
+
+// sign return the HMAC-SHA1 signature of the canonicalized query string
+// StringToSign = HTTPMethod + "&" + percentEncode("/") + "&" + percentEncode(CanonicalizedQueryString)
+func sign(method string, canonicalized string, secret string) string {
+	stringToSign := method + "&" + percentEncode("/") + "&" + percentEncode(canonicalized)
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// percentEncode encode s following RFC 3986, which is required by the signature
+func percentEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	s = strings.ReplaceAll(s, "%7E", "~")
+	return s
+}
+
+// nonce return a random string to prevent replay attack
+func nonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type errorResponse struct {
+	RequestId string `json:"RequestId"`
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+}
+
+type describeSubDomainRecordsResponse struct {
+	errorResponse
+	TotalCount    int `json:"TotalCount"`
+	DomainRecords struct {
+		Record []record `json:"Record"`
+	} `json:"DomainRecords"`
+}
+
+type record struct {
+	RecordId   string `json:"RecordId"`
+	RR         string `json:"RR"`
+	Type       string `json:"Type"`
+	Value      string `json:"Value"`
+	Line       string `json:"Line"`
+	TTL        int    `json:"TTL"`
+	DomainName string `json:"DomainName"`
+}
+
+type updateDomainRecordResponse struct {
+	errorResponse
+	RecordId string `json:"RecordId"`
+}
@@ -0,0 +1,199 @@
+package alidns
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"GodDns/core"
+)
+
+const secret = "testsecret"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		query, err := url.ParseQuery(r.URL.RawQuery)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature := query.Get("Signature")
+		query.Del("Signature")
+		if sign(r.Method, canonicalize(query), secret) != signature {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"RequestId":"req0","Code":"SignatureDoesNotMatch","Message":"Specified signature is not matched with our calculation."}`)
+			return
+		}
+
+		switch query.Get("Action") {
+		case "DescribeSubDomainRecords":
+			if query.Get("SubDomain") == "mail.example.com" {
+				_, _ = io.WriteString(w, `{"RequestId":"req1","TotalCount":2,"DomainRecords":{"Record":[{"RecordId":"rec2","RR":"mail","Type":"A","Value":"1.1.1.1","Line":"default","TTL":600,"DomainName":"example.com"},{"RecordId":"rec3","RR":"mail","Type":"A","Value":"1.1.1.2","Line":"default","TTL":600,"DomainName":"example.com"}]}}`)
+				return
+			}
+			if query.Get("SubDomain") != "www.example.com" {
+				_, _ = io.WriteString(w, `{"RequestId":"req1","TotalCount":0,"DomainRecords":{"Record":[]}}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"RequestId":"req1","TotalCount":1,"DomainRecords":{"Record":[{"RecordId":"rec1","RR":"www","Type":"A","Value":"1.1.1.1","Line":"default","TTL":600,"DomainName":"example.com"}]}}`)
+		case "UpdateDomainRecord":
+			if query.Get("RecordId") != "rec1" || query.Get("RR") != "www" || query.Get("Value") != "2.2.2.2" {
+				t.Errorf("unexpected query %v", query)
+			}
+			_, _ = io.WriteString(w, `{"RequestId":"req2","RecordId":"rec1"}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"RequestId":"req3","Code":"InvalidAction","Message":"unknown action"}`)
+		}
+	}))
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		AccessKeyId:     "testid",
+		AccessKeySecret: secret,
+		Domain:          "example.com",
+		SubDomain:       "www",
+		Value:           "2.2.2.2",
+		TTL:             600,
+		Type:            "A",
+		Endpoint:        server.URL,
+	}
+	r, _ := p.ToRequest()
+	err := r.MakeRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d: %v", r.Status().Status, r.Status().MG.GetMsgOf(core.Error))
+	}
+	if r.ToParameters().(*Parameters).RecordId != "rec1" {
+		t.Error("record id should be set")
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Info))
+}
+
+func TestRequest_MakeRequestWithBadSecret(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		AccessKeyId:     "testid",
+		AccessKeySecret: "bad secret",
+		Domain:          "example.com",
+		SubDomain:       "www",
+		Value:           "2.2.2.2",
+		TTL:             600,
+		Type:            "A",
+		Endpoint:        server.URL,
+	}
+	r, _ := p.ToRequest()
+	err := r.MakeRequest()
+	if err == nil {
+		t.Fatal("should return error")
+	}
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Error))
+}
+
+func TestRequest_MakeRequestWithoutRecord(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		AccessKeyId:     "testid",
+		AccessKeySecret: secret,
+		Domain:          "example.com",
+		SubDomain:       "ftp",
+		Value:           "2.2.2.2",
+		TTL:             600,
+		Type:            "A",
+		Endpoint:        server.URL,
+	}
+	r, _ := p.ToRequest()
+	if r.MakeRequest() == nil {
+		t.Fatal("should return error")
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Error))
+}
+
+func TestRequest_MakeRequestWithAmbiguousRecords(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		AccessKeyId:     "testid",
+		AccessKeySecret: secret,
+		Domain:          "example.com",
+		SubDomain:       "mail",
+		Value:           "2.2.2.2",
+		TTL:             600,
+		Type:            "A",
+		Endpoint:        server.URL,
+	}
+	r, _ := p.ToRequest()
+	if r.MakeRequest() == nil {
+		t.Fatal("should return error when more than one record matches")
+	}
+	if r.ToParameters().(*Parameters).RecordId != "" {
+		t.Error("record id should not be set")
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Error))
+}
+
+func TestPercentEncode(t *testing.T) {
+	cases := map[string]string{
+		"a b":   "a%20b",
+		"a*b":   "a%2Ab",
+		"a~b":   "a~b",
+		"/":     "%2F",
+		"a=b&c": "a%3Db%26c",
+	}
+	for in, expected := range cases {
+		if got := percentEncode(in); got != expected {
+			t.Errorf("percentEncode(%q) = %q, expect %q", in, got, expected)
+		}
+	}
+}
+
+func TestCode2status(t *testing.T) {
+	if code2status(http.StatusOK, Success).Status != core.Success {
+		t.Error("2xx without error code should be mapped to core.Success")
+	}
+	if code2status(http.StatusInternalServerError, Success).Status != core.Failed {
+		t.Error("non-2xx without error code should be mapped to core.Failed")
+	}
+	if code2status(http.StatusBadRequest, DomainRecordDuplicate).Status != core.Success {
+		t.Error("DomainRecordDuplicate means the record already holds the value")
+	}
+	if code2status(http.StatusBadRequest, SignatureDoesNotMatch).Status != core.Failed {
+		t.Error("SignatureDoesNotMatch should be mapped to core.Failed")
+	}
+}
+
+// TestSign check the example in https://help.aliyun.com/document_detail/29747.html
+func TestSign(t *testing.T) {
+	query := url.Values{}
+	query.Set("Format", "XML")
+	query.Set("AccessKeyId", "testid")
+	query.Set("Action", "DescribeDomainRecords")
+	query.Set("SignatureMethod", "HMAC-SHA1")
+	query.Set("DomainName", "example.com")
+	query.Set("SignatureNonce", "f59ed6a9-83fc-473b-9cc6-99c95df3856e")
+	query.Set("SignatureVersion", "1.0")
+	query.Set("Version", "2015-01-09")
+	query.Set("Timestamp", "2016-03-24T16:41:54Z")
+
+	const expected = "uRpHwaSEt3J+6KQD//svCh/x+pI="
+	if got := sign("GET", canonicalize(query), "testsecret"); got != expected {
+		t.Errorf("sign() = %s, expect %s", got, expected)
+	}
+}
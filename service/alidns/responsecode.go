@@ -0,0 +1,101 @@
+package alidns
+
+import (
+	"fmt"
+
+	"GodDns/core"
+)
+
+// https://help.aliyun.com/document_detail/29774.html
+const (
+	Success = ""
+
+	InvalidAccessKeyIdNotFound = "InvalidAccessKeyId.NotFound"
+	InvalidAccessKeyIdInactive = "InvalidAccessKeyId.Inactive"
+	SignatureDoesNotMatch      = "SignatureDoesNotMatch"
+	IncompleteSignature        = "IncompleteSignature"
+	SignatureNonceUsed         = "SignatureNonceUsed"
+	InvalidTimeStampExpired    = "InvalidTimeStamp.Expired"
+	MissingParameter           = "MissingParameter"
+	Forbidden                  = "Forbidden"
+	ForbiddenRAM               = "Forbidden.RAM"
+	Throttling                 = "Throttling"
+	ThrottlingUser             = "Throttling.User"
+
+	InvalidDomainNameNoExist    = "InvalidDomainName.NoExist"
+	DomainRecordDuplicate       = "DomainRecordDuplicate"
+	DomainRecordConflict        = "DomainRecordConflict"
+	DomainRecordNotBelongToUser = "DomainRecordNotBelongToUser"
+	InvalidRRFormat             = "InvalidRR.Format"
+	RecordForbiddenDNSChange    = "RecordForbidden.DNSChange"
+	LineNotSupport              = "LineNotSupport"
+	QuotaExceededTTL            = "QuotaExceeded.TTL"
+
+	InternalError      = "InternalError"
+	ServiceUnavailable = "ServiceUnavailable"
+)
+
+// code2status
+// convert the http status code and the error code to message and set status.Status
+// only a 2xx response without error code is successful
+func code2status(httpStatus int, code string) *core.Status {
+	msg := newStatus()
+	is2xx := httpStatus >= 200 && httpStatus <= 299
+	switch code {
+	case Success:
+		if !is2xx {
+			msg.MG.AddError(fmt.Sprintf("HTTP %d", httpStatus))
+		} else {
+			msg.MG.AddInfo("接口调用成功")
+		}
+	case InvalidAccessKeyIdNotFound:
+		msg.MG.AddError("AccessKeyId 不存在")
+	case InvalidAccessKeyIdInactive:
+		msg.MG.AddError("AccessKeyId 已被禁用")
+	case SignatureDoesNotMatch:
+		msg.MG.AddError("签名错误, 请检查 AccessKeySecret")
+	case IncompleteSignature:
+		msg.MG.AddError("签名不完整")
+	case SignatureNonceUsed:
+		msg.MG.AddError("签名随机数已被使用")
+	case InvalidTimeStampExpired:
+		msg.MG.AddError("时间戳已过期, 请检查系统时间")
+	case MissingParameter:
+		msg.MG.AddError("缺少参数")
+	case Forbidden, ForbiddenRAM:
+		msg.MG.AddError("无接口权限")
+	case Throttling, ThrottlingUser:
+		msg.MG.AddError("请求次数超过限制")
+	case InvalidDomainNameNoExist:
+		msg.MG.AddError("域名不存在")
+	case DomainRecordDuplicate:
+		msg.MG.AddInfo("记录已存在")
+	case DomainRecordConflict:
+		msg.MG.AddError("记录与其他记录冲突")
+	case DomainRecordNotBelongToUser:
+		msg.MG.AddError("记录不属于您")
+	case InvalidRRFormat:
+		msg.MG.AddError("主机记录非法")
+	case RecordForbiddenDNSChange:
+		msg.MG.AddError("域名被锁定, 禁止修改记录")
+	case LineNotSupport:
+		msg.MG.AddError("解析线路非法")
+	case QuotaExceededTTL:
+		msg.MG.AddError("TTL 超出限制")
+	case InternalError:
+		msg.MG.AddError("内部错误")
+	case ServiceUnavailable:
+		msg.MG.AddError("服务不可用")
+	default:
+		msg.MG.AddError("未知错误")
+	}
+
+	// updating a record to the value it already holds returns DomainRecordDuplicate
+	if (code == Success && is2xx) || code == DomainRecordDuplicate {
+		msg.Status = core.Success
+	} else {
+		msg.Status = core.Failed
+	}
+
+	return msg
+}
@@ -0,0 +1,167 @@
+package clouddns
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	json "GodDns/util/json"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// scope of Cloud DNS read and write
+	scope = "https://www.googleapis.com/auth/ndev.clouddns.readwrite"
+	// grantType of exchanging a JWT for an access token, see RFC 7523
+	grantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	// jwtLifetime is the max lifetime of JWT accepted by Google
+	jwtLifetime = time.Hour
+)
+
+// serviceAccountKey is the json key of a service account
+type serviceAccountKey struct {
+	Type         string `json:"type"`
+	ProjectId    string `json:"project_id"`
+	PrivateKeyId string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenUri     string `json:"token_uri"`
+}
+
+// readServiceAccountKey read the service account key from json file
+func readServiceAccountKey(path string) (*serviceAccountKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading credentials file: %w", err)
+	}
+	key := &serviceAccountKey{}
+	if err = json.Unmarshal(data, key); err != nil {
+		return nil, fmt.Errorf("error parsing credentials file %s: %w", path, err)
+	}
+	if key.Type != "service_account" {
+		return nil, fmt.Errorf("credentials file %s is not a service account key, type is %q", path, key.Type)
+	}
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("client_email or private_key is missing in credentials file %s", path)
+	}
+	return key, nil
+}
+
+// rsaKey parse the private key in PEM, PKCS #8 or PKCS #1
+func (k *serviceAccountKey) rsaKey() (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(k.PrivateKey))
+	if block == nil {
+		return nil, errors.New("private key is not in PEM format")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not a RSA key")
+	}
+	return key, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
+}
+
+type jwtClaims struct {
+	Iss   string `json:"iss"`
+	Scope string `json:"scope"`
+	Aud   string `json:"aud"`
+	Iat   int64  `json:"iat"`
+	Exp   int64  `json:"exp"`
+}
+
+// jwt mint a JWT signed with RS256 asserting the service account, audience is the token endpoint
+func (k *serviceAccountKey) jwt(aud string, now time.Time) (string, error) {
+	privateKey, err := k.rsaKey()
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(jwtHeader{Alg: "RS256", Typ: "JWT", Kid: k.PrivateKeyId})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(jwtClaims{
+		Iss:   k.ClientEmail,
+		Scope: scope,
+		Aud:   aud,
+		Iat:   now.Unix(),
+		Exp:   now.Add(jwtLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hashed := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// fetchToken exchange a JWT for an access token at tokenEndpoint
+func fetchToken(ctx context.Context, client *resty.Client, key *serviceAccountKey, tokenEndpoint string) (core.Token, error) {
+	now := time.Now()
+	assertion, err := key.jwt(tokenEndpoint, now)
+	if err != nil {
+		return core.Token{}, fmt.Errorf("error minting JWT: %w", err)
+	}
+
+	log.Debugf("get access token of %s from %s", key.ClientEmail, tokenEndpoint)
+	response, err := client.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{
+			"grant_type": grantType,
+			"assertion":  assertion,
+		}).
+		Post(tokenEndpoint)
+	if err != nil {
+		return core.Token{}, err
+	}
+
+	res := &tokenResponse{}
+	if err = json.Unmarshal(response.Body(), res); err != nil {
+		return core.Token{}, fmt.Errorf("error unmarshalling token response %s: %w", response.String(), err)
+	}
+	if response.IsError() || res.AccessToken == "" {
+		return core.Token{}, fmt.Errorf("error getting access token: %s %s", res.Error, res.ErrorDescription)
+	}
+
+	token := core.Token{AccessToken: res.AccessToken, TokenType: res.TokenType}
+	if res.ExpiresIn > 0 {
+		token.Expiry = now.Add(time.Duration(res.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	TokenType        string `json:"token_type"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
@@ -0,0 +1,134 @@
+package clouddns
+
+import (
+	"bytes"
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util"
+	"GodDns/util/collections"
+	"gopkg.in/ini.v1"
+)
+
+func init() {
+	core.Add2FactoryList(configFactoryInstance)
+}
+
+var (
+	configFactoryInstance ConfigFactory
+	configInstance        Config
+)
+
+// ConfigFactory is a factory that create a new Config
+type ConfigFactory struct{}
+
+// GetName return the name of clouddns
+func (c ConfigFactory) GetName() string {
+	return serviceName
+}
+
+// Get return a singleton Config
+func (c ConfigFactory) Get() core.Config {
+	return &configInstance
+}
+
+// New return a new Config
+func (c ConfigFactory) New() *core.Config {
+	var config core.Config = &Config{}
+	return &config
+}
+
+// Config implements core.Config
+type Config struct{}
+
+// GetName Get name of service
+func (c Config) GetName() string {
+	return serviceName
+}
+
+// GenerateDefaultConfigInfo Create default config
+func (c Config) GenerateDefaultConfigInfo() (core.ConfigStr, error) {
+	p := GenerateDefaultConfigInfo()
+	return c.GenerateConfigInfo(&p, 0)
+}
+
+// ReadConfig Read config file
+// Parameters: sec ini.Section
+// Return: core.Parameters and error
+// if any error occurs, returned Parameters will be nil
+func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
+	names := [6]string{"CredentialsFile", "ManagedZone", "Domain", "Value", "TTL", "Type"}
+
+	p := Parameters{}
+	var domains []string
+	for _, name := range names {
+		if !sec.HasKey(name) {
+			return nil, core.NewMissKeyErr(name, serviceName)
+		} else {
+			switch name {
+			case "Domain":
+				domain := sec.Key(name).String()
+				domains = collections.AppendUnique(nil, strings.Fields(strings.ReplaceAll(domain, ",", " "))...)
+			case "TTL":
+				ttl, err := sec.Key(name).Uint64()
+				if err != nil {
+					return nil, err
+				}
+				p.TTL = ttl
+			case "Type":
+				p.Type = netutil.Type2Str(sec.Key(name).String())
+			default:
+				err := util.SetVariable(&p, name, sec.Key(name).String())
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// optional keys, empty if not exist
+	p.Project = sec.Key("Project").String()
+	p.Endpoint = sec.Key("Endpoint").String()
+	p.TokenEndpoint = sec.Key("TokenEndpoint").String()
+	p.Device = sec.Key("Device").String()
+	options, err := core.ReadDeviceOptions(sec)
+	if err != nil {
+		return nil, err
+	}
+	p.DeviceOptions = options
+
+	ps := make([]core.Parameters, 0, len(domains))
+	for _, domain := range domains {
+		if domain == "" {
+			continue
+		}
+		ps = append(ps, &Parameters{
+			CredentialsFile: p.CredentialsFile,
+			Project:         p.Project,
+			ManagedZone:     p.ManagedZone,
+			Domain:          domain,
+			Value:           p.Value,
+			TTL:             p.TTL,
+			Type:            p.Type,
+			Endpoint:        p.Endpoint,
+			TokenEndpoint:   p.TokenEndpoint,
+			Device:          p.Device,
+			DeviceOptions:   p.DeviceOptions,
+		})
+	}
+	return ps, nil
+}
+
+// GenerateConfigInfo
+// Generate KeyValue style config
+func (c Config) GenerateConfigInfo(parameters core.Parameters, No uint) (core.ConfigStr, error) {
+	buffer := bytes.NewBufferString(core.ConfigHead(parameters, No))
+	buffer.WriteString(util.Convert2KeyValue(core.Format, parameters))
+	buffer.Write([]byte{'\n', '\n'})
+
+	return core.ConfigStr{
+		Name:    serviceName,
+		Content: buffer.String(),
+	}, nil
+}
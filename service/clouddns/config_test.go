@@ -0,0 +1,110 @@
+package clouddns
+
+import (
+	"strings"
+	"testing"
+
+	"GodDns/core"
+	"gopkg.in/ini.v1"
+)
+
+func TestConfig_GenerateDefaultConfigInfo(t *testing.T) {
+	config, err := Config{}.GenerateDefaultConfigInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(config.Content)
+}
+
+func TestConfig_ReadConfig(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[CloudDNS]
+CredentialsFile=/etc/GodDns/key.json
+ManagedZone=example-com
+Domain=www.example.com,example.com,www.example.com
+Value=1.2.3.4
+TTL=300
+Type=4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("CloudDNS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 {
+		t.Fatalf("expect 2 parameters, got %d", len(ps))
+	}
+	for _, p := range ps {
+		p := p.(*Parameters)
+		if p.Type != "A" || p.getEndpoint() != DefaultEndpoint || p.getName() != p.Domain+"." {
+			t.Errorf("unexpected parameters %+v", p)
+		}
+		t.Log(p.Target())
+	}
+}
+
+func TestConfig_ReadConfigMissingKey(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[CloudDNS]
+ManagedZone=example-com
+Domain=www.example.com
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (Config{}).ReadConfig(*cfg.Section("CloudDNS")); err == nil {
+		t.Error("should return error when key is missing")
+	}
+}
+
+func TestConfig_SaveMerged(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[CloudDNS]
+CredentialsFile=/etc/GodDns/key.json
+Project=my-project
+ManagedZone=example-com
+Domain=www.example.com,example.com
+Value=1.2.3.4
+TTL=300
+Type=A
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := Config{}.ReadConfig(*cfg.Section("CloudDNS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("expect 1 merged parameters, got %d", len(merged))
+	}
+	saved, err := merged[0].SaveConfig(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(saved.Content, "Domain=www.example.com,example.com\n") {
+		t.Errorf("unexpected config:\n%s", saved.Content)
+	}
+
+	cfg, err = ini.Load([]byte(saved.Content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Config{}.ReadConfig(*cfg.Section("CloudDNS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != len(ps) {
+		t.Fatalf("expect %d parameters read again, got %d", len(ps), len(again))
+	}
+	for i := range again {
+		if *again[i].(*Parameters) != *ps[i].(*Parameters) {
+			t.Errorf("expect %+v, got %+v", ps[i], again[i])
+		}
+	}
+}
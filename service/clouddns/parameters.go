@@ -0,0 +1,142 @@
+// Package clouddns use Google Cloud DNS API(or compatible JSON-REST API) to update DNS record,
+// authenticating with a service account key
+package clouddns
+
+import (
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util/collections"
+)
+
+const serviceName = "CloudDNS"
+
+const (
+	// DefaultEndpoint is the endpoint of Cloud DNS API
+	DefaultEndpoint = "https://dns.googleapis.com/dns/v1"
+	// DefaultTokenEndpoint is the endpoint to exchange a JWT for an access token
+	DefaultTokenEndpoint = "https://oauth2.googleapis.com/token"
+)
+
+// Parameters implements DeviceOverridable and Mergeable
+// CredentialsFile is the path of service account key in json, which is used to mint a JWT
+// Project is read from CredentialsFile if not set
+// Domain is the full record name like www.example.com in the managed zone ManagedZone
+// Device is Device name when overriding ip with specific Device/interface
+type Parameters struct {
+	CredentialsFile string `KeyValue:"CredentialsFile,path of service account key in json, create at https://console.cloud.google.com/iam-admin/serviceaccounts"`
+	Project         string `KeyValue:"Project,project id, read from CredentialsFile if not set"`
+	ManagedZone     string `KeyValue:"ManagedZone,name of the managed zone like example-com"`
+	Domain          string `KeyValue:"Domain,full record name like www.example.com, if you have multiple records to update, set like Domain=www.example.com,ftp.example.com"`
+	Value           string `KeyValue:"Value,IP address like 6.6.6.6"`
+	TTL             uint64 `KeyValue:"TTL,Time-To-Live, 300(default)"`
+	Type            string `KeyValue:"Type,A/AAAA/4/6"`
+	Endpoint        string `KeyValue:"Endpoint,API endpoint, https://dns.googleapis.com/dns/v1(default)"`
+	TokenEndpoint   string `KeyValue:"TokenEndpoint,endpoint to get access token, token_uri in CredentialsFile or https://oauth2.googleapis.com/token(default)"`
+	Device          string `KeyValue:"Device,device/net interface name"`
+	core.DeviceOptions
+}
+
+// GenerateDefaultConfigInfo return Default config
+func GenerateDefaultConfigInfo() Parameters {
+	return Parameters{
+		CredentialsFile: "/path/to/service-account.json",
+		ManagedZone:     "example-com",
+		Domain:          "www.example.com,mail.example.com...",
+		Value:           "1.2.3.4",
+		TTL:             300,
+		Type:            "A/AAAA/4/6",
+		Device:          "your device/net interface name",
+	}
+}
+
+// GetName return "CloudDNS"
+func (p *Parameters) GetName() string {
+	return serviceName
+}
+
+// SaveConfig return core.ConfigStr
+func (p *Parameters) SaveConfig(No uint) (core.ConfigStr, error) {
+	return configInstance.GenerateConfigInfo(p, No)
+}
+
+// Target return the record name
+func (p *Parameters) Target() string {
+	return p.Domain
+}
+
+// ToRequest Convert to core.Request
+func (p *Parameters) ToRequest() (core.Request, error) {
+	r := new(Request)
+	r.Init(*p)
+	return r, nil
+}
+
+// SetValue set ip
+func (p *Parameters) SetValue(value string) {
+	p.Value = value
+}
+
+// GetIP return ip value
+func (p *Parameters) GetIP() string {
+	return p.Value
+}
+
+// GetType return Type like "4" or "6" and "" if invalid type
+func (p *Parameters) GetType() string {
+	return netutil.Type2Num(p.Type)
+}
+
+// IsTypeSet return whether the type is set correctly
+func (p *Parameters) IsTypeSet() bool {
+	return p.Type == "A" || p.Type == "AAAA"
+}
+
+// GetDevice return Device name
+func (p *Parameters) GetDevice() string {
+	return p.Device
+}
+
+// IsDeviceSet return whether the Device is set
+func (p *Parameters) IsDeviceSet() bool {
+	return p.Device != ""
+}
+
+// MergeableWith return true if the two Parameters differ only by Domain
+func (p *Parameters) MergeableWith(other core.Parameters) bool {
+	o, ok := other.(*Parameters)
+	if !ok {
+		return false
+	}
+	return p.CredentialsFile == o.CredentialsFile && p.Project == o.Project && p.ManagedZone == o.ManagedZone &&
+		p.Value == o.Value && p.TTL == o.TTL && p.Type == o.Type && p.Endpoint == o.Endpoint &&
+		p.TokenEndpoint == o.TokenEndpoint && p.Device == o.Device && p.DeviceOptions == o.DeviceOptions
+}
+
+// Merge return a new Parameters whose Domain is like "www.example.com,ftp.example.com"
+func (p *Parameters) Merge(others ...core.Parameters) core.Parameters {
+	merged := *p
+	domains := []string{p.Domain}
+	for _, other := range others {
+		domains = collections.AppendUnique(domains, other.(*Parameters).Domain)
+	}
+	merged.Domain = strings.Join(domains, ",")
+	return &merged
+}
+
+// getEndpoint return Endpoint or DefaultEndpoint if not set
+func (p *Parameters) getEndpoint() string {
+	if p.Endpoint == "" {
+		return DefaultEndpoint
+	}
+	return strings.TrimSuffix(p.Endpoint, "/")
+}
+
+// getName return the record name ending with a dot like www.example.com.
+func (p *Parameters) getName() string {
+	if strings.HasSuffix(p.Domain, ".") {
+		return p.Domain
+	}
+	return p.Domain + "."
+}
@@ -0,0 +1,321 @@
+package clouddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	"GodDns/netutil"
+	json "GodDns/util/json"
+	"github.com/go-resty/resty/v2"
+)
+
+const requestTimeout = 20 * time.Second
+
+// usage
+// r:=clouddns.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request and core.ThroughProxy
+type Request struct {
+	parameters Parameters
+	status     core.Status
+
+	key           *serviceAccountKey
+	project       string
+	tokenEndpoint string
+}
+
+// Init set parameter
+func (r *Request) Init(parameters Parameters) {
+	r.parameters = parameters
+}
+
+// Target return target domain
+func (r *Request) Target() string {
+	return r.parameters.Target()
+}
+
+// GetName return "CloudDNS"
+func (r *Request) GetName() string {
+	return serviceName
+}
+
+// ToParameters return core.Service
+func (r *Request) ToParameters() core.Service {
+	return &r.parameters
+}
+
+// Status return core.Status which contains execution result etc.
+func (r *Request) Status() core.Status {
+	return r.status
+}
+
+func newStatus() *core.Status {
+	return &core.Status{
+		Name:   serviceName,
+		Status: core.NotExecute,
+		MG:     core.NewDefaultMsgGroup(),
+	}
+}
+
+// MakeRequest 1.get access token 2.get the record set 3.create a change which deletes the old one and adds the new one
+func (r *Request) MakeRequest() error {
+	client := core.MainClientPool.Get().(*resty.Client)
+	defer core.MainClientPool.Put(client)
+	return r.execute(client)
+}
+
+// RequestThroughProxy make request through the proxies in netutil.GlobalProxies one by one until one succeeds
+func (r *Request) RequestThroughProxy() error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	iter := netutil.GlobalProxies.GetProxyIter()
+	if iter.Len() == 0 {
+		return r.fail(errors.New("no proxy available"))
+	}
+
+	var err error
+	for iter.NotLast() {
+		proxy := iter.Next()
+		// never set proxy on a pooled client
+		client := core.MainClientPool.New().(*resty.Client).SetProxy(proxy)
+		err = r.execute(client)
+		// the server has answered, other proxies will get the same answer
+		var apiErr *apiError
+		if err == nil || errors.As(err, &apiErr) {
+			return err
+		}
+		errMsg := fmt.Sprintf("request error through proxy %s: %v", proxy, err)
+		r.status.MG.AddError(errMsg)
+		log.Errorf(errMsg)
+	}
+	return err
+}
+
+func (r *Request) execute(client *resty.Client) error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	if err := r.prepare(); err != nil {
+		return r.fail(err)
+	}
+	if !r.parameters.IsTypeSet() {
+		return r.fail(fmt.Errorf("unsupported type %s", r.parameters.Type))
+	}
+	if !netutil.IsIpValid(r.parameters.Value) {
+		return r.fail(fmt.Errorf("invalid ip %s", r.parameters.Value))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	existing, err := r.getRecordSet(ctx, client)
+	if err != nil {
+		return r.failWith(err)
+	}
+
+	wanted := resourceRecordSet{
+		Kind:    "dns#resourceRecordSet",
+		Name:    r.parameters.getName(),
+		Type:    r.parameters.Type,
+		TTL:     r.parameters.TTL,
+		Rrdatas: []string{r.parameters.Value},
+	}
+	if existing != nil && existing.TTL == wanted.TTL &&
+		len(existing.Rrdatas) == 1 && netutil.IpEqual(existing.Rrdatas[0], wanted.Rrdatas[0]) {
+		r.status = *newStatus()
+		r.status.Status = core.Unchanged
+		r.status.MG.AddInfo(fmt.Sprintf("%s %s is already %s", r.Target(), r.parameters.Type, r.parameters.Value))
+		return nil
+	}
+
+	c := change{Kind: "dns#change", Additions: []resourceRecordSet{wanted}}
+	if existing != nil {
+		c.Deletions = []resourceRecordSet{*existing}
+	}
+	res := &change{}
+	path := "/projects/" + r.project + "/managedZones/" + r.parameters.ManagedZone + "/changes"
+	if err = r.call(ctx, client, http.MethodPost, path, nil, c, res); err != nil {
+		return r.failWith(err)
+	}
+
+	r.status = *code2status(Success)
+	r.status.MG.AddInfo(fmt.Sprintf("%s %s set to %s in change %s, status %s",
+		r.Target(), r.parameters.Type, r.parameters.Value, res.Id, res.Status))
+	return nil
+}
+
+// prepare read the credentials file, decide project and token endpoint
+func (r *Request) prepare() error {
+	key, err := readServiceAccountKey(r.parameters.CredentialsFile)
+	if err != nil {
+		return err
+	}
+	r.key = key
+
+	r.project = r.parameters.Project
+	if r.project == "" {
+		r.project = key.ProjectId
+	}
+	if r.project == "" {
+		return fmt.Errorf("project is not set and not found in credentials file %s", r.parameters.CredentialsFile)
+	}
+
+	switch {
+	case r.parameters.TokenEndpoint != "":
+		r.tokenEndpoint = r.parameters.TokenEndpoint
+	case key.TokenUri != "":
+		r.tokenEndpoint = key.TokenUri
+	default:
+		r.tokenEndpoint = DefaultTokenEndpoint
+	}
+	return nil
+}
+
+// getRecordSet return the record set of Domain and Type, nil if not exist
+func (r *Request) getRecordSet(ctx context.Context, client *resty.Client) (*resourceRecordSet, error) {
+	res := &resourceRecordSetsListResponse{}
+	path := "/projects/" + r.project + "/managedZones/" + r.parameters.ManagedZone + "/rrsets"
+	query := map[string]string{
+		"name": r.parameters.getName(),
+		"type": r.parameters.Type,
+	}
+	if err := r.call(ctx, client, http.MethodGet, path, query, nil, res); err != nil {
+		return nil, err
+	}
+	for i := range res.Rrsets {
+		if strings.EqualFold(res.Rrsets[i].Name, r.parameters.getName()) && res.Rrsets[i].Type == r.parameters.Type {
+			return &res.Rrsets[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// tokenCacheKey is the key of token in core.MainTokenCache
+func (r *Request) tokenCacheKey() string {
+	return r.key.ClientEmail + "|" + r.tokenEndpoint + "|" + scope
+}
+
+// token return the cached access token, or get a new one
+func (r *Request) token(ctx context.Context, client *resty.Client) (core.Token, error) {
+	return core.MainTokenCache.Get(r.tokenCacheKey(), func() (core.Token, error) {
+		return fetchToken(ctx, client, r.key, r.tokenEndpoint)
+	})
+}
+
+// call make an authorized request to path, unmarshal the response to result
+// the cached token is invalidated and the request is retried once if the token is rejected
+// *apiError is returned if the API answers an error
+func (r *Request) call(ctx context.Context, client *resty.Client, method string, path string, query map[string]string, body any, result any) error {
+	u := r.parameters.getEndpoint() + path
+	for retried := false; ; retried = true {
+		token, err := r.token(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		req := client.R().SetContext(ctx).SetAuthToken(token.AccessToken).SetQueryParams(query)
+		if body != nil {
+			data, err := json.Marshal(body)
+			if err != nil {
+				return err
+			}
+			req.SetHeader("Content-Type", "application/json").SetBody(data)
+		}
+		log.Debugf("%s %s", method, u)
+		response, err := req.Execute(method, u)
+		if err != nil {
+			return err
+		}
+		log.Tracef("response: %v", response)
+
+		if response.StatusCode() == http.StatusUnauthorized && !retried {
+			log.Debugf("access token rejected, get a new one")
+			core.MainTokenCache.Invalidate(r.tokenCacheKey())
+			continue
+		}
+
+		if response.IsError() {
+			e := &errorResponse{}
+			if err = json.Unmarshal(response.Body(), e); err != nil || e.Error.Message == "" {
+				return fmt.Errorf("unexpected response %s: %s", response.Status(), response.String())
+			}
+			return &apiError{Code: e.Error.Code, Status: e.Error.Status, Message: e.Error.Message}
+		}
+
+		if err = json.Unmarshal(response.Body(), result); err != nil {
+			return fmt.Errorf("error unmarshalling response %s: %w", response.String(), err)
+		}
+		return nil
+	}
+}
+
+// fail set status to Failed with err and return err
+func (r *Request) fail(err error) error {
+	r.status.Status = core.Failed
+	r.status.MG.AddError(err.Error())
+	return err
+}
+
+// failWith set status by err, which may be an *apiError, and return err
+func (r *Request) failWith(err error) error {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		r.status = *code2status(apiErr.Status)
+		r.status.MG.AddError(fmt.Sprintf("%s at %s", apiErr.Message, r.Target()))
+		return err
+	}
+	return r.fail(err)
+}
+
+// apiError is the error answered by Cloud DNS
+type apiError struct {
+	Code    int
+	Status  string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Code, e.Status, e.Message)
+}
+
+type resourceRecordSet struct {
+	Kind    string   `json:"kind,omitempty"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	TTL     uint64   `json:"ttl"`
+	Rrdatas []string `json:"rrdatas"`
+}
+
+type resourceRecordSetsListResponse struct {
+	Rrsets        []resourceRecordSet `json:"rrsets"`
+	NextPageToken string              `json:"nextPageToken"`
+}
+
+// change is both the request and response of changes.create
+type change struct {
+	Kind      string              `json:"kind,omitempty"`
+	Additions []resourceRecordSet `json:"additions,omitempty"`
+	Deletions []resourceRecordSet `json:"deletions,omitempty"`
+	Id        string              `json:"id,omitempty"`
+	Status    string              `json:"status,omitempty"`
+}
+
+// errorResponse is like {"error":{"code":404,"message":"...","status":"NOT_FOUND"}}
+type errorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
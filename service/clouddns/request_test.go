@@ -0,0 +1,238 @@
+package clouddns
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"GodDns/core"
+	json "GodDns/util/json"
+)
+
+// fakeCloudDNS is a token endpoint and a Cloud DNS API of project p1 and managed zone z1
+type fakeCloudDNS struct {
+	t         *testing.T
+	publicKey *rsa.PublicKey
+
+	mu          sync.Mutex
+	tokenHits   int
+	rejectToken bool // reject the first token once
+	tokens      int
+	rrsets      []resourceRecordSet
+	changes     []change
+}
+
+func (f *fakeCloudDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.URL.Path == "/token" {
+		f.tokenHits++
+		if err := r.ParseForm(); err != nil {
+			f.t.Error(err)
+		}
+		if r.PostForm.Get("grant_type") != grantType {
+			f.t.Errorf("unexpected grant_type %s", r.PostForm.Get("grant_type"))
+		}
+		f.verifyJWT(r.PostForm.Get("assertion"), "http://"+r.Host+"/token")
+		f.tokens++
+		_, _ = io.WriteString(w, `{"access_token":"token`+strconv.Itoa(f.tokens)+`","expires_in":3599,"token_type":"Bearer"}`)
+		return
+	}
+
+	auth := r.Header.Get("Authorization")
+	if f.rejectToken && auth == "Bearer token1" || !strings.HasPrefix(auth, "Bearer token") {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"error":{"code":401,"message":"Request had invalid authentication credentials.","status":"UNAUTHENTICATED"}}`)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/projects/p1/managedZones/z1/rrsets":
+		res := resourceRecordSetsListResponse{Rrsets: []resourceRecordSet{}}
+		for _, rrset := range f.rrsets {
+			if rrset.Name == r.URL.Query().Get("name") && rrset.Type == r.URL.Query().Get("type") {
+				res.Rrsets = append(res.Rrsets, rrset)
+			}
+		}
+		data, _ := json.Marshal(res)
+		_, _ = w.Write(data)
+	case r.Method == http.MethodPost && r.URL.Path == "/projects/p1/managedZones/z1/changes":
+		body, _ := io.ReadAll(r.Body)
+		c := change{}
+		if err := json.Unmarshal(body, &c); err != nil {
+			f.t.Error(err)
+		}
+		f.changes = append(f.changes, c)
+		_, _ = io.WriteString(w, `{"kind":"dns#change","id":"1","status":"pending"}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":{"code":404,"message":"The 'parameters.managedZone' resource named 'z2' does not exist.","status":"NOT_FOUND"}}`)
+	}
+}
+
+// verifyJWT verify the signature and claims of the JWT
+func (f *fakeCloudDNS) verifyJWT(assertion string, aud string) {
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		f.t.Fatalf("invalid JWT %s", assertion)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	hashed := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err = rsa.VerifyPKCS1v15(f.publicKey, crypto.SHA256, hashed[:], signature); err != nil {
+		f.t.Errorf("invalid signature: %v", err)
+	}
+
+	header := jwtHeader{}
+	data, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	if err = json.Unmarshal(data, &header); err != nil || header.Alg != "RS256" || header.Kid != "kid1" {
+		f.t.Errorf("unexpected header %s", data)
+	}
+	claims := jwtClaims{}
+	data, _ = base64.RawURLEncoding.DecodeString(parts[1])
+	if err = json.Unmarshal(data, &claims); err != nil {
+		f.t.Fatal(err)
+	}
+	if claims.Iss != "goddns@p1.iam.gserviceaccount.com" || claims.Scope != scope || claims.Aud != aud || claims.Exp-claims.Iat != 3600 {
+		f.t.Errorf("unexpected claims %s", data)
+	}
+}
+
+// writeKey write a service account key with a new RSA key in PKCS #8 to a temp file
+func writeKey(t *testing.T) (string, *rsa.PublicKey) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := serviceAccountKey{
+		Type:         "service_account",
+		ProjectId:    "p1",
+		PrivateKeyId: "kid1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  "goddns@p1.iam.gserviceaccount.com",
+		TokenUri:     "https://oauth2.googleapis.com/token",
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, &privateKey.PublicKey
+}
+
+func newTestRequest(t *testing.T, server *httptest.Server, credentials string, domain string) *Request {
+	t.Helper()
+	r := &Request{}
+	r.Init(Parameters{
+		CredentialsFile: credentials,
+		ManagedZone:     "z1",
+		Domain:          domain,
+		Value:           "1.2.3.4",
+		TTL:             300,
+		Type:            "A",
+		Endpoint:        server.URL,
+		TokenEndpoint:   server.URL + "/token",
+	})
+	return r
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	credentials, publicKey := writeKey(t)
+	f := &fakeCloudDNS{t: t, publicKey: publicKey, rrsets: []resourceRecordSet{
+		{Kind: "dns#resourceRecordSet", Name: "www.example.com.", Type: "A", TTL: 300, Rrdatas: []string{"5.6.7.8"}},
+	}}
+	server := httptest.NewServer(f)
+	defer server.Close()
+
+	// the record exists, so it is deleted and added
+	r := newTestRequest(t, server, credentials, "www.example.com")
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("unexpected status %d", r.Status().Status)
+	}
+	if len(f.changes) != 1 || len(f.changes[0].Deletions) != 1 || f.changes[0].Deletions[0].Rrdatas[0] != "5.6.7.8" ||
+		len(f.changes[0].Additions) != 1 || f.changes[0].Additions[0].Rrdatas[0] != "1.2.3.4" {
+		t.Errorf("unexpected changes %+v", f.changes)
+	}
+
+	// the record does not exist, so it is only added, and the token is cached
+	r = newTestRequest(t, server, credentials, "ftp.example.com")
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.changes) != 2 || len(f.changes[1].Deletions) != 0 || f.changes[1].Additions[0].Name != "ftp.example.com." {
+		t.Errorf("unexpected changes %+v", f.changes)
+	}
+	if f.tokenHits != 1 {
+		t.Errorf("token should be cached, got %d token requests", f.tokenHits)
+	}
+
+	// the record already holds the ip
+	f.rrsets = append(f.rrsets, f.changes[1].Additions[0])
+	r = newTestRequest(t, server, credentials, "ftp.example.com")
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Unchanged || len(f.changes) != 2 {
+		t.Errorf("unexpected status %d", r.Status().Status)
+	}
+}
+
+func TestRequest_MakeRequestTokenRejected(t *testing.T) {
+	credentials, publicKey := writeKey(t)
+	f := &fakeCloudDNS{t: t, publicKey: publicKey, rejectToken: true}
+	server := httptest.NewServer(f)
+	defer server.Close()
+
+	r := newTestRequest(t, server, credentials, "www.example.com")
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if f.tokenHits != 2 || len(f.changes) != 1 {
+		t.Errorf("expect a new token after rejected, got %d token requests and %d changes", f.tokenHits, len(f.changes))
+	}
+}
+
+func TestRequest_MakeRequestError(t *testing.T) {
+	credentials, publicKey := writeKey(t)
+	f := &fakeCloudDNS{t: t, publicKey: publicKey}
+	server := httptest.NewServer(f)
+	defer server.Close()
+
+	r := newTestRequest(t, server, credentials, "www.example.com")
+	r.parameters.ManagedZone = "z2"
+	err := r.MakeRequest()
+	if err == nil {
+		t.Fatal("should return error when managed zone does not exist")
+	}
+	if r.Status().Status != core.Failed {
+		t.Errorf("unexpected status %d", r.Status().Status)
+	}
+	t.Log(err, r.Status().MG)
+}
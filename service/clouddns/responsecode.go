@@ -0,0 +1,75 @@
+package clouddns
+
+import "GodDns/core"
+
+// status of errors answered by Google APIs
+// https://cloud.google.com/apis/design/errors#handling_errors
+const (
+	Success = ""
+
+	InvalidArgument    = "INVALID_ARGUMENT"
+	FailedPrecondition = "FAILED_PRECONDITION"
+	OutOfRange         = "OUT_OF_RANGE"
+	Unauthenticated    = "UNAUTHENTICATED"
+	PermissionDenied   = "PERMISSION_DENIED"
+	NotFound           = "NOT_FOUND"
+	Aborted            = "ABORTED"
+	AlreadyExists      = "ALREADY_EXISTS"
+	ResourceExhausted  = "RESOURCE_EXHAUSTED"
+	Cancelled          = "CANCELLED"
+	Internal           = "INTERNAL"
+	Unavailable        = "UNAVAILABLE"
+	DeadlineExceeded   = "DEADLINE_EXCEEDED"
+)
+
+// status of a change
+const (
+	Pending = "pending"
+	Done    = "done"
+)
+
+// code2status
+// convert the error status to message and set status.Status
+func code2status(code string) *core.Status {
+	msg := newStatus()
+	switch code {
+	case Success:
+		msg.MG.AddInfo("request succeeded")
+	case InvalidArgument:
+		msg.MG.AddError("invalid argument")
+	case FailedPrecondition:
+		msg.MG.AddError("failed precondition, the record may have been changed by others, try again later")
+	case OutOfRange:
+		msg.MG.AddError("out of range")
+	case Unauthenticated:
+		msg.MG.AddError("unauthenticated, check the credentials file")
+	case PermissionDenied:
+		msg.MG.AddError("permission denied, check the roles of the service account")
+	case NotFound:
+		msg.MG.AddError("project or managed zone not found")
+	case Aborted:
+		msg.MG.AddError("aborted by concurrent changes, try again later")
+	case AlreadyExists:
+		msg.MG.AddError("record already exists")
+	case ResourceExhausted:
+		msg.MG.AddError("quota exceeded")
+	case Cancelled:
+		msg.MG.AddError("request cancelled")
+	case Internal:
+		msg.MG.AddError("internal error")
+	case Unavailable:
+		msg.MG.AddError("service unavailable")
+	case DeadlineExceeded:
+		msg.MG.AddError("deadline exceeded")
+	default:
+		msg.MG.AddError("unknown error")
+	}
+
+	if code == Success {
+		msg.Status = core.Success
+	} else {
+		msg.Status = core.Failed
+	}
+
+	return msg
+}
@@ -0,0 +1,93 @@
+package cloudflare
+
+import (
+	"testing"
+
+	"GodDns/core"
+	"gopkg.in/ini.v1"
+)
+
+func TestConfig_GenerateDefaultConfigInfo(t *testing.T) {
+	config, err := Config{}.GenerateDefaultConfigInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(config.Content)
+}
+
+func TestConfig_ReadConfig(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[Cloudflare]
+Token=TOKEN
+Domain=example.com
+Subdomain=www,mail
+ZoneId=zone1
+RecordId=record1
+Value=1.2.3.4
+TTL=1
+Proxied=true
+Type=4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("Cloudflare"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 {
+		t.Fatalf("expect 2 parameters, got %d", len(ps))
+	}
+	for _, p := range ps {
+		p := p.(*Parameters)
+		if p.Type != "A" || !p.Proxied || p.ZoneId != "zone1" {
+			t.Errorf("unexpected parameters %+v", p)
+		}
+		if p.RecordId != "" {
+			t.Error("record id should not be shared by multiple records")
+		}
+		t.Log(p.Target())
+	}
+}
+
+func TestConfig_ReadConfigMerge(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[Cloudflare]
+Token=TOKEN
+Domain=example.com
+Subdomain=www,ftp,mail
+RecordId=www:1,mail:3
+Value=1.2.3.4
+TTL=1
+Type=A
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("Cloudflare"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][2]string{{"www", "1"}, {"ftp", ""}, {"mail", "3"}}
+	if len(ps) != len(expected) {
+		t.Fatalf("expect %d parameters, got %d", len(expected), len(ps))
+	}
+	for i, p := range ps {
+		p := p.(*Parameters)
+		if p.Subdomain != expected[i][0] || p.RecordId != expected[i][1] {
+			t.Errorf("expect %s with record id %q, got %s with %q", expected[i][0], expected[i][1], p.Subdomain, p.RecordId)
+		}
+	}
+
+	// saved back as a single section
+	ps[1].(*Parameters).RecordId = "2"
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("expect 1 merged parameters, got %d", len(merged))
+	}
+	if m := merged[0].(*Parameters); m.Subdomain != "www,ftp,mail" || m.RecordId != "www:1,ftp:2,mail:3" {
+		t.Errorf("unexpected merged parameters %+v", m)
+	}
+}
@@ -0,0 +1,178 @@
+// Package cloudflare use Cloudflare API v4 to update DNS record
+package cloudflare
+
+import (
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util/collections"
+)
+
+const serviceName = "Cloudflare"
+
+// DefaultEndpoint is the base url of Cloudflare API v4
+const DefaultEndpoint = "https://api.cloudflare.com/client/v4"
+
+// Parameters implements DeviceOverridable and Mergeable
+// one Parameters per Subdomain, they are merged back into one section when saving
+// Token is an API token with Zone.DNS edit permission
+// ZoneId and RecordId will be looked up by Domain and Subdomain if not set
+// Device is Device name when overriding ip with specific Device/interface
+type Parameters struct {
+	Token     string `KeyValue:"Token,API token with 'Zone.DNS:Edit' permission, get from https://dash.cloudflare.com/profile/api-tokens"`
+	Domain    string `KeyValue:"Domain,zone name like example.com"`
+	Subdomain string `KeyValue:"Subdomain,record name like www, use @ for the zone apex, if you have multiple records to update, set like Subdomain=www,ftp,mail"`
+	ZoneId    string `KeyValue:"ZoneId,zone id, looked up by Domain if empty"`
+	RecordId  string `KeyValue:"RecordId,record id, looked up by Subdomain and Type if empty or not found, set like RecordId=www:1,ftp:2 for multiple subdomains"`
+	Value     string `KeyValue:"Value,IP address like 6.6.6.6"`
+	TTL       uint64 `KeyValue:"TTL,Time-To-Live, 1 means automatic"`
+	Proxied   bool   `KeyValue:"Proxied,whether the record is proxied by Cloudflare, true or false(default)"`
+	Type      string `KeyValue:"Type,A/AAAA/4/6"`
+	Endpoint  string `KeyValue:"Endpoint,API base url, https://api.cloudflare.com/client/v4(default)"`
+	Device    string `KeyValue:"Device,device/net interface name"`
+	core.DeviceOptions
+}
+
+// GenerateDefaultConfigInfo return Default config
+func GenerateDefaultConfigInfo() Parameters {
+	return Parameters{
+		Token:     "Token",
+		Domain:    "example.com",
+		Subdomain: "www,mail,ftp...",
+		Value:     "1.2.3.4",
+		TTL:       1,
+		Type:      "A/AAAA/4/6",
+		Device:    "your device/net interface name",
+	}
+}
+
+// GetName return "Cloudflare"
+func (p *Parameters) GetName() string {
+	return serviceName
+}
+
+// SaveConfig return core.ConfigStr
+func (p *Parameters) SaveConfig(No uint) (core.ConfigStr, error) {
+	return configInstance.GenerateConfigInfo(p, No)
+}
+
+// Target return the full record name
+func (p *Parameters) Target() string {
+	return p.getTotalDomain()
+}
+
+// ToRequest Convert to core.Request
+func (p *Parameters) ToRequest() (core.Request, error) {
+	r := new(Request)
+	r.Init(*p)
+	return r, nil
+}
+
+// SetValue set ip
+func (p *Parameters) SetValue(value string) {
+	p.Value = value
+}
+
+// GetIP return ip value
+func (p *Parameters) GetIP() string {
+	return p.Value
+}
+
+// GetType return Type like "4" or "6" and "" if invalid type
+func (p *Parameters) GetType() string {
+	return netutil.Type2Num(p.Type)
+}
+
+// IsTypeSet return whether the type is set correctly, "4" and "6" are accepted as "A" and "AAAA"
+func (p *Parameters) IsTypeSet() bool {
+	return netutil.Type2Str(p.Type) != ""
+}
+
+// GetDevice return Device name
+func (p *Parameters) GetDevice() string {
+	return p.Device
+}
+
+// IsDeviceSet return whether the Device is set
+func (p *Parameters) IsDeviceSet() bool {
+	return p.Device != ""
+}
+
+// getTotalDomain return subdomain+domain, "@" stands for the zone apex
+func (p *Parameters) getTotalDomain() string {
+	if p.Subdomain == "@" || p.Subdomain == "" {
+		return p.Domain
+	}
+	return p.Subdomain + "." + p.Domain
+}
+
+// getEndpoint return Endpoint or DefaultEndpoint if not set
+func (p *Parameters) getEndpoint() string {
+	if p.Endpoint == "" {
+		return DefaultEndpoint
+	}
+	return p.Endpoint
+}
+
+// getTTL return TTL, 1(automatic) if not set
+func (p *Parameters) getTTL() uint64 {
+	if p.TTL == 0 {
+		return 1
+	}
+	return p.TTL
+}
+
+// MergeableWith return true if the two Parameters differ only by Subdomain and RecordId
+func (p *Parameters) MergeableWith(other core.Parameters) bool {
+	o, ok := other.(*Parameters)
+	if !ok {
+		return false
+	}
+	return p.Token == o.Token && p.Domain == o.Domain && p.ZoneId == o.ZoneId && p.Value == o.Value &&
+		p.TTL == o.TTL && p.Proxied == o.Proxied && p.Type == o.Type && p.Endpoint == o.Endpoint &&
+		p.Device == o.Device && p.DeviceOptions == o.DeviceOptions
+}
+
+// Merge return a new Parameters whose Subdomain is like "www,ftp" and RecordId is like "www:1,ftp:2"
+func (p *Parameters) Merge(others ...core.Parameters) core.Parameters {
+	merged := *p
+	all := append([]core.Parameters{p}, others...)
+	var subdomains []string
+	for _, other := range all {
+		subdomains = collections.AppendUnique(subdomains, other.(*Parameters).Subdomain)
+	}
+
+	recordIds := make([]string, 0, len(all))
+	for _, other := range all {
+		o := other.(*Parameters)
+		if o.RecordId != "" {
+			recordIds = append(recordIds, o.Subdomain+recordIdSep+o.RecordId)
+		}
+	}
+	merged.Subdomain = strings.Join(subdomains, ",")
+	merged.RecordId = strings.Join(recordIds, ",")
+	return &merged
+}
+
+// recordIdSep separates subdomain and record id in RecordId like "www:1,ftp:2"
+const recordIdSep = ":"
+
+// parseRecordIds parse RecordId like "www:1,ftp:2" to map of subdomain to record id,
+// a plain record id like "1" is only meaningful when there is exactly one subdomain
+func parseRecordIds(recordId string, subdomains []string) map[string]string {
+	ids := make(map[string]string, len(subdomains))
+	if !strings.Contains(recordId, recordIdSep) {
+		if recordId != "" && len(subdomains) == 1 {
+			ids[subdomains[0]] = recordId
+		}
+		return ids
+	}
+	for _, pair := range strings.Split(recordId, ",") {
+		subdomain, id, _ := strings.Cut(strings.TrimSpace(pair), recordIdSep)
+		if id != "" {
+			ids[subdomain] = id
+		}
+	}
+	return ids
+}
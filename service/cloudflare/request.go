@@ -0,0 +1,267 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	"GodDns/netutil"
+	"github.com/go-resty/resty/v2"
+)
+
+const requestTimeout = 20 * time.Second
+
+// usage
+// r:=cloudflare.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request and core.ThroughProxy
+type Request struct {
+	parameters Parameters
+	status     core.Status
+}
+
+// Init set parameter
+func (r *Request) Init(parameters Parameters) {
+	r.parameters = parameters
+	// type is sent as "A" or "AAAA" even if it is set like "4" or "6"
+	r.parameters.Type = netutil.Type2Str(parameters.Type)
+}
+
+// Target return target domain
+func (r *Request) Target() string {
+	return r.parameters.getTotalDomain()
+}
+
+// GetName return "Cloudflare"
+func (r *Request) GetName() string {
+	return serviceName
+}
+
+// ToParameters return core.Service
+func (r *Request) ToParameters() core.Service {
+	return &r.parameters
+}
+
+// Status return core.Status which contains execution result etc.
+func (r *Request) Status() core.Status {
+	return r.status
+}
+
+func newStatus() *core.Status {
+	return &core.Status{
+		Name:   serviceName,
+		Status: core.NotExecute,
+		MG:     core.NewDefaultMsgGroup(),
+	}
+}
+
+// MakeRequest 1.GetZoneId 2.GetRecordId if not set 3.update the record, GetRecordId again if the record is not found
+func (r *Request) MakeRequest() error {
+	client := core.MainClientPool.Get().(*resty.Client)
+	defer core.MainClientPool.Put(client)
+	return r.execute(client)
+}
+
+// RequestThroughProxy make request through the proxies in netutil.GlobalProxies one by one until one succeeds
+func (r *Request) RequestThroughProxy() error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	iter := netutil.GlobalProxies.GetProxyIter()
+	if iter.Len() == 0 {
+		return r.fail(errors.New("no proxy available"))
+	}
+
+	var err error
+	for iter.NotLast() {
+		proxy := iter.Next()
+		// never set proxy on a pooled client
+		client := core.MainClientPool.New().(*resty.Client).SetProxy(proxy)
+		err = r.execute(client)
+		if err == nil {
+			return nil
+		}
+		errMsg := fmt.Sprintf("request error through proxy %s: %v", proxy, err)
+		r.status.MG.AddError(errMsg)
+		log.Errorf(errMsg)
+	}
+	return err
+}
+
+func (r *Request) execute(client *resty.Client) error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if r.parameters.ZoneId == "" {
+		zoneId, err := r.GetZoneId(ctx, client)
+		if err != nil {
+			return r.fail(fmt.Errorf("error getting zone id of %s: %w", r.parameters.Domain, err))
+		}
+		r.parameters.ZoneId = zoneId
+	}
+
+	// the configured record id is used, it is looked up again only if the record is not found
+	lookedUp := false
+	if r.parameters.RecordId == "" {
+		if err := r.lookupRecordId(ctx, client); err != nil {
+			return r.fail(err)
+		}
+		lookedUp = true
+	}
+
+	res, notFound, err := r.update(ctx, client)
+	if err == nil && notFound && !lookedUp {
+		log.Debugf("record %s of %s not found, look up again", r.parameters.RecordId, r.Target())
+		if err := r.lookupRecordId(ctx, client); err != nil {
+			return r.fail(err)
+		}
+		res, _, err = r.update(ctx, client)
+	}
+	if err != nil {
+		return r.fail(err)
+	}
+	if !res.Success {
+		return r.fail(res.err())
+	}
+
+	r.status.Status = core.Success
+	r.status.MG.AddInfo(fmt.Sprintf("record updated at %s %s %s", res.Result.ModifiedOn, r.Target(), res.Result.Content))
+	return nil
+}
+
+// lookupRecordId look up the record id and cache it in parameters
+func (r *Request) lookupRecordId(ctx context.Context, client *resty.Client) error {
+	recordId, err := r.GetRecordId(ctx, client)
+	if err != nil {
+		return fmt.Errorf("error getting record id of %s: %w", r.Target(), err)
+	}
+	r.parameters.RecordId = recordId
+	return nil
+}
+
+// update put the record by RecordId, notFound is true if the record does not exist
+func (r *Request) update(ctx context.Context, client *resty.Client) (res *response[dnsRecord], notFound bool, err error) {
+	res = &response[dnsRecord]{}
+	URL := fmt.Sprintf("%s/zones/%s/dns_records/%s", r.parameters.getEndpoint(), r.parameters.ZoneId, r.parameters.RecordId)
+	resp, err := r.newRequest(ctx, client).
+		SetBody(dnsRecord{
+			Type:    r.parameters.Type,
+			Name:    r.Target(),
+			Content: r.parameters.Value,
+			TTL:     r.parameters.getTTL(),
+			Proxied: r.parameters.Proxied,
+		}).
+		SetResult(res).
+		SetError(res).
+		Put(URL)
+	if err != nil {
+		return nil, false, err
+	}
+	log.Debugf("result:%s", resp.String())
+	return res, resp.StatusCode() == http.StatusNotFound, nil
+}
+
+// GetZoneId look up the zone id by Domain
+func (r *Request) GetZoneId(ctx context.Context, client *resty.Client) (string, error) {
+	res := &response[[]zone]{}
+	_, err := r.newRequest(ctx, client).
+		SetQueryParam("name", r.parameters.Domain).
+		SetResult(res).
+		SetError(res).
+		Get(r.parameters.getEndpoint() + "/zones")
+	if err != nil {
+		return "", err
+	}
+	if !res.Success {
+		return "", res.err()
+	}
+	if len(res.Result) == 0 {
+		return "", errors.New("no zone found")
+	}
+	return res.Result[0].Id, nil
+}
+
+// GetRecordId look up the record id by Subdomain and Type
+func (r *Request) GetRecordId(ctx context.Context, client *resty.Client) (string, error) {
+	res := &response[[]dnsRecord]{}
+	_, err := r.newRequest(ctx, client).
+		SetQueryParams(map[string]string{
+			"type": r.parameters.Type,
+			"name": r.Target(),
+		}).
+		SetResult(res).
+		SetError(res).
+		Get(fmt.Sprintf("%s/zones/%s/dns_records", r.parameters.getEndpoint(), r.parameters.ZoneId))
+	if err != nil {
+		return "", err
+	}
+	if !res.Success {
+		return "", res.err()
+	}
+	if len(res.Result) == 0 {
+		return "", errors.New("no record found")
+	}
+	return res.Result[0].Id, nil
+}
+
+func (r *Request) newRequest(ctx context.Context, client *resty.Client) *resty.Request {
+	return client.R().
+		SetContext(ctx).
+		SetAuthToken(r.parameters.Token).
+		SetHeader("Content-Type", "application/json")
+}
+
+// fail set status to Failed with err and return err
+func (r *Request) fail(err error) error {
+	r.status.Status = core.Failed
+	r.status.MG.AddError(err.Error())
+	return err
+}
+
+type response[T any] struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result T `json:"result"`
+}
+
+// err join all errors in response
+func (r *response[T]) err() error {
+	if len(r.Errors) == 0 {
+		return errors.New("unknown error")
+	}
+	msg := make([]string, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		msg = append(msg, fmt.Sprintf("%d: %s", e.Code, e.Message))
+	}
+	return errors.New(strings.Join(msg, "; "))
+}
+
+type zone struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type dnsRecord struct {
+	Id         string `json:"id,omitempty"`
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Content    string `json:"content"`
+	TTL        uint64 `json:"ttl"`
+	Proxied    bool   `json:"proxied"`
+	ModifiedOn string `json:"modified_on,omitempty"`
+}
@@ -0,0 +1,200 @@
+package cloudflare
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"GodDns/core"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return newCountingTestServer(t, new(atomic.Int32))
+}
+
+// newCountingTestServer count the requests looking up record id in lookups
+func newCountingTestServer(t *testing.T, lookups *atomic.Int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/zones", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") != "example.com" {
+			_, _ = io.WriteString(w, `{"success":true,"errors":[],"result":[]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"success":true,"errors":[],"result":[{"id":"zone1","name":"example.com"}]}`)
+	})
+	mux.HandleFunc("/zones/zone1/dns_records", func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		if r.URL.Query().Get("name") != "www.example.com" || r.URL.Query().Get("type") != "A" {
+			_, _ = io.WriteString(w, `{"success":true,"errors":[],"result":[]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"success":true,"errors":[],"result":[{"id":"record1","type":"A","name":"www.example.com","content":"1.1.1.1","ttl":1}]}`)
+	})
+	mux.HandleFunc("/zones/zone1/dns_records/record1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		record := dnsRecord{}
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			t.Error(err)
+		}
+		record.Id = "record1"
+		record.ModifiedOn = "2023-04-01T00:00:00Z"
+		res, _ := json.Marshal(map[string]any{"success": true, "errors": []any{}, "result": record})
+		_, _ = w.Write(res)
+	})
+
+	mux.HandleFunc("/zones/zone1/dns_records/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"success":false,"errors":[{"code":81044,"message":"Record does not exist."}],"result":null}`)
+	})
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer TOKEN" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"success":false,"errors":[{"code":9109,"message":"Invalid access token"}],"result":null}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "www",
+		Value:     "2.2.2.2",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	err := r.MakeRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d: %v", r.Status().Status, r.Status().MG.GetMsgOf(core.Error))
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Info))
+
+	updated := r.ToParameters().(*Parameters)
+	if updated.ZoneId != "zone1" || updated.RecordId != "record1" {
+		t.Errorf("zone id and record id should be cached, got %s %s", updated.ZoneId, updated.RecordId)
+	}
+}
+
+func TestRequest_MakeRequestWithNumericType(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "www",
+		Value:     "2.2.2.2",
+		Type:      "4",
+		Endpoint:  server.URL,
+	}
+	if !p.IsTypeSet() {
+		t.Errorf("type %s should be accepted as A", p.Type)
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d: %v", r.Status().Status, r.Status().MG.GetMsgOf(core.Error))
+	}
+}
+
+func TestRequest_MakeRequestWithBadToken(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "BAD TOKEN",
+		Domain:    "example.com",
+		Subdomain: "www",
+		Value:     "2.2.2.2",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	err := r.MakeRequest()
+	if err == nil {
+		t.Fatal("should return error")
+	}
+	t.Log(err)
+
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+}
+
+func TestRequest_MakeRequestWithoutRecord(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "@",
+		Value:     "2.2.2.2",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	err := r.MakeRequest()
+	if err == nil {
+		t.Fatal("should return error")
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Error))
+}
+
+func TestRequest_MakeRequestWithRecordId(t *testing.T) {
+	tests := []struct {
+		name     string
+		recordId string
+		lookups  int32
+	}{
+		{"configured", "record1", 0},
+		{"stale", "deleted", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookups := new(atomic.Int32)
+			server := newCountingTestServer(t, lookups)
+			defer server.Close()
+
+			p := Parameters{
+				Token:     "TOKEN",
+				Domain:    "example.com",
+				Subdomain: "www",
+				ZoneId:    "zone1",
+				RecordId:  tt.recordId,
+				Value:     "2.2.2.2",
+				Type:      "A",
+				Endpoint:  server.URL,
+			}
+			r, _ := p.ToRequest()
+			if err := r.MakeRequest(); err != nil {
+				t.Fatal(err)
+			}
+			if n := lookups.Load(); n != tt.lookups {
+				t.Errorf("expect record id looked up %d times, got %d", tt.lookups, n)
+			}
+			if id := r.ToParameters().(*Parameters).RecordId; id != "record1" {
+				t.Errorf("expect record id record1, got %s", id)
+			}
+		})
+	}
+}
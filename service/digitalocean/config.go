@@ -0,0 +1,16 @@
+package digitalocean
+
+import (
+	"GodDns/core"
+	"GodDns/service/internal/restrecord"
+)
+
+func init() {
+	core.Add2FactoryList(ConfigFactory{})
+}
+
+// ConfigFactory is a factory that create a new Config
+type ConfigFactory = restrecord.ConfigFactory[spec]
+
+// Config implements core.Config
+type Config = restrecord.Config[spec]
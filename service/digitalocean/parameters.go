@@ -0,0 +1,32 @@
+// Package digitalocean use DigitalOcean API v2 to update DNS record
+package digitalocean
+
+import (
+	"GodDns/service/internal/restrecord"
+)
+
+const serviceName = "DigitalOcean"
+
+// DefaultEndpoint is the base url of DigitalOcean API v2
+const DefaultEndpoint = "https://api.digitalocean.com/v2"
+
+// Parameters implements DeviceOverridable, Filterable and SuffixComposable
+type Parameters = restrecord.Parameters[spec]
+
+// spec implements restrecord.Spec, the zone apex is "@" in the API
+type spec struct{}
+
+func (spec) Info() restrecord.Info {
+	return restrecord.Info{
+		Name:            serviceName,
+		DefaultEndpoint: DefaultEndpoint,
+		Apex:            "@",
+		DefaultTTL:      1800,
+		TokenComment:    "personal access token with write scope, get from https://cloud.digitalocean.com/account/api/tokens",
+		TTLComment:      "Time-To-Live, 1800(default), at least 30",
+	}
+}
+
+func (spec) NewProvider(s restrecord.Settings) restrecord.Provider {
+	return provider{s}
+}
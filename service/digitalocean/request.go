@@ -0,0 +1,113 @@
+package digitalocean
+
+import (
+	"net/http"
+	"strconv"
+
+	"GodDns/service/internal/restrecord"
+	json "GodDns/util/json"
+)
+
+// usage
+// r:=digitalocean.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request and core.ThroughProxy
+type Request = restrecord.Request[spec]
+
+// provider implements restrecord.Provider
+// domains are identified by name, records are listed with filters of fqdn and type
+type provider struct {
+	restrecord.Settings
+}
+
+func (p provider) Auth() restrecord.TokenAuth {
+	return restrecord.TokenAuth{Token: p.Token}
+}
+
+func (p provider) ZoneId(*restrecord.Client) (string, error) {
+	return p.Domain, nil
+}
+
+func (p provider) GetRecord(c *restrecord.Client, zoneId string, id string) (restrecord.Record, error) {
+	res := &domainRecordResponse{}
+	if err := c.Do(c.R(), http.MethodGet, p.Endpoint+"/domains/"+zoneId+"/records/"+id, res); err != nil {
+		return restrecord.Record{}, err
+	}
+	return res.DomainRecord.toRecord(), nil
+}
+
+func (p provider) ListRecords(c *restrecord.Client, zoneId string, page int) ([]restrecord.Record, bool, error) {
+	res := &domainRecordsResponse{}
+	req := c.R().SetQueryParams(map[string]string{
+		"type":     p.Type,
+		"name":     p.Target,
+		"page":     strconv.Itoa(page),
+		"per_page": strconv.Itoa(restrecord.PerPage),
+	})
+	if err := c.Do(req, http.MethodGet, p.Endpoint+"/domains/"+zoneId+"/records", res); err != nil {
+		return nil, false, err
+	}
+
+	records := make([]restrecord.Record, 0, len(res.DomainRecords))
+	for _, record := range res.DomainRecords {
+		records = append(records, record.toRecord())
+	}
+	return records, res.Links.Pages.Next != "", nil
+}
+
+func (p provider) UpdateRecord(c *restrecord.Client, zoneId string, record restrecord.Record) error {
+	req := c.R().SetBody(domainRecord{
+		Type: record.Type,
+		Name: record.Name,
+		Data: record.Value,
+		TTL:  record.TTL,
+	})
+	return c.Do(req, http.MethodPut, p.Endpoint+"/domains/"+zoneId+"/records/"+record.Id, nil)
+}
+
+func (p provider) ErrorMessage(body []byte) string {
+	res := &errorResponse{}
+	if err := json.Unmarshal(body, res); err != nil || res.Message == "" {
+		return ""
+	}
+	return res.Id + ": " + res.Message
+}
+
+type domainRecord struct {
+	Id   int64  `json:"id,omitempty"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Data string `json:"data"`
+	TTL  uint64 `json:"ttl,omitempty"`
+}
+
+func (d domainRecord) toRecord() restrecord.Record {
+	return restrecord.Record{
+		Id:    strconv.FormatInt(d.Id, 10),
+		Name:  d.Name,
+		Type:  d.Type,
+		Value: d.Data,
+		TTL:   d.TTL,
+	}
+}
+
+type domainRecordResponse struct {
+	DomainRecord domainRecord `json:"domain_record"`
+}
+
+type domainRecordsResponse struct {
+	DomainRecords []domainRecord `json:"domain_records"`
+	Links         struct {
+		Pages struct {
+			Next string `json:"next"`
+		} `json:"pages"`
+	} `json:"links"`
+}
+
+// errorResponse is like {"id":"not_found","message":"The resource you were accessing could not be found."}
+type errorResponse struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,178 @@
+package digitalocean
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"GodDns/core"
+)
+
+// fakeDigitalOcean serve records of domain example.com
+type fakeDigitalOcean struct {
+	t       *testing.T
+	mu      sync.Mutex
+	records []domainRecord
+	lists   int
+	gets    int
+	puts    int
+}
+
+func (f *fakeDigitalOcean) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("Authorization") != "Bearer TOKEN" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"id":"unauthorized","message":"Unable to authenticate you"}`)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/domains/example.com/records":
+		f.lists++
+		res := domainRecordsResponse{DomainRecords: []domainRecord{}}
+		for _, record := range f.records {
+			fqdn := record.Name + ".example.com"
+			if record.Name == "@" {
+				fqdn = "example.com"
+			}
+			if fqdn == r.URL.Query().Get("name") && record.Type == r.URL.Query().Get("type") {
+				res.DomainRecords = append(res.DomainRecords, record)
+			}
+		}
+		data, _ := json.Marshal(res)
+		_, _ = w.Write(data)
+	case r.Method == http.MethodGet && r.URL.Path == "/domains/example.com/records/2":
+		f.gets++
+		data, _ := json.Marshal(domainRecordResponse{DomainRecord: f.records[1]})
+		_, _ = w.Write(data)
+	case r.Method == http.MethodPut && r.URL.Path == "/domains/example.com/records/2":
+		f.puts++
+		record := domainRecord{}
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			f.t.Error(err)
+		}
+		record.Id = 2
+		f.records[1] = record
+		data, _ := json.Marshal(map[string]any{"domain_record": record})
+		_, _ = w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"id":"not_found","message":"The resource you were accessing could not be found."}`)
+	}
+}
+
+func newTestServer(t *testing.T) (*fakeDigitalOcean, *httptest.Server) {
+	f := &fakeDigitalOcean{t: t, records: []domainRecord{
+		{Id: 1, Type: "A", Name: "@", Data: "1.1.1.1", TTL: 1800},
+		{Id: 2, Type: "A", Name: "www", Data: "1.1.1.1", TTL: 1800},
+		{Id: 3, Type: "AAAA", Name: "www", Data: "::1", TTL: 1800},
+	}}
+	return f, httptest.NewServer(f)
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	f, server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "www",
+		Value:     "2.2.2.2",
+		TTL:       1800,
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d: %v", r.Status().Status, r.Status().MG.GetMsgOf(core.Error))
+	}
+	if f.records[1].Data != "2.2.2.2" || f.records[1].Name != "www" {
+		t.Errorf("unexpected record %+v", f.records[1])
+	}
+
+	// record id is cached in parameters, and kept in State with GetRecordId
+	p = *r.ToParameters().(*Parameters)
+	if p.GetRecordId() != "example.com/2" {
+		t.Errorf("unexpected record id %s", p.GetRecordId())
+	}
+	p.Value = "3.3.3.3"
+	r, _ = p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if f.lists != 1 || f.gets != 1 || f.puts != 2 || f.records[1].Data != "3.3.3.3" {
+		t.Errorf("record id should be cached, got %d list, %d get and %d put requests", f.lists, f.gets, f.puts)
+	}
+}
+
+func TestRequest_MakeRequestUnchanged(t *testing.T) {
+	f, server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "@",
+		Value:     "1.1.1.1",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Unchanged || f.puts != 0 {
+		t.Errorf("status should be unchanged, got %d", r.Status().Status)
+	}
+}
+
+func TestRequest_MakeRequestWithBadToken(t *testing.T) {
+	_, server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "BAD TOKEN",
+		Domain:    "example.com",
+		Subdomain: "www",
+		Value:     "2.2.2.2",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	err := r.MakeRequest()
+	if err == nil {
+		t.Fatal("should return error")
+	}
+	t.Log(err)
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+}
+
+func TestRequest_MakeRequestWithoutRecord(t *testing.T) {
+	_, server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "ftp",
+		Value:     "2.2.2.2",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err == nil {
+		t.Fatal("should return error")
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Error))
+}
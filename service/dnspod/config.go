@@ -2,6 +2,8 @@ package dnspod
 
 import (
 	"bytes"
+	"fmt"
+	"strconv"
 	"strings"
 
 	"GodDns/core"
@@ -35,13 +37,13 @@ func (c Config) GenerateDefaultConfigInfo() (core.ConfigStr, error) {
 // Return: DDNS.Parameters and error
 // if any error occurs, returned Parameters will be nil
 func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
-	names := [11]string{
+	names := [10]string{
 		"LoginToken", "Format", "Lang", "ErrorOnEmpty", "Domain",
-		"RecordId", "RecordLine", "Value", "TTL", "Type", "Subdomain",
+		"RecordLine", "Value", "TTL", "Type", "Subdomain",
 	}
 
 	p := Parameters{}
-	var subdomains []string
+	var subdomains, types []string
 	for _, name := range names {
 		if !sec.HasKey(name) {
 			return nil, core.NewMissKeyErr(name, serviceName)
@@ -49,8 +51,8 @@ func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
 			switch name {
 			case "Subdomain":
 				subdomain := sec.Key(name).String()
-				subdomains = strings.Fields(strings.ReplaceAll(subdomain, ",", " "))
-				collections.RemoveDuplicate(&subdomains)
+				// keep the order of subdomains so that the section is saved back as it is
+				subdomains = collections.AppendUnique(nil, strings.Fields(strings.ReplaceAll(subdomain, ",", " "))...)
 			case "TTL":
 				ttl, err := sec.Key(name).Uint64()
 				if err != nil {
@@ -58,7 +60,8 @@ func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
 				}
 				p.TTL = uint16(ttl)
 			case "Type":
-				p.Type = netutil.Type2Str(sec.Key(name).String())
+				// Type=A,AAAA or Type=both for a dual-stack section
+				types = netutil.ParseTypes(sec.Key(name).String())
 			default:
 				err := util.SetVariable(&p, name, sec.Key(name).String())
 				if err != nil {
@@ -68,28 +71,59 @@ func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
 		}
 	}
 
+	if p.Format != FormatJSON && p.Format != FormatXML {
+		return nil, fmt.Errorf("unsupported format %s, should be json or xml", p.Format)
+	}
+
 	// empty if not exist
 	p.Device = sec.Key("Device").String()
-
-	ps := make([]core.Parameters, 0, len(subdomains))
+	options, err := core.ReadDeviceOptions(sec)
+	if err != nil {
+		return nil, err
+	}
+	p.DeviceOptions = options
+	// records are selected by RecordLine, RecordLineId, Weight and Remark, empty ones are ignored
+	p.RecordLineId = sec.Key("RecordLineId").String()
+	p.Remark = sec.Key("Remark").String()
+	if p.Weight = sec.Key("Weight").String(); p.Weight != "" {
+		if w, err := strconv.Atoi(p.Weight); err != nil || w < 0 || w > 100 {
+			return nil, fmt.Errorf("invalid weight %s, should be 0-100", p.Weight)
+		}
+	}
+	// false if not exist
+	p.CreateIfMissing = sec.Key("CreateIfMissing").MustBool(false)
+	// record id of each subdomain and type, looked up if not exist
+	recordIds := parseRecordIds(sec.Key("RecordId").String(), subdomains, types)
+	// one value per type like Value=1.2.3.4,2001:db8::1
+	values := netutil.ValuesOfTypes(p.Value, types)
+
+	ps := make([]core.Parameters, 0, len(subdomains)*len(types))
 	for _, subdomain := range subdomains {
 		if subdomain == "" {
 			continue
 		}
-		ps = append(ps, &Parameters{
-			LoginToken:   p.LoginToken,
-			Format:       p.Format,
-			Lang:         p.Lang,
-			ErrorOnEmpty: p.ErrorOnEmpty,
-			Domain:       p.Domain,
-			RecordId:     p.RecordId,
-			Subdomain:    subdomain,
-			RecordLine:   p.RecordLine,
-			Value:        p.Value,
-			TTL:          p.TTL,
-			Type:         p.Type,
-			Device:       p.Device,
-		})
+		for _, t := range types {
+			ps = append(ps, &Parameters{
+				LoginToken:    p.LoginToken,
+				Format:        p.Format,
+				Lang:          p.Lang,
+				ErrorOnEmpty:  p.ErrorOnEmpty,
+				Domain:        p.Domain,
+				RecordId:      recordIds[subdomain+recordIdSep+t],
+				Subdomain:     subdomain,
+				RecordLine:    p.RecordLine,
+				RecordLineId:  p.RecordLineId,
+				Weight:        p.Weight,
+				Remark:        p.Remark,
+				Value:         values[t],
+				TTL:           p.TTL,
+				Type:          t,
+				Device:        p.Device,
+				DeviceOptions: p.DeviceOptions,
+
+				CreateIfMissing: p.CreateIfMissing,
+			})
+		}
 	}
 	return ps, nil
 }
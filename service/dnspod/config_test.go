@@ -64,3 +64,163 @@ func TestSplit(t *testing.T) {
 		}
 	}
 }
+
+func TestConfig_ReadConfigMultipleSubdomains(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[Dnspod]
+LoginToken=TOKEN
+Format=json
+Lang=en
+ErrorOnEmpty=no
+Domain=example.com
+RecordId=www:1,ftp:2
+Subdomain=www,ftp,mail
+RecordLine=默认
+Value=1.2.3.4
+TTL=600
+Type=A
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("Dnspod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 3 {
+		t.Fatalf("expect one Parameters per subdomain, got %d", len(ps))
+	}
+	expected := map[string]string{"www": "1", "ftp": "2", "mail": ""}
+	for _, p := range ps {
+		p := p.(*Parameters)
+		if p.RecordId != expected[p.Subdomain] {
+			t.Errorf("record id of %s should be %q, got %q", p.Subdomain, expected[p.Subdomain], p.RecordId)
+		}
+	}
+
+	// round trip through MergeParameters and SaveConfig
+	ps[2].(*Parameters).RecordId = "3"
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("Parameters differ only by subdomain should be merged, got %d", len(merged))
+	}
+	info, err := merged[0].SaveConfig(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(info.Content)
+
+	cfg, err = ini.Load([]byte(info.Content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err = Config{}.ReadConfig(*cfg.Section("Dnspod#1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected["mail"] = "3"
+	if len(ps) != 3 {
+		t.Fatalf("expect 3 Parameters after round trip, got %d", len(ps))
+	}
+	for _, p := range ps {
+		p := p.(*Parameters)
+		if p.RecordId != expected[p.Subdomain] {
+			t.Errorf("record id of %s should be %q after round trip, got %q", p.Subdomain, expected[p.Subdomain], p.RecordId)
+		}
+	}
+}
+
+func TestConfig_ReadConfigDualStack(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[Dnspod]
+LoginToken=TOKEN
+Format=json
+Lang=en
+ErrorOnEmpty=no
+Domain=example.com
+RecordId=www:A:1,www:AAAA:2
+Subdomain=www,ftp
+RecordLine=默认
+Value=2001:db8::1,1.2.3.4
+TTL=600
+Type=both
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("Dnspod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 4 {
+		t.Fatalf("expect one Parameters per subdomain and type, got %d", len(ps))
+	}
+	expectedIds := map[string]string{"www:A": "1", "www:AAAA": "2", "ftp:A": "", "ftp:AAAA": ""}
+	expectedValues := map[string]string{"A": "1.2.3.4", "AAAA": "2001:db8::1"}
+	for _, p := range ps {
+		p := p.(*Parameters)
+		if id := expectedIds[p.Subdomain+recordIdSep+p.Type]; p.RecordId != id {
+			t.Errorf("record id of %s %s should be %q, got %q", p.Subdomain, p.Type, id, p.RecordId)
+		}
+		if p.Value != expectedValues[p.Type] {
+			t.Errorf("value of %s %s should be %s, got %s", p.Subdomain, p.Type, expectedValues[p.Type], p.Value)
+		}
+	}
+
+	// saved back as a single section
+	ps[3].(*Parameters).RecordId = "4"
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("Parameters of a dual-stack section should be merged, got %d", len(merged))
+	}
+	m := merged[0].(*Parameters)
+	if m.Subdomain != "www,ftp" || m.Type != "A,AAAA" || m.Value != "1.2.3.4,2001:db8::1" || m.RecordId != "www:A:1,www:AAAA:2,ftp:AAAA:4" {
+		t.Errorf("unexpected merged Parameters %+v", m)
+	}
+}
+
+func TestMergeParameters(t *testing.T) {
+	a := p
+	b := p
+	b.Subdomain, b.RecordId = "s2", "3"
+	c := p
+	c.Subdomain, c.Value = "s3", "fe80::2"
+
+	merged := core.MergeParameters(&a, &b, &c)
+	if len(merged) != 2 {
+		t.Fatalf("expect 2 Parameters, got %d", len(merged))
+	}
+	m := merged[0].(*Parameters)
+	if m.Subdomain != "s1,s2" || m.RecordId != "s1:2,s2:3" {
+		t.Errorf("unexpected merged Parameters %+v", m)
+	}
+	if a.Subdomain != "s1" || a.RecordId != "2" {
+		t.Error("merging should not modify the original Parameters")
+	}
+	if merged[1] != &c {
+		t.Error("Parameters with different value should not be merged")
+	}
+}
+
+func TestMergeParametersConflictingValues(t *testing.T) {
+	// both AAAA can be merged with the A, but not with each other
+	a := p
+	a.Type, a.Value = "A", "1.2.3.4"
+	b := p
+	b.Subdomain, b.Value = "s2", "2001:db8::1"
+	c := p
+	c.Subdomain, c.Value = "s3", "2001:db8::2"
+
+	merged := core.MergeParameters(&a, &b, &c)
+	if len(merged) != 2 {
+		t.Fatalf("expect 2 Parameters, got %d", len(merged))
+	}
+	if m := merged[0].(*Parameters); m.Subdomain != "s1,s2" || m.Value != "1.2.3.4,2001:db8::1" {
+		t.Errorf("unexpected merged Parameters %+v", m)
+	}
+	if merged[1] != &c {
+		t.Error("Parameters with different value of the same type should not be merged")
+	}
+}
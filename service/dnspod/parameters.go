@@ -3,29 +3,45 @@ package dnspod
 import "C"
 
 import (
+	"strings"
+
 	"GodDns/core"
 	"GodDns/netutil"
+	"GodDns/util/collections"
 )
 
 const serviceName = "Dnspod"
 
+// data formats of response
+const (
+	FormatJSON = "json"
+	FormatXML  = "xml"
+)
+
 // Parameters implements DeviceOverridable
 // PublicParameter is public parameter of dnspod
 // ExternalParameter is external parameter of dnspod ddns
 // Device is Device name when overriding ip with specific Device/interface
 type Parameters struct {
 	LoginToken   string `json:"login_token,omitempty" xwwwformurlencoded:"login_token" KeyValue:"LoginToken,get from https://console.dnspod.cn/account/token/token, 'ID,Token'"`
-	Format       string `json:"format,omitempty" xwwwformurlencoded:"format" KeyValue:"Format,data format, json(recommended) or xml(not support yet)"`
+	Format       string `json:"format,omitempty" xwwwformurlencoded:"format" KeyValue:"Format,data format, json(recommended) or xml"`
 	Lang         string `json:"lang,omitempty" xwwwformurlencoded:"lang" KeyValue:"Lang,language, en or zh(recommended)"`
 	ErrorOnEmpty string `json:"error_on_empty,omitempty" xwwwformurlencoded:"ErrorOnEmpty" KeyValue:"ErrorOnEmpty,return error if the data doesn't exist,no(recommended) or yes"`
 	Domain       string `json:"domain,omitempty" xwwwformurlencoded:"domain" KeyValue:"Domain,domain name"`
-	RecordId     string `json:"record_id,omitempty" xwwwformurlencoded:"record_id" KeyValue:"RecordId,record id can be get by making http POST request with required Parameters to https://dnsapi.cn/Record.List, more at https://docs.dnspod.com/api/get-record-list/"`
-	Subdomain    string `json:"sub_domain,omitempty" xwwwformurlencoded:"sub_domain" KeyValue:"Subdomain,record name like www., if you have multiple records to update, set like sub_domain=www,ftp,mail"`
+	RecordId     string `json:"record_id,omitempty" xwwwformurlencoded:"record_id" KeyValue:"RecordId,record id can be get by making http POST request with required Parameters to https://dnsapi.cn/Record.List, more at https://docs.dnspod.com/api/get-record-list/, looked up if empty, set like RecordId=www:1,ftp:2 for multiple subdomains, or RecordId=www:A:1,www:AAAA:2 for multiple types"`
+	Subdomain    string `json:"sub_domain,omitempty" xwwwformurlencoded:"sub_domain" KeyValue:"Subdomain,record name like www., if you have multiple records to update, set like Subdomain=www,ftp,mail"`
 	RecordLine   string `json:"record_line,omitempty" xwwwformurlencoded:"record_line" KeyValue:"RecordLine,The record line.You can get the list from the API.The default value is '默认'"`
-	Value        string `json:"value,omitempty" xwwwformurlencoded:"value" KeyValue:"Value,IP address like 6.6.6.6"`
+	RecordLineId string `json:"record_line_id,omitempty" xwwwformurlencoded:"record_line_id" KeyValue:"RecordLineId,id of the record line like 10=1, preferred to RecordLine if set, optional"`
+	Weight       string `json:"weight,omitempty" xwwwformurlencoded:"weight" KeyValue:"Weight,weight of the record 0-100, select the record with the weight, optional"`
+	Remark       string `json:"remark,omitempty" xwwwformurlencoded:"remark" KeyValue:"Remark,remark of the record, select the record with the remark, optional"`
+	Value        string `json:"value,omitempty" xwwwformurlencoded:"value" KeyValue:"Value,IP address like 6.6.6.6, set like Value=6.6.6.6,2001:db8::1 for both A and AAAA"`
 	TTL          uint16 `json:"ttl,omitempty" xwwwformurlencoded:"ttl" KeyValue:"TTL,Time-To-Live, 600(default)"`
-	Type         string `json:"type,omitempty" xwwwformurlencoded:"type" KeyValue:"Type,A/AAAA/4/6"`
+	Type         string `json:"type,omitempty" xwwwformurlencoded:"type" KeyValue:"Type,A/AAAA/4/6, set like Type=A,AAAA or Type=both to update both"`
 	Device       string `json:"-" xwwwformurlencoded:"-" KeyValue:"Device,device/net interface name"`
+	// Filter, IPv6Suffix and PrefixLength are not sent to the API
+	core.DeviceOptions `json:"-" xwwwformurlencoded:"-"`
+	// CreateIfMissing create the record with TTL and RecordLine if it does not exist
+	CreateIfMissing bool `json:"-" xwwwformurlencoded:"-" KeyValue:"CreateIfMissing,create the record if it does not exist, true or false(default)"`
 }
 
 func (p *Parameters) Target() string {
@@ -37,6 +53,16 @@ func (p *Parameters) IsDeviceSet() bool {
 	return p.Device != ""
 }
 
+// GetRecordId return the record id, "" if it is not known
+func (p *Parameters) GetRecordId() string {
+	return p.RecordId
+}
+
+// SetRecordId set the record id cached, a stale one is looked up again when updating
+func (p *Parameters) SetRecordId(id string) {
+	p.RecordId = id
+}
+
 // IsTypeSet return whether the type is set correctly
 func (p *Parameters) IsTypeSet() bool {
 	return p.Type == "AAAA" || p.Type == "A"
@@ -80,7 +106,6 @@ func GenerateDefaultConfigInfo() Parameters {
 		Lang:         "en",
 		ErrorOnEmpty: "no",
 		Domain:       "example.com",
-		RecordId:     "record id",
 		Subdomain:    "www,mail,ftp...",
 		RecordLine:   "默认",
 		Value:        "1.2.3.4",
@@ -116,3 +141,77 @@ func getDefaultType() string {
 func (p *Parameters) getTotalDomain() string {
 	return p.Subdomain + "." + p.Domain
 }
+
+// MergeableWith return true if the two Parameters differ only by Subdomain, Type, Value and RecordId,
+// Value must be the same if Type is the same
+func (p *Parameters) MergeableWith(other core.Parameters) bool {
+	o, ok := other.(*Parameters)
+	if !ok {
+		return false
+	}
+	return p.LoginToken == o.LoginToken && p.Format == o.Format && p.Lang == o.Lang &&
+		p.ErrorOnEmpty == o.ErrorOnEmpty && p.Domain == o.Domain && p.RecordLine == o.RecordLine &&
+		p.RecordLineId == o.RecordLineId && p.Weight == o.Weight && p.Remark == o.Remark &&
+		(p.Type != o.Type || p.Value == o.Value) && p.TTL == o.TTL && p.Device == o.Device &&
+		p.DeviceOptions == o.DeviceOptions && p.CreateIfMissing == o.CreateIfMissing
+}
+
+// Merge return a new Parameters whose Subdomain is like "www,ftp", Type is like "A,AAAA",
+// Value is like "1.2.3.4,2001:db8::1" and RecordId is like "www:1,ftp:2" or "www:A:1,www:AAAA:2" for multiple types
+func (p *Parameters) Merge(others ...core.Parameters) core.Parameters {
+	merged := *p
+	all := append([]core.Parameters{p}, others...)
+	var subdomains, types, values []string
+	for _, other := range all {
+		o := other.(*Parameters)
+		subdomains = collections.AppendUnique(subdomains, o.Subdomain)
+		types = collections.AppendUnique(types, o.Type)
+		if o.Value != "" {
+			values = collections.AppendUnique(values, o.Value)
+		}
+	}
+
+	recordIds := make([]string, 0, len(all))
+	for _, other := range all {
+		o := other.(*Parameters)
+		if o.RecordId == "" {
+			continue
+		}
+		if len(types) > 1 {
+			recordIds = append(recordIds, o.Subdomain+recordIdSep+o.Type+recordIdSep+o.RecordId)
+		} else {
+			recordIds = append(recordIds, o.Subdomain+recordIdSep+o.RecordId)
+		}
+	}
+	merged.Subdomain = strings.Join(subdomains, ",")
+	merged.Type = strings.Join(types, ",")
+	merged.Value = strings.Join(values, ",")
+	merged.RecordId = strings.Join(recordIds, ",")
+	return &merged
+}
+
+// recordIdSep separates subdomain, type and record id in RecordId like "www:1,ftp:2" or "www:A:1,www:AAAA:2"
+const recordIdSep = ":"
+
+// parseRecordIds parse RecordId like "www:1,ftp:2" or "www:A:1,www:AAAA:2" to map of "subdomain:type" to record id
+// a record id without type is only meaningful when there is exactly one type,
+// and a plain record id like "1" is only meaningful when there is exactly one subdomain and one type
+func parseRecordIds(recordId string, subdomains []string, types []string) map[string]string {
+	ids := make(map[string]string, len(subdomains)*len(types))
+	if !strings.Contains(recordId, recordIdSep) {
+		if recordId != "" && len(subdomains) == 1 && len(types) == 1 {
+			ids[subdomains[0]+recordIdSep+types[0]] = recordId
+		}
+		return ids
+	}
+	for _, pair := range strings.Split(recordId, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), recordIdSep)
+		switch {
+		case len(parts) == 2 && parts[1] != "" && len(types) == 1:
+			ids[parts[0]+recordIdSep+types[0]] = parts[1]
+		case len(parts) == 3 && parts[2] != "":
+			ids[parts[0]+recordIdSep+netutil.Type2Str(parts[1])] = parts[2]
+		}
+	}
+	return ids
+}
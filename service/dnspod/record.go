@@ -0,0 +1,58 @@
+package dnspod
+
+import (
+	"fmt"
+	"strings"
+)
+
+// weight of record is null, number or string in json
+type weight string
+
+// UnmarshalJSON accept null, number and string
+func (w *weight) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		s = ""
+	}
+	*w = weight(s)
+	return nil
+}
+
+// matchRecords return records matching RecordLineId, Weight and Remark, the empty ones are ignored
+// RecordLine is not compared as Record.List filters records by record_line already,
+// and the name of line returned is localized like "默认" for "default"
+func (r *Request) matchRecords(records []record) []record {
+	matched := make([]record, 0, len(records))
+	for _, rec := range records {
+		if r.parameters.RecordLineId != "" && rec.LineId != r.parameters.RecordLineId {
+			continue
+		}
+		if r.parameters.Weight != "" && string(rec.Weight) != r.parameters.Weight {
+			continue
+		}
+		if r.parameters.Remark != "" && rec.Remark != r.parameters.Remark {
+			continue
+		}
+		matched = append(matched, rec)
+	}
+	return matched
+}
+
+// selectRecord return the only record to update
+// error if no record matches, or more than one record matches and it is ambiguous which one to update
+func (r *Request) selectRecord(records []record) (record, error) {
+	matched := r.matchRecords(records)
+	switch len(matched) {
+	case 0:
+		return record{}, fmt.Errorf("no record found")
+	case 1:
+		return matched[0], nil
+	default:
+		ids := make([]string, 0, len(matched))
+		for _, rec := range matched {
+			ids = append(ids, fmt.Sprintf("%s(line:%s,line_id:%s,weight:%s,remark:%s)", rec.Id, rec.Line, rec.LineId, rec.Weight, rec.Remark))
+		}
+		return record{}, fmt.Errorf("%d records found: %s, set RecordLineId, Weight or Remark to select one",
+			len(matched), strings.Join(ids, " "))
+	}
+}
@@ -0,0 +1,107 @@
+package dnspod
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	DDNS "GodDns/core"
+)
+
+// records of www on lines 默认 and 电信, the two 电信 records are weighted
+const splitHorizonRecords = `{"status":{"code":"1","message":"Action completed successful","created_at":"2023-01-01 00:00:00"},"records":[
+{"id":"1","value":"1.1.1.1","name":"www","line":"默认","line_id":"0","type":"A","weight":null,"remark":""},
+{"id":"2","value":"2.2.2.2","name":"www","line":"电信","line_id":"10=0","type":"A","weight":10,"remark":"primary"},
+{"id":"3","value":"3.3.3.3","name":"www","line":"电信","line_id":"10=0","type":"A","weight":"20","remark":"backup"}]}`
+
+// lineIds are ids of lines by names in both languages
+var lineIds = map[string]string{"默认": "0", "default": "0", "电信": "10=0", "telecom": "10=0", "联通": "10=1", "unicom": "10=1"}
+
+// filterByLine filter records like Record.List with record_line_id, or record_line if record_line_id is not set
+func filterByLine(t *testing.T, r *http.Request, records string) string {
+	if err := r.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	lineId := r.PostForm.Get("record_line_id")
+	if lineId == "" && r.PostForm.Get("record_line") != "" {
+		lineId = lineIds[r.PostForm.Get("record_line")]
+	}
+	if lineId == "" {
+		return records
+	}
+
+	res := resOfRecordId{}
+	if err := json.Unmarshal([]byte(records), &res); err != nil {
+		t.Fatal(err)
+	}
+	filtered := make([]map[string]any, 0, len(res.Records))
+	for _, rec := range res.Records {
+		if rec.LineId == lineId {
+			filtered = append(filtered, map[string]any{"id": rec.Id, "value": rec.Value, "line": rec.Line,
+				"line_id": rec.LineId, "weight": string(rec.Weight), "remark": rec.Remark})
+		}
+	}
+	data, _ := json.Marshal(map[string]any{"status": res.Status, "records": filtered})
+	return string(data)
+}
+
+func TestRequest_GetRecordIdSelectRecord(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, filterByLine(t, r, splitHorizonRecords))
+	}))
+	recordListUrl := RecordListUrl
+	RecordListUrl = server.URL
+	t.Cleanup(func() {
+		RecordListUrl = recordListUrl
+		server.Close()
+	})
+
+	cases := []struct {
+		recordLine, recordLineId, weight, remark string
+		recordId                                 string // empty if error expected
+		err                                      string
+	}{
+		{recordLine: "默认", recordId: "1"},
+		{recordLine: "default", recordId: "1"},
+		{recordLine: "telecom", weight: "10", recordId: "2"},
+		{recordLine: "电信", err: "2 records found"},
+		{recordLine: "电信", weight: "20", recordId: "3"},
+		{recordLine: "默认", recordLineId: "10=0", remark: "primary", recordId: "2"},
+		{recordLine: "电信", weight: "30", err: "no record found"},
+		{recordLine: "联通", err: "no record found"},
+	}
+	for _, c := range cases {
+		r := Request{parameters: Parameters{
+			LoginToken:   "TOKEN",
+			Format:       "json",
+			Domain:       "example.com",
+			Subdomain:    "www",
+			RecordLine:   c.recordLine,
+			RecordLineId: c.recordLineId,
+			Weight:       c.weight,
+			Remark:       c.remark,
+			Type:         "A",
+		}}
+		status, err := r.GetRecordId()
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%+v: error should contain %q, got %v", c, c.err, err)
+			}
+			if status.Status != DDNS.Failed || len(status.MG.GetMsgOf(DDNS.Error)) == 0 {
+				t.Errorf("%+v: status should be failed with error message, got %+v", c, status)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %v", c, err)
+			continue
+		}
+		if r.parameters.RecordId != c.recordId {
+			t.Errorf("%+v: record id should be %s, got %s", c, c.recordId, r.parameters.RecordId)
+		}
+	}
+}
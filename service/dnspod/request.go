@@ -11,18 +11,24 @@ import (
 	log "GodDns/log"
 	"GodDns/netutil"
 	json "GodDns/util/json"
+	xml "GodDns/util/xml"
 	"github.com/go-resty/resty/v2"
 )
 
-const (
+// urls are variables so that they can be pointed to a mock server in tests
+var (
 	// RecordListUrl url of getting Record list
 	RecordListUrl = "https://dnsapi.cn/Record.List"
 	// DDNSURL  url of DDNS
 	DDNSURL = "https://dnsapi.cn/Record.Ddns"
-
-	fatalStr = "Fatal"
+	// RecordCreateUrl url of creating Record
+	RecordCreateUrl = "https://dnsapi.cn/Record.Create"
+	// RecordInfoUrl url of getting Record info
+	RecordInfoUrl = "https://dnsapi.cn/Record.Info"
 )
 
+const fatalStr = "Fatal"
+
 type empty struct{}
 
 // usage
@@ -34,6 +40,12 @@ type empty struct{}
 type Request struct {
 	parameters Parameters
 	status     core.Status
+	// noCache is set when the cached RecordId turns out to be stale
+	noCache bool
+	// currentValue is the value the record holds, got from Record.List
+	currentValue string
+	// created is set when the record is created by CreateRecord
+	created bool
 }
 
 // Target return target domain
@@ -80,6 +92,10 @@ func (r *Request) encodeURLWithoutIDContent() url.Values {
 	v.Add("domain", r.parameters.Domain)
 	v.Add("sub_domain", r.parameters.Subdomain)
 	v.Add("record_line", r.parameters.RecordLine)
+	// record_line_id is preferred to record_line by Dnspod
+	if r.parameters.RecordLineId != "" {
+		v.Add("record_line_id", r.parameters.RecordLineId)
+	}
 
 	v.Add("record_type", r.parameters.Type)
 	return v
@@ -101,6 +117,7 @@ func (r *Request) encodeURL() (content string) {
 }
 
 func (r *Request) RequestThroughProxy() error {
+	cached := r.isRecordIdCached()
 	done := make(chan empty)
 	status := newStatus()
 	var err error
@@ -132,6 +149,14 @@ func (r *Request) RequestThroughProxy() error {
 			r.status.MG.AddError(err.Error())
 			return err
 		}
+		// the record is created with Value just now
+		if r.created {
+			r.status = *status
+			return nil
+		}
+		if r.isUnchanged() {
+			return nil
+		}
 		// content = Util.Convert2XWWWFormUrlencoded(&r.parameters)
 		content = r.encodeURL()
 
@@ -146,14 +171,9 @@ func (r *Request) RequestThroughProxy() error {
 	iter := netutil.GlobalProxies.GetProxyIter()
 	client := core.MainClientPool.Get().(*resty.Client)
 	defer core.MainClientPool.Put(client)
-	req := client.R()
 	for iter.NotLast() {
 		proxy := iter.Next()
-		response, err := req.
-			SetResult(s).
-			SetHeader("Content-Type", "application/x-www-form-urlencoded").
-			SetBody([]byte(content)).
-			Post(DDNSURL)
+		response, err := r.post(client, DDNSURL, content, s)
 		if err != nil {
 			errMsg := fmt.Sprintf("request error through proxy %s: %v", proxy, err)
 			r.status.MG.AddError(errMsg)
@@ -161,11 +181,13 @@ func (r *Request) RequestThroughProxy() error {
 			continue
 		} else {
 			log.Debugf("result:%+v", string(response.Body()))
-			_ = json.Unmarshal(response.Body(), s)
 			log.Debugf("after marshall:%+v", s)
 			break
 		}
 	}
+	if cached && s.Status.Code == BadDomain {
+		return r.retryWithoutCache(r.RequestThroughProxy)
+	}
 	r.status = *code2status(s.Status.Code)
 	if s.Status.Message == "" {
 		s.Status.Message = fatalStr
@@ -186,6 +208,7 @@ func (r *Request) RequestThroughProxy() error {
 
 // MakeRequest  1.GetRecordId  2.DDNS
 func (r *Request) MakeRequest() error {
+	cached := r.isRecordIdCached()
 	done := make(chan struct{})
 	status := newStatus()
 	var err error
@@ -216,6 +239,14 @@ func (r *Request) MakeRequest() error {
 			r.status.MG.AddError(err.Error())
 			return err
 		}
+		// the record is created with Value just now
+		if r.created {
+			r.status = *status
+			return nil
+		}
+		if r.isUnchanged() {
+			return nil
+		}
 		// content = Util.Convert2XWWWFormUrlencoded(&r.parameters)
 		content = r.encodeURL()
 
@@ -228,15 +259,12 @@ func (r *Request) MakeRequest() error {
 	log.Debugf("content:%s", content)
 	client := core.MainClientPool.Get().(*resty.Client)
 	defer core.MainClientPool.Put(client)
-	response, err := client.R().
-		SetResult(s).
-		SetHeader("Content-Type", "application/x-www-form-urlencoded").
-		SetBody([]byte(content)).
-		Post(DDNSURL)
+	response, err := r.post(client, DDNSURL, content, s)
 	log.Tracef("response: %v", response)
-	log.Debugf("result:%+v", s)
-	_ = json.Unmarshal(response.Body(), s)
 	log.Debugf("after marshall:%+v", s)
+	if cached && s.Status.Code == BadDomain {
+		return r.retryWithoutCache(r.MakeRequest)
+	}
 	r.status = *code2status(s.Status.Code)
 	if s.Status.Message == "" {
 		s.Status.Message = fatalStr
@@ -254,11 +282,108 @@ func (r *Request) MakeRequest() error {
 	}
 }
 
+// post make request to api with x-www-form-urlencoded content and unmarshal the response into v according to Format
+func (r *Request) post(client *resty.Client, api string, content string, v any) (*resty.Response, error) {
+	response, err := client.R().
+		SetHeader("Content-Type", "application/x-www-form-urlencoded").
+		SetBody(content).
+		Post(api)
+	if err != nil {
+		return response, err
+	}
+	log.Tracef("result:%s", response.String())
+	return response, r.unmarshal(response.Body(), v)
+}
+
+// unmarshal parse data in Format, json or xml
+func (r *Request) unmarshal(data []byte, v any) error {
+	if r.parameters.Format == FormatXML {
+		return xml.Unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// getCurrentValue return the value the record holds now
+// use the value got from Record.List if any, else make request to Record.Info with RecordId
+func (r *Request) getCurrentValue() (string, error) {
+	if r.currentValue != "" {
+		return r.currentValue, nil
+	}
+	if r.parameters.RecordId == "" {
+		return "", errors.New("record id is empty")
+	}
+
+	s := &resOfRecordInfo{}
+	v := url.Values{}
+	v.Add("login_token", r.parameters.LoginToken)
+	v.Add("format", r.parameters.Format)
+	v.Add("lang", r.parameters.Lang)
+	v.Add("domain", r.parameters.Domain)
+	v.Add("record_id", r.parameters.RecordId)
+
+	client := core.MainClientPool.Get().(*resty.Client)
+	defer core.MainClientPool.Put(client)
+	_, err := r.post(client, RecordInfoUrl, v.Encode(), s)
+	log.Debugf("after marshall:%s", s)
+	if err != nil {
+		return "", err
+	}
+	if s.Status.Code != Success {
+		return "", fmt.Errorf("status code:%s %s", s.Status.Code, s.Status.Message)
+	}
+	r.currentValue = s.Record.Value
+	return r.currentValue, nil
+}
+
+// isUnchanged check whether the record already holds Value, set status to Unchanged if so
+// the record is updated as usual if the current value can not be got
+func (r *Request) isUnchanged() bool {
+	value, err := r.getCurrentValue()
+	if err != nil {
+		log.Debugf("error getting current value of %s: %s", r.Target(), err.Error())
+		return false
+	}
+	if !netutil.IpEqual(value, r.parameters.Value) {
+		return false
+	}
+	r.status = *newStatus()
+	r.status.Status = core.Unchanged
+	r.status.MG.AddInfo(fmt.Sprintf("%s already holds %s, skip updating", r.parameters.getTotalDomain(), value))
+	return true
+}
+
+// isRecordIdCached return whether the RecordId is read from config and not looked up
+func (r *Request) isRecordIdCached() bool {
+	return r.parameters.RecordId != "" && !r.noCache
+}
+
+// retryWithoutCache look up the RecordId and make request again, when the cached RecordId is stale
+// Record.Ddns answers BadDomain(8) for bad record id
+func (r *Request) retryWithoutCache(request func() error) error {
+	log.Warnf("cached record id %s of %s may be stale, look up again", r.parameters.RecordId, r.Target())
+	r.noCache = true
+	r.parameters.RecordId = ""
+	r.currentValue = ""
+	return request()
+}
+
+// cachedRecordIdStatus return a success status when the RecordId is cached
+func (r *Request) cachedRecordIdStatus() core.Status {
+	status := newStatus()
+	status.Status = core.Success
+	status.MG.AddInfo(fmt.Sprintf("use cached record id %s of %s", r.parameters.RecordId, r.parameters.getTotalDomain()))
+	return *status
+}
+
 // GetRecordId make request to Dnspod to get RecordId and set ExternalParameter.RecordId
+// the request is skipped if RecordId is cached
 func (r *Request) GetRecordId() (core.Status, error) {
 	if r.status.MG == nil {
 		r.status.MG = core.NewDefaultMsgGroup()
 	}
+	if r.isRecordIdCached() {
+		return r.cachedRecordIdStatus(), nil
+	}
 
 	s := &resOfRecordId{}
 
@@ -269,13 +394,12 @@ func (r *Request) GetRecordId() (core.Status, error) {
 	// make request to "https://dnsapi.cn/Record.List" to get record id
 	client := core.MainClientPool.Get().(*resty.Client)
 	defer core.MainClientPool.Put(client)
-	_, err := client.R().
-		SetResult(s).
-		SetHeader("Content-Type", "application/x-www-form-urlencoded").
-		SetBody(content).
-		Post(RecordListUrl)
+	_, err := r.post(client, RecordListUrl, content, s)
 
 	log.Debugf("after marshall:%s", s)
+	if err == nil && r.isRecordMissing(s) {
+		return r.CreateRecord(client)
+	}
 	status := *code2status(s.Status.Code)
 	if err != nil {
 		if s.Status.Message == "" {
@@ -294,20 +418,27 @@ func (r *Request) GetRecordId() (core.Status, error) {
 		}
 	}
 
-	if len(s.Records) == 0 {
-		status.MG.AddError(fmt.Sprintf("%s at %s %s", s.Status.Message, s.Status.CreatedAt, r.parameters.getTotalDomain()))
-		return status, fmt.Errorf("no record found")
+	record, err := r.selectRecord(s.Records)
+	if err != nil {
+		status.Status = core.Failed
+		status.MG.AddError(fmt.Sprintf("%s at %s %s", err.Error(), s.Status.CreatedAt, r.parameters.getTotalDomain()))
+		return status, err
 	}
 
 	status.MG.AddInfo(fmt.Sprintf("%s at %s %s", s.Status.Message, s.Status.CreatedAt, r.parameters.getTotalDomain()))
-	r.parameters.RecordId = s.Records[0].Id
+	r.parameters.RecordId = record.Id
+	r.currentValue = record.Value
 	return status, nil
 }
 
+// GetRecordIdByProxy is the same as GetRecordId but make request through proxies
 func (r *Request) GetRecordIdByProxy() (core.Status, error) {
 	if r.status.MG == nil {
 		r.status.MG = core.NewDefaultMsgGroup()
 	}
+	if r.isRecordIdCached() {
+		return r.cachedRecordIdStatus(), nil
+	}
 	s := &resOfRecordId{}
 
 	content := r.encodeURLWithoutID()
@@ -315,12 +446,11 @@ func (r *Request) GetRecordIdByProxy() (core.Status, error) {
 
 	client := core.MainClientPool.Get().(*resty.Client)
 	defer core.MainClientPool.Put(client)
-	res := client.R().SetHeader("Content-Type", "application/x-www-form-urlencoded")
 	// make request to "https://dnsapi.cn/Record.List" to get record id
 	iter := netutil.GlobalProxies.GetProxyIter()
 	for iter.NotLast() {
 		proxy := iter.Next()
-		_, err := res.SetBody(content).SetResult(s).Post(RecordListUrl)
+		_, err := r.post(client, RecordListUrl, content, s)
 		log.Debugf("after marshall:%s", s)
 		if err == nil {
 			break
@@ -331,6 +461,9 @@ func (r *Request) GetRecordIdByProxy() (core.Status, error) {
 		log.ErrorRaw(errMsg)
 		continue
 	}
+	if r.isRecordMissing(s) {
+		return r.CreateRecord(client)
+	}
 	status := code2status(s.Status.Code) // " %s at %s %s", s.Status.Message, s.Status.CreatedAt, r.parameters.getTotalDomain()
 
 	if s.Status.Code != "1" {
@@ -342,52 +475,132 @@ func (r *Request) GetRecordIdByProxy() (core.Status, error) {
 		}
 	}
 
-	if len(s.Records) == 0 {
-		status.MG.AddError(fmt.Sprintf("%s at %s %s", s.Status.Message, s.Status.CreatedAt, r.parameters.getTotalDomain()))
-		return *status, fmt.Errorf("no record found")
+	record, err := r.selectRecord(s.Records)
+	if err != nil {
+		status.Status = core.Failed
+		status.MG.AddError(fmt.Sprintf("%s at %s %s", err.Error(), s.Status.CreatedAt, r.parameters.getTotalDomain()))
+		return *status, err
 	}
 
 	status.MG.AddInfo(fmt.Sprintf("%s at %s %s", s.Status.Message, s.Status.CreatedAt, r.parameters.getTotalDomain()))
-	r.parameters.RecordId = s.Records[0].Id
+	r.parameters.RecordId = record.Id
+	r.currentValue = record.Value
 	return *status, nil
 }
 
+// isRecordMissing return whether the record should be created
+func (r *Request) isRecordMissing(s *resOfRecordId) bool {
+	if !r.parameters.CreateIfMissing {
+		return false
+	}
+	return s.Status.Code == EmptyRecordList || (s.Status.Code == Success && len(r.matchRecords(s.Records)) == 0)
+}
+
+// CreateRecord make request to Dnspod to create the record with Value, TTL and RecordLine and set RecordId
+func (r *Request) CreateRecord(client *resty.Client) (core.Status, error) {
+	s := &resOfCreateRecord{}
+
+	v := r.encodeURLWithoutIDContent()
+	v.Add("value", r.parameters.Value)
+	v.Add("ttl", strconv.Itoa(int(r.parameters.TTL)))
+	if r.parameters.Weight != "" {
+		v.Add("weight", r.parameters.Weight)
+	}
+	content := v.Encode()
+	log.Debugf("content:%s", content)
+
+	// make request to "https://dnsapi.cn/Record.Create" to create record
+	_, err := r.post(client, RecordCreateUrl, content, s)
+
+	log.Debugf("after marshall:%s", s)
+	status := *code2status(s.Status.Code)
+	if err != nil {
+		status.Status = core.Failed
+		status.MG.AddError(fmt.Sprintf("error creating record %s: %s", r.parameters.getTotalDomain(), err.Error()))
+		return status, err
+	}
+
+	if s.Status.Code != Success {
+		if s.Status.Message == "" {
+			s.Status.Message = fatalStr
+		}
+		status.MG.AddError(fmt.Sprintf("%s at %s %s", s.Status.Message, s.Status.CreatedAt, r.parameters.getTotalDomain()))
+		return status, fmt.Errorf("status code:%s", s.Status.Code)
+	}
+
+	status.MG.AddInfo(fmt.Sprintf("record created at %s %s %s", s.Status.CreatedAt, r.parameters.getTotalDomain(), r.parameters.Value))
+	r.parameters.RecordId = s.Record.Id
+	r.created = true
+	return status, nil
+}
+
 type resOfRecordId struct {
 	Status struct {
-		Code      string `json:"code"`
-		Message   string `json:"message"`
-		CreatedAt string `json:"created_at"`
-	} `json:"status"`
-
-	Records []struct {
-		Id            string `json:"id"`
-		Ttl           string `json:"ttl"`
-		Value         string `json:"value"`
-		Enabled       string `json:"enabled"`
-		Status        string `json:"status"`
-		UpdatedOn     string `json:"updated_on"`
-		RecordTypeV1  string `json:"record_type_v1"`
-		Name          string `json:"name"`
-		Line          string `json:"line"`
-		LineId        string `json:"line_id"`
-		Type          string `json:"type"`
-		Weight        any    `json:"weight"`
-		MonitorStatus string `json:"monitor_status"`
-		Remark        string `json:"remark"`
-		UseAqb        string `json:"use_aqb"`
-		Mx            string `json:"mx"`
-	} `json:"records"`
+		Code      string `json:"code" xml:"code"`
+		Message   string `json:"message" xml:"message"`
+		CreatedAt string `json:"created_at" xml:"created_at"`
+	} `json:"status" xml:"status"`
+
+	Records []record `json:"records" xml:"records>item"`
 }
 
 type resOfddns struct {
 	Status struct {
-		Code      string `json:"code"`
-		Message   string `json:"message"`
-		CreatedAt string `json:"created_at"`
-	} `json:"status"`
+		Code      string `json:"code" xml:"code"`
+		Message   string `json:"message" xml:"message"`
+		CreatedAt string `json:"created_at" xml:"created_at"`
+	} `json:"status" xml:"status"`
+	Record struct {
+		Id    int    `json:"id" xml:"id"`
+		Name  string `json:"name" xml:"name"`
+		Value string `json:"value" xml:"value"`
+	} `json:"record" xml:"record"`
+}
+
+type resOfCreateRecord struct {
+	Status struct {
+		Code      string `json:"code" xml:"code"`
+		Message   string `json:"message" xml:"message"`
+		CreatedAt string `json:"created_at" xml:"created_at"`
+	} `json:"status" xml:"status"`
 	Record struct {
-		Id    int    `json:"id"`
-		Name  string `json:"name"`
-		Value string `json:"value"`
-	} `json:"record"`
+		Id     string `json:"id" xml:"id"`
+		Name   string `json:"name" xml:"name"`
+		Status string `json:"status" xml:"status"`
+	} `json:"record" xml:"record"`
+}
+
+type resOfRecordInfo struct {
+	Status struct {
+		Code      string `json:"code" xml:"code"`
+		Message   string `json:"message" xml:"message"`
+		CreatedAt string `json:"created_at" xml:"created_at"`
+	} `json:"status" xml:"status"`
+	Record struct {
+		Id         string `json:"id" xml:"id"`
+		SubDomain  string `json:"sub_domain" xml:"sub_domain"`
+		RecordType string `json:"record_type" xml:"record_type"`
+		RecordLine string `json:"record_line" xml:"record_line"`
+		Value      string `json:"value" xml:"value"`
+	} `json:"record" xml:"record"`
+}
+
+// record is an item of Record.List
+type record struct {
+	Id            string `json:"id" xml:"id"`
+	Ttl           string `json:"ttl" xml:"ttl"`
+	Value         string `json:"value" xml:"value"`
+	Enabled       string `json:"enabled" xml:"enabled"`
+	Status        string `json:"status" xml:"status"`
+	UpdatedOn     string `json:"updated_on" xml:"updated_on"`
+	RecordTypeV1  string `json:"record_type_v1" xml:"record_type_v1"`
+	Name          string `json:"name" xml:"name"`
+	Line          string `json:"line" xml:"line"`
+	LineId        string `json:"line_id" xml:"line_id"`
+	Type          string `json:"type" xml:"type"`
+	Weight        weight `json:"weight" xml:"weight"`
+	MonitorStatus string `json:"monitor_status" xml:"monitor_status"`
+	Remark        string `json:"remark" xml:"remark"`
+	UseAqb        string `json:"use_aqb" xml:"use_aqb"`
+	Mx            string `json:"mx" xml:"mx"`
 }
@@ -2,6 +2,13 @@ package dnspod
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -105,3 +112,278 @@ func TestRequest_MakeRequest(t *testing.T) {
 
 	t.Log(status)
 }
+
+// newTestServer mock Record.List, Record.Info and Record.Ddns
+// record id of every subdomain is "100" and the record holds "5.6.7.8"
+func newTestServer(t *testing.T, listHits *int32, ddnsHits *int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(listHits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"status":{"code":"1","message":"Action completed successful","created_at":"2023-01-01 00:00:00"},"records":[{"id":"100","name":"`+r.FormValue("sub_domain")+`","value":"5.6.7.8"}]}`)
+	})
+	mux.HandleFunc("/Record.Info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("record_id") != "100" {
+			_, _ = io.WriteString(w, `{"status":{"code":"8","message":"Record id invalid","created_at":"2023-01-01 00:00:00"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"status":{"code":"1","message":"Action completed successful","created_at":"2023-01-01 00:00:00"},"record":{"id":"100","sub_domain":"www","record_type":"A","record_line":"默认","value":"5.6.7.8"}}`)
+	})
+	mux.HandleFunc("/Record.Ddns", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(ddnsHits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("record_id") != "100" {
+			_, _ = io.WriteString(w, `{"status":{"code":"8","message":"Record id invalid","created_at":"2023-01-01 00:00:00"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"status":{"code":"1","message":"Action completed successful","created_at":"2023-01-01 00:00:00"},"record":{"id":100,"name":"`+r.FormValue("sub_domain")+`","value":"`+r.FormValue("value")+`"}}`)
+	})
+	server := httptest.NewServer(mux)
+
+	recordListUrl, recordInfoUrl, ddnsUrl := RecordListUrl, RecordInfoUrl, DDNSURL
+	RecordListUrl, RecordInfoUrl, DDNSURL = server.URL+"/Record.List", server.URL+"/Record.Info", server.URL+"/Record.Ddns"
+	t.Cleanup(func() {
+		RecordListUrl, RecordInfoUrl, DDNSURL = recordListUrl, recordInfoUrl, ddnsUrl
+		server.Close()
+	})
+	return server
+}
+
+func TestRequest_MakeRequestWithCachedRecordId(t *testing.T) {
+	var listHits, ddnsHits int32
+	newTestServer(t, &listHits, &ddnsHits)
+
+	cases := []struct {
+		recordId string
+		hits     int32
+	}{
+		{"", 1},      // looked up
+		{"100", 0},   // cached
+		{"stale", 1}, // cached but stale, looked up again
+	}
+	for _, c := range cases {
+		atomic.StoreInt32(&listHits, 0)
+		p := Parameters{
+			LoginToken: "TOKEN",
+			Format:     "json",
+			Domain:     "example.com",
+			RecordId:   c.recordId,
+			Subdomain:  "www",
+			RecordLine: "默认",
+			Value:      "1.2.3.4",
+			TTL:        600,
+			Type:       "A",
+		}
+		r, _ := p.ToRequest()
+		if err := r.MakeRequest(); err != nil {
+			t.Fatal(err, r.Status().MG.GetMsgOf(DDNS.Error))
+		}
+		if r.Status().Status != DDNS.Success {
+			t.Errorf("status should be success, got %d", r.Status().Status)
+		}
+		if hits := atomic.LoadInt32(&listHits); hits != c.hits {
+			t.Errorf("record id %q: Record.List should be requested %d times, got %d", c.recordId, c.hits, hits)
+		}
+		if id := r.ToParameters().(*Parameters).RecordId; id != "100" {
+			t.Errorf("record id should be 100, got %s", id)
+		}
+	}
+}
+
+func TestRequest_MakeRequestUnchanged(t *testing.T) {
+	var listHits, ddnsHits int32
+	newTestServer(t, &listHits, &ddnsHits)
+
+	// value from Record.List and Record.Info
+	for _, recordId := range []string{"", "100"} {
+		atomic.StoreInt32(&ddnsHits, 0)
+		p := Parameters{
+			LoginToken: "TOKEN",
+			Format:     "json",
+			Domain:     "example.com",
+			RecordId:   recordId,
+			Subdomain:  "www",
+			RecordLine: "默认",
+			Value:      "5.6.7.8",
+			TTL:        600,
+			Type:       "A",
+		}
+		r, _ := p.ToRequest()
+		if err := r.MakeRequest(); err != nil {
+			t.Fatal(err, r.Status().MG.GetMsgOf(DDNS.Error))
+		}
+		if r.Status().Status != DDNS.Unchanged {
+			t.Errorf("record id %q: status should be unchanged, got %d", recordId, r.Status().Status)
+		}
+		if hits := atomic.LoadInt32(&ddnsHits); hits != 0 {
+			t.Errorf("record id %q: Record.Ddns should not be requested, got %d", recordId, hits)
+		}
+		t.Log(r.Status().MG.GetMsgOf(DDNS.Info))
+	}
+}
+
+func TestRequest_MakeRequestCreateIfMissing(t *testing.T) {
+	var created int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Record.List", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.LoadInt32(&created) == 0 {
+			_, _ = io.WriteString(w, `{"status":{"code":"10","message":"No records","created_at":"2023-01-01 00:00:00"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"status":{"code":"1","message":"Action completed successful","created_at":"2023-01-01 00:00:00"},"records":[{"id":"200","name":"new"}]}`)
+	})
+	mux.HandleFunc("/Record.Create", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("value") != "1.2.3.4" || r.FormValue("ttl") != "300" || r.FormValue("record_line") != "默认" || r.FormValue("record_type") != "A" {
+			t.Errorf("unexpected form %v", r.Form)
+		}
+		atomic.AddInt32(&created, 1)
+		_, _ = io.WriteString(w, `{"status":{"code":"1","message":"Action completed successful","created_at":"2023-01-01 00:00:00"},"record":{"id":"200","name":"new","status":"enable"}}`)
+	})
+	mux.HandleFunc("/Record.Ddns", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("record_id") != "200" {
+			_, _ = io.WriteString(w, `{"status":{"code":"8","message":"Record id invalid","created_at":"2023-01-01 00:00:00"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"status":{"code":"1","message":"Action completed successful","created_at":"2023-01-01 00:00:00"},"record":{"id":200,"name":"new","value":"`+r.FormValue("value")+`"}}`)
+	})
+	server := httptest.NewServer(mux)
+	recordListUrl, ddnsUrl, recordCreateUrl := RecordListUrl, DDNSURL, RecordCreateUrl
+	RecordListUrl, DDNSURL, RecordCreateUrl = server.URL+"/Record.List", server.URL+"/Record.Ddns", server.URL+"/Record.Create"
+	t.Cleanup(func() {
+		RecordListUrl, DDNSURL, RecordCreateUrl = recordListUrl, ddnsUrl, recordCreateUrl
+		server.Close()
+	})
+
+	p := Parameters{
+		LoginToken: "TOKEN",
+		Format:     "json",
+		Domain:     "example.com",
+		Subdomain:  "new",
+		RecordLine: "默认",
+		Value:      "1.2.3.4",
+		TTL:        300,
+		Type:       "A",
+	}
+
+	// not created without CreateIfMissing
+	r, _ := p.ToRequest()
+	if r.MakeRequest() == nil {
+		t.Fatal("should return error")
+	}
+	if atomic.LoadInt32(&created) != 0 {
+		t.Fatal("record should not be created")
+	}
+
+	p.CreateIfMissing = true
+	r, _ = p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err, r.Status().MG.GetMsgOf(DDNS.Error))
+	}
+	if atomic.LoadInt32(&created) != 1 {
+		t.Errorf("record should be created once, got %d", created)
+	}
+	if id := r.ToParameters().(*Parameters).RecordId; id != "200" {
+		t.Errorf("record id should be 200, got %s", id)
+	}
+}
+
+// newFixtureServer serve Record.List and Record.Ddns from testdata in the requested format
+// prefix of Record.List fixture is listFixture like "record_list" or "record_list_error"
+func newFixtureServer(t *testing.T, listFixture string) {
+	serve := func(fixture string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			format := r.FormValue("format")
+			data, err := os.ReadFile(filepath.Join("testdata", fixture+"."+format))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			w.Header().Set("Content-Type", "text/"+format)
+			_, _ = w.Write(data)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Record.List", serve(listFixture))
+	mux.HandleFunc("/Record.Ddns", serve("record_ddns"))
+	server := httptest.NewServer(mux)
+
+	recordListUrl, ddnsUrl := RecordListUrl, DDNSURL
+	RecordListUrl, DDNSURL = server.URL+"/Record.List", server.URL+"/Record.Ddns"
+	t.Cleanup(func() {
+		RecordListUrl, DDNSURL = recordListUrl, ddnsUrl
+		server.Close()
+	})
+}
+
+func TestRequest_Unmarshal(t *testing.T) {
+	for _, fixture := range []string{"record_list", "record_list_error"} {
+		var results [2]resOfRecordId
+		for i, format := range []string{FormatJSON, FormatXML} {
+			data, err := os.ReadFile(filepath.Join("testdata", fixture+"."+format))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := Request{parameters: Parameters{Format: format}}
+			if err = r.unmarshal(data, &results[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !reflect.DeepEqual(results[0], results[1]) {
+			t.Errorf("%s: json and xml should be the same\n%+v\n%+v", fixture, results[0], results[1])
+		}
+	}
+
+	var results [2]resOfddns
+	for i, format := range []string{FormatJSON, FormatXML} {
+		data, _ := os.ReadFile(filepath.Join("testdata", "record_ddns."+format))
+		r := Request{parameters: Parameters{Format: format}}
+		if err := r.unmarshal(data, &results[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("record_ddns: json and xml should be the same\n%+v\n%+v", results[0], results[1])
+	}
+}
+
+func TestRequest_MakeRequestInFormats(t *testing.T) {
+	cases := []struct {
+		fixture string
+		status  int
+	}{
+		{"record_list", DDNS.Success},
+		{"record_list_error", DDNS.Failed},
+	}
+	for _, c := range cases {
+		newFixtureServer(t, c.fixture)
+		var msgs [2][]string
+		for i, format := range []string{FormatJSON, FormatXML} {
+			p := Parameters{
+				LoginToken: "TOKEN",
+				Format:     format,
+				Domain:     "example.com",
+				Subdomain:  "www",
+				RecordLine: "默认",
+				Value:      "1.2.3.4",
+				TTL:        600,
+				Type:       "A",
+			}
+			r, _ := p.ToRequest()
+			err := r.MakeRequest()
+			if r.Status().Status != c.status {
+				t.Errorf("%s %s: status should be %d, got %d %v", c.fixture, format, c.status, r.Status().Status, err)
+			}
+			if c.status == DDNS.Success && r.ToParameters().(*Parameters).RecordId != "16894439" {
+				t.Errorf("%s %s: record id should be 16894439, got %s", c.fixture, format, r.ToParameters().(*Parameters).RecordId)
+			}
+			msgs[i] = append(r.Status().MG.GetMsgOf(DDNS.Info), r.Status().MG.GetMsgOf(DDNS.Error)...)
+		}
+		if !reflect.DeepEqual(msgs[0], msgs[1]) {
+			t.Errorf("%s: json and xml should behave identically\n%v\n%v", c.fixture, msgs[0], msgs[1])
+		}
+	}
+}
@@ -32,6 +32,8 @@ const (
 	// BadUserOwner               = 7 //proxy only
 
 	AccountLocked = "83"
+
+	EmptyRecordList = "10" // Record.List only, when error_on_empty is yes
 )
 
 // code2status
@@ -90,6 +92,8 @@ func code2status(code string) *core.Status {
 	//	msg.msg = "用户不属于您"
 	case AccountLocked:
 		msg.MG.AddError("账号被锁定")
+	case EmptyRecordList:
+		msg.MG.AddError("记录列表为空")
 	case LoginRegionLimited:
 		msg.MG.AddError("用户登录地异常或该帐户开启了登录区域保护，当前IP不在允许的区域内。")
 	default:
@@ -66,7 +66,7 @@ func GenerateDefaultConfigInfo() DnspodYun {
 func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
 	names := [9]string{"SecretID", "SecretKey", "Domain", "SubDomain", "RecordId", "RecordLine", "Value", "TTL", "Type"}
 	p := DnspodYun{}
-	var subdomains []string
+	var subdomains, types []string
 	for _, name := range names {
 		if !sec.HasKey(name) {
 			return nil, core.NewMissKeyErr(name, serviceName)
@@ -74,8 +74,8 @@ func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
 			switch name {
 			case "SubDomain":
 				subdomain := sec.Key(name).String()
-				subdomains = strings.Fields(strings.ReplaceAll(subdomain, ",", " "))
-				collections.RemoveDuplicate(&subdomains)
+				// keep the order of subdomains so that the section is saved back as it is
+				subdomains = collections.AppendUnique(nil, strings.Fields(strings.ReplaceAll(subdomain, ",", " "))...)
 			case "TTL":
 				ttl, err := sec.Key(name).Uint64()
 				if err != nil {
@@ -83,7 +83,8 @@ func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
 				}
 				p.TTL = ttl
 			case "Type":
-				p.Type = netutil.Type2Str(sec.Key(name).String())
+				// Type=A,AAAA or Type=both for a dual-stack section
+				types = netutil.ParseTypes(sec.Key(name).String())
 			default:
 				err := util.SetVariable(&p, name, sec.Key(name).String())
 				if err != nil {
@@ -92,22 +93,39 @@ func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
 			}
 		}
 	}
-	ps := make([]core.Parameters, 0, len(subdomains))
+	// empty if not exist
+	options, err := core.ReadDeviceOptions(sec)
+	if err != nil {
+		return nil, err
+	}
+	p.DeviceOptions = options
+	// false if not exist
+	p.CreateIfMissing = sec.Key("CreateIfMissing").MustBool(false)
+
+	// one value per type like Value=1.2.3.4,2001:db8::1
+	values := netutil.ValuesOfTypes(p.Value, types)
+
+	ps := make([]core.Parameters, 0, len(subdomains)*len(types))
 	for _, subdomain := range subdomains {
 		if subdomain == "" {
 			continue
 		}
-		ps = append(ps, &DnspodYun{
-			SecretID:   p.SecretID,
-			SecretKey:  p.SecretKey,
-			Domain:     p.Domain,
-			SubDomain:  subdomain,
-			RecordId:   p.RecordId,
-			RecordLine: p.RecordLine,
-			Value:      p.Value,
-			TTL:        p.TTL,
-			Type:       p.Type,
-		})
+		for _, t := range types {
+			ps = append(ps, &DnspodYun{
+				SecretID:   p.SecretID,
+				SecretKey:  p.SecretKey,
+				Domain:     p.Domain,
+				SubDomain:  subdomain,
+				RecordId:   p.RecordId,
+				RecordLine: p.RecordLine,
+				Value:      values[t],
+				TTL:        p.TTL,
+				Type:       t,
+
+				DeviceOptions:   p.DeviceOptions,
+				CreateIfMissing: p.CreateIfMissing,
+			})
+		}
 	}
 
 	return ps, nil
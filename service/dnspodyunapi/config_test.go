@@ -0,0 +1,137 @@
+package dnspodyunapi
+
+import (
+	"strings"
+	"testing"
+
+	"GodDns/core"
+	"gopkg.in/ini.v1"
+)
+
+func TestConfig_ReadConfigDualStack(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[DnspodYun]
+SecretID=ID
+SecretKey=KEY
+Domain=example.com
+SubDomain=www,ftp
+RecordId=0
+RecordLine=默认
+Value=1.2.3.4,2001:db8::1
+TTL=600
+Type=4,6
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("DnspodYun"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 4 {
+		t.Fatalf("expect one DnspodYun per subdomain and type, got %d", len(ps))
+	}
+	expected := map[string]string{"A": "1.2.3.4", "AAAA": "2001:db8::1"}
+	for _, p := range ps {
+		p := p.(*DnspodYun)
+		if p.Value != expected[p.Type] {
+			t.Errorf("value of %s %s should be %s, got %s", p.SubDomain, p.Type, expected[p.Type], p.Value)
+		}
+	}
+
+	// saved back as a single section
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("DnspodYun of a dual-stack section should be merged, got %d", len(merged))
+	}
+	m := merged[0].(*DnspodYun)
+	if m.SubDomain != "www,ftp" || m.Type != "A,AAAA" || m.Value != "1.2.3.4,2001:db8::1" || m.RecordId != "0" {
+		t.Errorf("unexpected merged DnspodYun %+v", m)
+	}
+}
+
+func TestConfig_ReadConfigFilter(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[DnspodYun]
+SecretID=ID
+SecretKey=KEY
+Domain=example.com
+SubDomain=www,ftp
+RecordId=0
+RecordLine=默认
+Value=1.2.3.4
+TTL=600
+Type=A
+Filter=remove-loopback,global-unicast
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("DnspodYun"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range ps {
+		if f := p.(core.Filterable).GetFilter(); f != "remove-loopback,global-unicast" {
+			t.Errorf("unexpected filter %s", f)
+		}
+	}
+
+	// saved back with the filter
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("expect 1 merged DnspodYun, got %d", len(merged))
+	}
+	config, err := merged[0].(core.Service).SaveConfig(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(config.Content, "Filter=remove-loopback,global-unicast") {
+		t.Errorf("filter should be saved, got %s", config.Content)
+	}
+}
+
+func TestConfig_ReadConfigIPv6Suffix(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[DnspodYun]
+SecretID=ID
+SecretKey=KEY
+Domain=example.com
+SubDomain=nas,printer
+RecordId=0
+RecordLine=默认
+Value=::
+TTL=600
+Type=AAAA
+IPv6Suffix=::1:2:3:4
+PrefixLength=56
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("DnspodYun"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range ps {
+		p := p.(*DnspodYun)
+		if p.GetIPv6Suffix() != "::1:2:3:4" || p.GetPrefixLength() != 56 {
+			t.Errorf("unexpected DnspodYun %+v", p)
+		}
+		if err := core.SetValue(p, "2001:db8:1:ff::1"); err != nil {
+			t.Fatal(err)
+		}
+		if p.Value != "2001:db8:1:0:1:2:3:4" {
+			t.Errorf("expect 2001:db8:1:0:1:2:3:4, got %s", p.Value)
+		}
+	}
+
+	sec := cfg.Section("DnspodYun")
+	sec.Key("PrefixLength").SetValue("129")
+	if _, err := (Config{}).ReadConfig(*sec); err == nil {
+		t.Error("expect error with PrefixLength 129")
+	}
+}
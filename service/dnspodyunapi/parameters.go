@@ -2,8 +2,11 @@
 package dnspodyunapi
 
 import (
+	"strings"
+
 	"GodDns/core"
 	"GodDns/netutil"
+	"GodDns/util/collections"
 )
 
 type DnspodYun struct {
@@ -17,7 +20,10 @@ type DnspodYun struct {
 	Value                string
 	TTL                  uint64
 	Type                 string
-	device               string
+	core.DeviceOptions
+	// CreateIfMissing create the record with TTL and RecordLine if it does not exist
+	CreateIfMissing bool
+	device          string
 }
 
 func (s *DnspodYun) GetDevice() string {
@@ -65,3 +71,34 @@ func (s *DnspodYun) Target() string {
 func (s *DnspodYun) getTotalDomain() string {
 	return s.SubDomain + "." + s.Domain
 }
+
+// MergeableWith return true if the two DnspodYun differ only by SubDomain, Type, Value and RecordId,
+// Value must be the same if Type is the same
+func (s *DnspodYun) MergeableWith(other core.Parameters) bool {
+	o, ok := other.(*DnspodYun)
+	if !ok {
+		return false
+	}
+	return s.SecretID == o.SecretID && s.SecretKey == o.SecretKey && s.Domain == o.Domain &&
+		s.RecordLine == o.RecordLine && (s.Type != o.Type || s.Value == o.Value) && s.TTL == o.TTL &&
+		s.DeviceOptions == o.DeviceOptions && s.CreateIfMissing == o.CreateIfMissing && s.device == o.device
+}
+
+// Merge return a new DnspodYun whose SubDomain is like "www,ftp", Type is like "A,AAAA" and Value is like "1.2.3.4,2001:db8::1"
+// RecordId is looked up every time, so the one of the receiver is kept
+func (s *DnspodYun) Merge(others ...core.Parameters) core.Parameters {
+	merged := *s
+	var subdomains, types, values []string
+	for _, other := range append([]core.Parameters{s}, others...) {
+		o := other.(*DnspodYun)
+		subdomains = collections.AppendUnique(subdomains, o.SubDomain)
+		types = collections.AppendUnique(types, o.Type)
+		if o.Value != "" {
+			values = collections.AppendUnique(values, o.Value)
+		}
+	}
+	merged.SubDomain = strings.Join(subdomains, ",")
+	merged.Type = strings.Join(types, ",")
+	merged.Value = strings.Join(values, ",")
+	return &merged
+}
@@ -6,6 +6,7 @@ import (
 
 	"GodDns/core"
 	log "GodDns/log"
+	"GodDns/netutil"
 	json "GodDns/util/json"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
@@ -13,7 +14,11 @@ import (
 	dnspod "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/dnspod/v20210323"
 )
 
-const api = "dnspod.tencentcloudapi.com"
+var (
+	api = "dnspod.tencentcloudapi.com"
+	// scheme of api, HTTPS or HTTP
+	scheme = "HTTPS"
+)
 
 type Request struct {
 	Parameters DnspodYun
@@ -45,9 +50,8 @@ func (r *Request) GetName() string {
 	return serviceName
 }
 
-func (r *Request) MakeRequest() error {
-	r.status = *newStatus()
-
+// newClient return a client of Tencent Cloud API
+func (r *Request) newClient() *dnspod.Client {
 	credential := common.NewCredential(
 		r.Parameters.SecretID,
 		r.Parameters.SecretKey,
@@ -55,31 +59,65 @@ func (r *Request) MakeRequest() error {
 	// 实例化一个client选项，可选的，没有特殊需求可以跳过
 	cpf := profile.NewClientProfile()
 	cpf.HttpProfile.Endpoint = api
+	cpf.HttpProfile.Scheme = scheme
 	// 实例化要请求产品的client对象,clientProfile是可选的
 	client, _ := dnspod.NewClient(credential, "", cpf)
+	return client
+}
 
+// describeRecord return the first record of DescribeRecordList, nil if the record does not exist
+func (r *Request) describeRecord(client *dnspod.Client) (*dnspod.RecordListItem, error) {
 	requestRecord := dnspod.NewDescribeRecordListRequest()
 	requestRecord.Domain = common.StringPtr(r.Parameters.Domain)
 	requestRecord.Subdomain = common.StringPtr(r.Parameters.SubDomain)
 	requestRecord.RecordType = common.StringPtr(r.Parameters.Type)
 	requestRecord.RecordLine = common.StringPtr(r.Parameters.RecordLine)
 
-	var responseRecordId *dnspod.DescribeRecordListResponse
 	// 返回的resp是一个DescribeRecordListResponse的实例，与请求对象对应
-	errChan := make(chan error, 1)
-	_ = core.MainGoroutinePool.Submit(func() {
-		_responseRecordId, err := client.DescribeRecordList(requestRecord)
-		if _, ok := err.(*errors.TencentCloudSDKError); ok {
-			log.Debug("an API error has returned ", log.String("error", err.Error()).String())
-			r.status.Status = core.Failed
-			r.status.MG.AddError(err.(*errors.TencentCloudSDKError).Message)
-			errChan <- err
-		} else if err != nil {
-			errChan <- err
+	responseRecordId, err := client.DescribeRecordList(requestRecord)
+	if sdkErr, ok := err.(*errors.TencentCloudSDKError); ok && sdkErr.Code == dnspod.RESOURCENOTFOUND_NODATAOFRECORD {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	records := responseRecordId.Response.RecordList
+	if len(records) == 0 {
+		return nil, nil
+	}
+	if len(records) > 1 {
+		r.status.MG.AddWarn(fmt.Sprintf("%d records found of %s, the first one is updated", len(records), r.Parameters.getTotalDomain()))
+	}
+	return records[0], nil
+}
+
+// MakeRequest 1.DescribeRecordList to get RecordId 2.ModifyDynamicDNS
+// if the record does not exist and CreateIfMissing is set, CreateRecord instead of ModifyDynamicDNS
+// if the record already holds Value, status is set to Unchanged and the record is not updated
+func (r *Request) MakeRequest() error {
+	r.status = *newStatus()
+
+	client := r.newClient()
+	record, err := r.describeRecord(client)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	if record == nil {
+		if !r.Parameters.CreateIfMissing {
+			return r.fail(fmt.Errorf("no record found of %s", r.Parameters.getTotalDomain()))
 		}
-		errChan <- nil
-		responseRecordId = _responseRecordId
-	})
+		return r.createRecord(client)
+	}
+
+	id := *record.RecordId
+	r.Parameters.RecordId = strconv.FormatUint(id, 10)
+
+	if record.Value != nil && netutil.IpEqual(*record.Value, r.Parameters.Value) {
+		r.status.Status = core.Unchanged
+		r.status.MG.AddInfo(fmt.Sprintf("%s already holds %s, skip updating", r.Parameters.getTotalDomain(), *record.Value))
+		return nil
+	}
 
 	// 实例化一个请求对象,每个接口都会对应一个request对象
 	requestDDNS := dnspod.NewModifyDynamicDNSRequest()
@@ -87,29 +125,14 @@ func (r *Request) MakeRequest() error {
 	requestDDNS.Domain = common.StringPtr(r.Parameters.Domain)
 	requestDDNS.SubDomain = common.StringPtr(r.Parameters.SubDomain)
 	requestDDNS.RecordLine = common.StringPtr(r.Parameters.RecordLine)
-	requestDDNS.Value = common.StringPtr(r.Parameters.Type)
+	requestDDNS.Value = common.StringPtr(r.Parameters.Value)
 	requestDDNS.Ttl = common.Uint64Ptr(r.Parameters.TTL)
-
-	var id uint64
-	if err := <-errChan; err != nil {
-		return err
-	}
-
-	if len(responseRecordId.Response.RecordList) <= 1 {
-		id = *responseRecordId.Response.RecordList[0].RecordId
-		r.Parameters.RecordId = strconv.FormatUint(id, 10)
-	}
 	requestDDNS.RecordId = common.Uint64Ptr(id)
 
 	// 返回的resp是一个ModifyDynamicDNSResponse的实例，与请求对象对应
 	responseDDNS, err := client.ModifyDynamicDNS(requestDDNS)
-	if _, ok := err.(*errors.TencentCloudSDKError); ok {
-		log.Debug("an API error has returned ", log.String("error", err.Error()).String())
-		r.status.Status = core.Failed
-		r.status.MG.AddError(err.(*errors.TencentCloudSDKError).Message)
-		return fmt.Errorf("an API error has returned: %w", err)
-	} else if err != nil {
-		return err
+	if err != nil {
+		return r.fail(err)
 	}
 
 	res := res{}
@@ -129,6 +152,41 @@ func (r *Request) MakeRequest() error {
 	return nil
 }
 
+// createRecord create the record with TTL and RecordLine and set RecordId
+func (r *Request) createRecord(client *dnspod.Client) error {
+	request := dnspod.NewCreateRecordRequest()
+	request.Domain = common.StringPtr(r.Parameters.Domain)
+	request.SubDomain = common.StringPtr(r.Parameters.SubDomain)
+	request.RecordType = common.StringPtr(r.Parameters.Type)
+	request.RecordLine = common.StringPtr(r.Parameters.RecordLine)
+	request.Value = common.StringPtr(r.Parameters.Value)
+	request.TTL = common.Uint64Ptr(r.Parameters.TTL)
+
+	response, err := client.CreateRecord(request)
+	if err != nil {
+		return r.fail(err)
+	}
+	if response.Response.RecordId != nil {
+		r.Parameters.RecordId = strconv.FormatUint(*response.Response.RecordId, 10)
+	}
+
+	r.status.Status = core.Success
+	r.status.MG.AddInfo(fmt.Sprintf("record created %s %s", r.Parameters.getTotalDomain(), r.Parameters.Value))
+	return nil
+}
+
+// fail set status to Failed with err and return err
+func (r *Request) fail(err error) error {
+	r.status.Status = core.Failed
+	if sdkErr, ok := err.(*errors.TencentCloudSDKError); ok {
+		log.Debug("an API error has returned ", log.String("error", err.Error()).String())
+		r.status.MG.AddError(sdkErr.Message)
+		return fmt.Errorf("an API error has returned: %w", err)
+	}
+	r.status.MG.AddError(err.Error())
+	return err
+}
+
 func (r *Request) Status() core.Status {
 	return r.status
 }
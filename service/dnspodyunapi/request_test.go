@@ -0,0 +1,108 @@
+package dnspodyunapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"GodDns/core"
+)
+
+// newTestServer mock DescribeRecordList, CreateRecord and ModifyDynamicDNS
+// the record list is empty until the record is created, then the record holds "5.6.7.8"
+func newTestServer(t *testing.T, actions *[]string) {
+	created := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		action := r.Header.Get("X-TC-Action")
+		*actions = append(*actions, action)
+		body := map[string]any{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		switch action {
+		case "DescribeRecordList":
+			if !created {
+				_, _ = io.WriteString(w, `{"Response":{"Error":{"Code":"ResourceNotFound.NoDataOfRecord","Message":"记录列表为空。"},"RequestId":"1"}}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"Response":{"RecordCountInfo":{"SubdomainCount":1,"TotalCount":1,"ListCount":1},"RecordList":[{"RecordId":200,"Value":"5.6.7.8","Name":"www","Type":"A","Line":"默认","TTL":300}],"RequestId":"2"}}`)
+		case "CreateRecord":
+			if body["Value"] != "1.2.3.4" || body["RecordType"] != "A" || body["RecordLine"] != "默认" || body["TTL"] != float64(300) {
+				t.Errorf("unexpected body %v", body)
+			}
+			created = true
+			_, _ = io.WriteString(w, `{"Response":{"RecordId":200,"RequestId":"3"}}`)
+		case "ModifyDynamicDNS":
+			if body["Value"] != "1.2.3.4" || body["RecordId"] != float64(200) {
+				t.Errorf("unexpected body %v", body)
+			}
+			_, _ = io.WriteString(w, `{"Response":{"RecordId":200,"RequestId":"4"}}`)
+		default:
+			t.Errorf("unexpected action %s", action)
+		}
+	}))
+
+	oldApi, oldScheme := api, scheme
+	api, scheme = strings.TrimPrefix(server.URL, "http://"), "HTTP"
+	t.Cleanup(func() {
+		api, scheme = oldApi, oldScheme
+		server.Close()
+	})
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	var actions []string
+	newTestServer(t, &actions)
+
+	p := DnspodYun{
+		SecretID:   "SecretID",
+		SecretKey:  "SecretKey",
+		Domain:     "example.com",
+		SubDomain:  "www",
+		RecordLine: "默认",
+		Value:      "1.2.3.4",
+		TTL:        300,
+		Type:       "A",
+	}
+
+	// not created without CreateIfMissing
+	r, _ := p.ToRequest()
+	if r.MakeRequest() == nil {
+		t.Fatal("should return error")
+	}
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+
+	p.CreateIfMissing = true
+	r, _ = p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err, r.Status().MG.GetMsgOf(core.Error))
+	}
+	if id := r.ToParameters().(*DnspodYun).RecordId; id != "200" {
+		t.Errorf("record id should be 200, got %s", id)
+	}
+
+	// modified once created
+	r, _ = p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err, r.Status().MG.GetMsgOf(core.Error))
+	}
+
+	// unchanged
+	p.Value = "5.6.7.8"
+	r, _ = p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err, r.Status().MG.GetMsgOf(core.Error))
+	}
+	if r.Status().Status != core.Unchanged {
+		t.Errorf("status should be unchanged, got %d", r.Status().Status)
+	}
+
+	expected := "DescribeRecordList,DescribeRecordList,CreateRecord,DescribeRecordList,ModifyDynamicDNS,DescribeRecordList"
+	if got := strings.Join(actions, ","); got != expected {
+		t.Errorf("actions should be %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,112 @@
+package dyndns2
+
+import (
+	"bytes"
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util"
+	"GodDns/util/collections"
+	"gopkg.in/ini.v1"
+)
+
+func init() {
+	core.Add2FactoryList(configFactoryInstance)
+}
+
+var (
+	configFactoryInstance ConfigFactory
+	configInstance        Config
+)
+
+// ConfigFactory is a factory that create a new Config
+type ConfigFactory struct{}
+
+// GetName return the name of dyndns2
+func (c ConfigFactory) GetName() string {
+	return serviceName
+}
+
+// Get return a singleton Config
+func (c ConfigFactory) Get() core.Config {
+	return &configInstance
+}
+
+// New return a new Config
+func (c ConfigFactory) New() *core.Config {
+	var config core.Config = &Config{}
+	return &config
+}
+
+// Config implements core.Config
+type Config struct{}
+
+// GetName Get name of service
+func (c Config) GetName() string {
+	return serviceName
+}
+
+// GenerateDefaultConfigInfo Create default config
+func (c Config) GenerateDefaultConfigInfo() (core.ConfigStr, error) {
+	p := GenerateDefaultConfigInfo()
+	return c.GenerateConfigInfo(&p, 0)
+}
+
+// ReadConfig Read config file
+// Parameters: sec ini.Section
+// Return: core.Parameters and error
+// if any error occurs, returned Parameters will be nil
+// all hostnames in one section are updated in one request, as dyndns2 accepts a hostname list
+func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
+	names := [5]string{"Username", "Password", "Hostname", "Value", "Type"}
+
+	p := Parameters{}
+	for _, name := range names {
+		if !sec.HasKey(name) {
+			return nil, core.NewMissKeyErr(name, serviceName)
+		} else {
+			switch name {
+			case "Hostname":
+				hostname := sec.Key(name).String()
+				hostnames := strings.Fields(strings.ReplaceAll(hostname, ",", " "))
+				collections.RemoveDuplicate(&hostnames)
+				if len(hostnames) == 0 {
+					return nil, core.NewMissKeyErr(name, serviceName)
+				}
+				p.Hostname = strings.Join(hostnames, ",")
+			case "Type":
+				p.Type = netutil.Type2Str(sec.Key(name).String())
+			default:
+				err := util.SetVariable(&p, name, sec.Key(name).String())
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// optional keys, empty if not exist
+	p.Server = sec.Key("Server").String()
+	p.Device = sec.Key("Device").String()
+	options, err := core.ReadDeviceOptions(sec)
+	if err != nil {
+		return nil, err
+	}
+	p.DeviceOptions = options
+
+	return []core.Parameters{&p}, nil
+}
+
+// GenerateConfigInfo
+// Generate KeyValue style config
+func (c Config) GenerateConfigInfo(parameters core.Parameters, No uint) (core.ConfigStr, error) {
+	buffer := bytes.NewBufferString(core.ConfigHead(parameters, No))
+	buffer.WriteString(util.Convert2KeyValue(core.Format, parameters))
+	buffer.Write([]byte{'\n', '\n'})
+
+	return core.ConfigStr{
+		Name:    serviceName,
+		Content: buffer.String(),
+	}, nil
+}
@@ -0,0 +1,42 @@
+package dyndns2
+
+import (
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestConfig_GenerateDefaultConfigInfo(t *testing.T) {
+	config, err := Config{}.GenerateDefaultConfigInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(config.Content)
+}
+
+func TestConfig_ReadConfig(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[DynDNS2]
+Username=user
+Password=pass
+Hostname=a.example.com, b.example.com,a.example.com
+Value=1.2.3.4
+Type=4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("DynDNS2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 {
+		t.Fatalf("all hostnames should be updated in one request, got %d", len(ps))
+	}
+	p := ps[0].(*Parameters)
+	if p.Type != "A" || p.getServer() != DefaultServer || len(p.getHostnames()) != 2 {
+		t.Errorf("unexpected parameters %+v", p)
+	}
+	t.Log(p.Target())
+}
@@ -0,0 +1,106 @@
+// Package dyndns2 use dyndns2 protocol(members.dyndns.org style /nic/update) to update DNS record
+package dyndns2
+
+import (
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+)
+
+const serviceName = "DynDNS2"
+
+// DefaultServer is the server of Dyn
+const DefaultServer = "https://members.dyndns.org"
+
+// Parameters implements DeviceOverridable
+// Hostname is a list of hostnames separated by ',' which are updated in one request
+// Device is Device name when overriding ip with specific Device/interface
+type Parameters struct {
+	Server   string `KeyValue:"Server,server speaking dyndns2 like https://members.dyndns.org(default)"`
+	Username string `KeyValue:"Username,username of basic auth"`
+	Password string `KeyValue:"Password,password or update key of basic auth"`
+	Hostname string `KeyValue:"Hostname,hostnames to update like www.example.com, if you have multiple hostnames to update, set like Hostname=www.example.com,ftp.example.com"`
+	Value    string `KeyValue:"Value,IP address like 6.6.6.6"`
+	Type     string `KeyValue:"Type,A/AAAA/4/6"`
+	Device   string `KeyValue:"Device,device/net interface name"`
+	core.DeviceOptions
+}
+
+// GenerateDefaultConfigInfo return Default config
+func GenerateDefaultConfigInfo() Parameters {
+	return Parameters{
+		Server:   DefaultServer,
+		Username: "Username",
+		Password: "Password",
+		Hostname: "www.example.com,ftp.example.com...",
+		Value:    "1.2.3.4",
+		Type:     "A/AAAA/4/6",
+		Device:   "your device/net interface name",
+	}
+}
+
+// GetName return "DynDNS2"
+func (p *Parameters) GetName() string {
+	return serviceName
+}
+
+// SaveConfig return core.ConfigStr
+func (p *Parameters) SaveConfig(No uint) (core.ConfigStr, error) {
+	return configInstance.GenerateConfigInfo(p, No)
+}
+
+// Target return Hostname
+func (p *Parameters) Target() string {
+	return p.Hostname
+}
+
+// ToRequest Convert to core.Request
+func (p *Parameters) ToRequest() (core.Request, error) {
+	r := new(Request)
+	r.Init(*p)
+	return r, nil
+}
+
+// SetValue set ip
+func (p *Parameters) SetValue(value string) {
+	p.Value = value
+}
+
+// GetIP return ip value
+func (p *Parameters) GetIP() string {
+	return p.Value
+}
+
+// GetType return Type like "4" or "6" and "" if invalid type
+func (p *Parameters) GetType() string {
+	return netutil.Type2Num(p.Type)
+}
+
+// IsTypeSet return whether the type is set correctly
+func (p *Parameters) IsTypeSet() bool {
+	return p.Type == "A" || p.Type == "AAAA"
+}
+
+// GetDevice return Device name
+func (p *Parameters) GetDevice() string {
+	return p.Device
+}
+
+// IsDeviceSet return whether the Device is set
+func (p *Parameters) IsDeviceSet() bool {
+	return p.Device != ""
+}
+
+// getServer return Server without trailing '/' or DefaultServer if not set
+func (p *Parameters) getServer() string {
+	if p.Server == "" {
+		return DefaultServer
+	}
+	return strings.TrimSuffix(p.Server, "/")
+}
+
+// getHostnames return the list of hostnames
+func (p *Parameters) getHostnames() []string {
+	return strings.Split(p.Hostname, ",")
+}
@@ -0,0 +1,212 @@
+package dyndns2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	"GodDns/netutil"
+	"github.com/go-resty/resty/v2"
+)
+
+const requestTimeout = 20 * time.Second
+
+// userAgent is required by dyndns2, clients without it get "badagent"
+var userAgent = fmt.Sprintf("%s - %s - %s", core.Author, core.FullName, core.NowVersion)
+
+// usage
+// r:=dyndns2.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request, core.ThroughProxy and core.BackOff
+type Request struct {
+	parameters Parameters
+	status     core.Status
+	// rejected is true if the server answers a code which must not be retried, like "badauth"
+	rejected bool
+}
+
+// Init set parameter
+func (r *Request) Init(parameters Parameters) {
+	r.parameters = parameters
+}
+
+// Target return hostnames
+func (r *Request) Target() string {
+	return r.parameters.Target()
+}
+
+// GetName return "DynDNS2"
+func (r *Request) GetName() string {
+	return serviceName
+}
+
+// ToParameters return core.Service
+func (r *Request) ToParameters() core.Service {
+	return &r.parameters
+}
+
+// Status return core.Status which contains execution result etc.
+func (r *Request) Status() core.Status {
+	return r.status
+}
+
+// RetryAfter return how long to wait before the next attempt after "abuse" or "911",
+// the back off is kept in core.State so that it is still respected after restart
+func (r *Request) RetryAfter() time.Duration {
+	if d := time.Until(core.GetState().BackOffUntil(r.backOffKey())); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Retryable return false after "badauth" or other codes which need the config to be fixed
+func (r *Request) Retryable() bool {
+	return !r.rejected
+}
+
+// backOffKey return the key of the account on the server like "DynDNS2:https://members.dyndns.org|user"
+func (r *Request) backOffKey() string {
+	return serviceName + ":" + r.parameters.getServer() + "|" + r.parameters.Username
+}
+
+func (r *Request) backOff(d time.Duration) {
+	core.GetState().SetBackOff(r.backOffKey(), time.Now().Add(d))
+}
+
+func newStatus() *core.Status {
+	return &core.Status{
+		Name:   serviceName,
+		Status: core.NotExecute,
+		MG:     core.NewDefaultMsgGroup(),
+	}
+}
+
+// MakeRequest GET /nic/update?hostname=...&myip=... with basic auth
+func (r *Request) MakeRequest() error {
+	client := core.MainClientPool.Get().(*resty.Client)
+	defer core.MainClientPool.Put(client)
+	return r.execute(client)
+}
+
+// RequestThroughProxy make request through the proxies in netutil.GlobalProxies one by one until one succeeds
+func (r *Request) RequestThroughProxy() error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	iter := netutil.GlobalProxies.GetProxyIter()
+	if iter.Len() == 0 {
+		return r.fail(errors.New("no proxy available"))
+	}
+
+	var err error
+	for iter.NotLast() {
+		proxy := iter.Next()
+		// never set proxy on a pooled client
+		client := core.MainClientPool.New().(*resty.Client).SetProxy(proxy)
+		err = r.execute(client)
+		// the server has answered, other proxies will get the same answer
+		if err == nil || r.rejected || r.RetryAfter() > 0 {
+			return err
+		}
+		errMsg := fmt.Sprintf("request error through proxy %s: %v", proxy, err)
+		r.status.MG.AddError(errMsg)
+		log.Errorf(errMsg)
+	}
+	return err
+}
+
+func (r *Request) execute(client *resty.Client) error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	// the server asks not to update before the time
+	if d := r.RetryAfter(); d > 0 {
+		return r.fail(fmt.Errorf("backing off for %s before updating %s again", d.Round(time.Second), r.Target()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := client.R().
+		SetContext(ctx).
+		SetBasicAuth(r.parameters.Username, r.parameters.Password).
+		SetHeader("User-Agent", userAgent).
+		SetQueryParams(map[string]string{
+			"hostname": r.parameters.Hostname,
+			"myip":     r.parameters.Value,
+		}).
+		Get(r.parameters.getServer() + "/nic/update")
+	if err != nil {
+		return r.fail(err)
+	}
+	log.Debugf("response: %s", resp.String())
+
+	body := strings.TrimSpace(resp.String())
+	if body == "" {
+		return r.fail(fmt.Errorf("empty response with status code %d", resp.StatusCode()))
+	}
+
+	return r.handleResponse(body)
+}
+
+// handleResponse parse the return code of each hostname, one line per hostname in the order of request
+// like:
+// good 1.2.3.4
+// nochg 1.2.3.4
+func (r *Request) handleResponse(body string) error {
+	lines := strings.Split(body, "\n")
+	hostnames := r.parameters.getHostnames()
+	// some servers answer one line for all hostnames
+	if len(lines) != len(hostnames) {
+		hostnames = []string{r.Target()}
+		lines = lines[:1]
+	}
+
+	r.status.Status = core.Success
+	var backOff time.Duration
+	var failed []string
+	for i, line := range lines {
+		code, ip, _ := strings.Cut(strings.TrimSpace(line), " ")
+		status := code2status(code)
+		for _, msg := range status.MG.GetMsgOf(core.Info) {
+			r.status.MG.AddInfo(strings.TrimSpace(fmt.Sprintf("%s: %s %s", hostnames[i], msg, ip)))
+		}
+		for _, msg := range status.MG.GetMsgOf(core.Error) {
+			r.status.MG.AddError(fmt.Sprintf("%s: %s", hostnames[i], msg))
+		}
+		if status.Status != core.Success {
+			r.status.Status = core.Failed
+			failed = append(failed, code)
+		}
+		if d := backOffOf(code); d > backOff {
+			backOff = d
+		}
+		if !retryable(code) {
+			r.rejected = true
+		}
+	}
+
+	if backOff > 0 {
+		r.backOff(backOff)
+		r.status.MG.AddWarn(fmt.Sprintf("backing off for %s before updating %s again", backOff, r.Target()))
+	}
+	if r.status.Status != core.Success {
+		return fmt.Errorf("return code:%s", strings.Join(failed, ","))
+	}
+	return nil
+}
+
+// fail set status to Failed with err and return err
+func (r *Request) fail(err error) error {
+	r.status.Status = core.Failed
+	r.status.MG.AddError(err.Error())
+	return err
+}
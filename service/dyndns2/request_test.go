@@ -0,0 +1,133 @@
+package dyndns2
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"GodDns/core"
+)
+
+// newTestServer answer the body to requests with correct auth and user agent
+func newTestServer(t *testing.T, body string, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		if r.URL.Path != "/nic/update" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("User-Agent") != userAgent {
+			_, _ = io.WriteString(w, BadAgent)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, BadAuth)
+			return
+		}
+		if r.URL.Query().Get("myip") != "1.2.3.4" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		_, _ = io.WriteString(w, body)
+	}))
+}
+
+func newTestRequest(server string, password string) core.Request {
+	p := Parameters{
+		Server:   server,
+		Username: "user",
+		Password: password,
+		Hostname: "a.example.com,b.example.com",
+		Value:    "1.2.3.4",
+		Type:     "A",
+	}
+	r, _ := p.ToRequest()
+	return r
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	var hits int32
+	server := newTestServer(t, "good 1.2.3.4\nnochg 1.2.3.4\n", &hits)
+	defer server.Close()
+
+	r := newTestRequest(server.URL, "pass")
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d", r.Status().Status)
+	}
+	info := r.Status().MG.GetMsgOf(core.Info)
+	if len(info) != 2 || !strings.HasPrefix(info[0], "a.example.com") || !strings.HasPrefix(info[1], "b.example.com") {
+		t.Errorf("unexpected messages %v", info)
+	}
+	t.Log(info)
+}
+
+func TestRequest_MakeRequestBadAuth(t *testing.T) {
+	var hits int32
+	server := newTestServer(t, "good 1.2.3.4", &hits)
+	defer server.Close()
+
+	r := newTestRequest(server.URL, "wrong")
+	if r.MakeRequest() == nil {
+		t.Fatal("should return error")
+	}
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+	if r.(core.BackOff).RetryAfter() != 0 {
+		t.Error("badauth should not back off")
+	}
+	if r.(core.BackOff).Retryable() {
+		t.Error("badauth should not be retried")
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Error))
+}
+
+func TestRequest_MakeRequestBackOff(t *testing.T) {
+	for _, code := range []string{ServerError, Abuse} {
+		var hits int32
+		server := newTestServer(t, code, &hits)
+
+		r := newTestRequest(server.URL, "pass")
+		if r.MakeRequest() == nil {
+			t.Fatal("should return error")
+		}
+		if r.(core.BackOff).RetryAfter() <= 0 {
+			t.Errorf("%s should back off", code)
+		}
+		if core.GetState().BackOffUntil(r.(*Request).backOffKey()).IsZero() {
+			t.Errorf("back off of %s should be kept in state", code)
+		}
+
+		// the server must not be hit again during backing off
+		r = newTestRequest(server.URL, "pass")
+		if r.MakeRequest() == nil {
+			t.Fatal("should return error")
+		}
+		if r.Status().Status != core.Failed {
+			t.Errorf("status should be failed, got %d", r.Status().Status)
+		}
+		if atomic.LoadInt32(&hits) != 1 {
+			t.Errorf("server should be hit once, got %d", hits)
+		}
+		t.Log(r.Status().MG.GetMsgOf(core.Error))
+		server.Close()
+	}
+}
+
+func TestCode2status(t *testing.T) {
+	for _, code := range []string{Good, NoChg} {
+		if code2status(code).Status != core.Success {
+			t.Errorf("%s should be mapped to core.Success", code)
+		}
+	}
+	for _, code := range []string{BadAuth, NotDonator, NotFQDN, NoHost, NumHost, Abuse, BadAgent, DNSErr, ServerError, "unknown"} {
+		if code2status(code).Status != core.Failed {
+			t.Errorf("%s should be mapped to core.Failed", code)
+		}
+	}
+}
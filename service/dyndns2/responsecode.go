@@ -0,0 +1,86 @@
+package dyndns2
+
+import (
+	"time"
+
+	"GodDns/core"
+)
+
+// return codes of dyndns2, see https://help.dyn.com/remote-access-api/return-codes/
+const (
+	Good        = "good"
+	NoChg       = "nochg"
+	BadAuth     = "badauth"
+	NotDonator  = "!donator"
+	NotFQDN     = "notfqdn"
+	NoHost      = "nohost"
+	NumHost     = "numhost"
+	Abuse       = "abuse"
+	BadAgent    = "badagent"
+	DNSErr      = "dnserr"
+	ServerError = "911"
+)
+
+// code2status
+// convert the code to message and set status.Status
+func code2status(code string) *core.Status {
+	msg := newStatus()
+	switch code {
+	case Good:
+		msg.MG.AddInfo("update succeeded")
+	case NoChg:
+		msg.MG.AddInfo("no change, the hostname already has the ip")
+	case BadAuth:
+		msg.MG.AddError("username or password is incorrect")
+	case NotDonator:
+		msg.MG.AddError("the feature is only available to credited users")
+	case NotFQDN:
+		msg.MG.AddError("the hostname is not a fully-qualified domain name")
+	case NoHost:
+		msg.MG.AddError("the hostname does not exist in this user account")
+	case NumHost:
+		msg.MG.AddError("too many hostnames in one request")
+	case Abuse:
+		msg.MG.AddError("the hostname is blocked for update abuse")
+	case BadAgent:
+		msg.MG.AddError("the user agent was not sent or the http method is not permitted")
+	case DNSErr:
+		msg.MG.AddError("DNS error encountered by the server")
+	case ServerError:
+		msg.MG.AddError("there is a problem or scheduled maintenance on the server")
+	default:
+		msg.MG.AddError("unknown return code " + code)
+	}
+
+	if code == Good || code == NoChg {
+		msg.Status = core.Success
+	} else {
+		msg.Status = core.Failed
+	}
+
+	return msg
+}
+
+// backOffOf return how long to wait before next update after the code
+// the client must not retry immediately after "911", and "abuse" needs the user to unblock the hostname
+func backOffOf(code string) time.Duration {
+	switch code {
+	case ServerError:
+		return 30 * time.Minute
+	case Abuse:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// retryable return false if the code means the request is wrong and must not be sent again until the config is fixed,
+// the client must stop after "badauth" etc. or the hostname may be blocked for abuse
+func retryable(code string) bool {
+	switch code {
+	case BadAuth, NotDonator, NotFQDN, NoHost, NumHost, Abuse, BadAgent:
+		return false
+	default:
+		return true
+	}
+}
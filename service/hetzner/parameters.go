@@ -0,0 +1,32 @@
+// Package hetzner use Hetzner DNS API to update DNS record
+package hetzner
+
+import (
+	"GodDns/service/internal/restrecord"
+)
+
+const serviceName = "Hetzner"
+
+// DefaultEndpoint is the base url of Hetzner DNS API
+const DefaultEndpoint = "https://dns.hetzner.com/api/v1"
+
+// Parameters implements DeviceOverridable, Filterable and SuffixComposable
+type Parameters = restrecord.Parameters[spec]
+
+// spec implements restrecord.Spec, the zone apex is "@" in the API
+type spec struct{}
+
+func (spec) Info() restrecord.Info {
+	return restrecord.Info{
+		Name:            serviceName,
+		DefaultEndpoint: DefaultEndpoint,
+		Apex:            "@",
+		DefaultTTL:      300,
+		TokenComment:    "API token, get from https://dns.hetzner.com/settings/api-token",
+		TTLComment:      "Time-To-Live, 0 means the default TTL of the zone",
+	}
+}
+
+func (spec) NewProvider(s restrecord.Settings) restrecord.Provider {
+	return provider{s}
+}
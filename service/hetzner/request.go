@@ -0,0 +1,147 @@
+package hetzner
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"GodDns/service/internal/restrecord"
+	json "GodDns/util/json"
+)
+
+// usage
+// r:=hetzner.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request and core.ThroughProxy
+type Request = restrecord.Request[spec]
+
+// provider implements restrecord.Provider
+// the token is sent in header Auth-API-Token, records can only be listed by zone
+type provider struct {
+	restrecord.Settings
+}
+
+func (p provider) Auth() restrecord.TokenAuth {
+	return restrecord.TokenAuth{Token: p.Token, Header: "Auth-API-Token"}
+}
+
+func (p provider) ZoneId(c *restrecord.Client) (string, error) {
+	res := &zonesResponse{}
+	req := c.R().SetQueryParam("name", p.Domain)
+	if err := c.Do(req, http.MethodGet, p.Endpoint+"/zones", res); err != nil {
+		return "", err
+	}
+	for _, z := range res.Zones {
+		if strings.EqualFold(z.Name, p.Domain) {
+			return z.Id, nil
+		}
+	}
+	return "", errors.New("no zone found")
+}
+
+func (p provider) GetRecord(c *restrecord.Client, _ string, id string) (restrecord.Record, error) {
+	res := &recordResponse{}
+	if err := c.Do(c.R(), http.MethodGet, p.Endpoint+"/records/"+id, res); err != nil {
+		return restrecord.Record{}, err
+	}
+	return res.Record.toRecord(), nil
+}
+
+func (p provider) ListRecords(c *restrecord.Client, zoneId string, page int) ([]restrecord.Record, bool, error) {
+	res := &recordsResponse{}
+	req := c.R().SetQueryParams(map[string]string{
+		"zone_id":  zoneId,
+		"page":     strconv.Itoa(page),
+		"per_page": strconv.Itoa(restrecord.PerPage),
+	})
+	if err := c.Do(req, http.MethodGet, p.Endpoint+"/records", res); err != nil {
+		return nil, false, err
+	}
+
+	records := make([]restrecord.Record, 0, len(res.Records))
+	for _, record := range res.Records {
+		records = append(records, record.toRecord())
+	}
+	return records, res.Meta.Pagination.Page < res.Meta.Pagination.LastPage, nil
+}
+
+func (p provider) UpdateRecord(c *restrecord.Client, zoneId string, record restrecord.Record) error {
+	req := c.R().SetBody(dnsRecord{
+		ZoneId: zoneId,
+		Type:   record.Type,
+		Name:   record.Name,
+		Value:  record.Value,
+		TTL:    record.TTL,
+	})
+	return c.Do(req, http.MethodPut, p.Endpoint+"/records/"+record.Id, nil)
+}
+
+func (p provider) ErrorMessage(body []byte) string {
+	res := &errorResponse{}
+	if err := json.Unmarshal(body, res); err != nil {
+		return ""
+	}
+	if res.Error.Message != "" {
+		return res.Error.Message
+	}
+	return res.Message
+}
+
+type zone struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type dnsRecord struct {
+	Id     string `json:"id,omitempty"`
+	ZoneId string `json:"zone_id"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	TTL    uint64 `json:"ttl,omitempty"`
+}
+
+func (d dnsRecord) toRecord() restrecord.Record {
+	return restrecord.Record{
+		Id:    d.Id,
+		Name:  d.Name,
+		Type:  d.Type,
+		Value: d.Value,
+		TTL:   d.TTL,
+	}
+}
+
+type meta struct {
+	Pagination struct {
+		Page         int `json:"page"`
+		PerPage      int `json:"per_page"`
+		LastPage     int `json:"last_page"`
+		TotalEntries int `json:"total_entries"`
+	} `json:"pagination"`
+}
+
+type zonesResponse struct {
+	Zones []zone `json:"zones"`
+	Meta  meta   `json:"meta"`
+}
+
+type recordResponse struct {
+	Record dnsRecord `json:"record"`
+}
+
+type recordsResponse struct {
+	Records []dnsRecord `json:"records"`
+	Meta    meta        `json:"meta"`
+}
+
+// errorResponse is like {"error":{"message":"record not found","code":404}} or {"message":"Invalid authentication credentials"}
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,192 @@
+package hetzner
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"GodDns/core"
+)
+
+// fakeHetzner serve records of zone example.com with id zone1, two records per page
+type fakeHetzner struct {
+	t       *testing.T
+	mu      sync.Mutex
+	records []dnsRecord
+	lists   int
+}
+
+func (f *fakeHetzner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("Auth-API-Token") != "TOKEN" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"message":"Invalid authentication credentials"}`)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/zones":
+		if r.URL.Query().Get("name") != "example.com" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"zones":[],"error":{"message":"zone not found","code":404}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"zones":[{"id":"zone1","name":"example.com"}],"meta":{"pagination":{"page":1,"per_page":100,"last_page":1,"total_entries":1}}}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/records/r5":
+		data, _ := json.Marshal(recordResponse{Record: f.records[4]})
+		_, _ = w.Write(data)
+	case r.Method == http.MethodGet && r.URL.Path == "/records":
+		f.lists++
+		if r.URL.Query().Get("zone_id") != "zone1" {
+			f.t.Errorf("unexpected zone id %s", r.URL.Query().Get("zone_id"))
+		}
+		n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		end := n * 2
+		if end > len(f.records) {
+			end = len(f.records)
+		}
+		res := recordsResponse{Records: f.records[(n-1)*2 : end]}
+		res.Meta.Pagination.Page = n
+		res.Meta.Pagination.LastPage = (len(f.records) + 1) / 2
+		data, _ := json.Marshal(res)
+		_, _ = w.Write(data)
+	case r.Method == http.MethodPut && r.URL.Path == "/records/r5":
+		record := dnsRecord{}
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			f.t.Error(err)
+		}
+		if record.ZoneId != "zone1" || record.Name != "ftp" {
+			f.t.Errorf("unexpected record %+v", record)
+		}
+		record.Id = "r5"
+		f.records[4] = record
+		data, _ := json.Marshal(map[string]any{"record": record})
+		_, _ = w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"record":{},"error":{"message":"record not found","code":404}}`)
+	}
+}
+
+func newTestServer(t *testing.T) (*fakeHetzner, *httptest.Server) {
+	f := &fakeHetzner{t: t, records: []dnsRecord{
+		{Id: "r1", ZoneId: "zone1", Type: "A", Name: "@", Value: "1.1.1.1", TTL: 300},
+		{Id: "r2", ZoneId: "zone1", Type: "NS", Name: "@", Value: "ns1.example.com.", TTL: 86400},
+		{Id: "r3", ZoneId: "zone1", Type: "A", Name: "www", Value: "1.1.1.1"},
+		{Id: "r4", ZoneId: "zone1", Type: "AAAA", Name: "ftp", Value: "::1"},
+		{Id: "r5", ZoneId: "zone1", Type: "A", Name: "ftp", Value: "1.1.1.1"},
+	}}
+	return f, httptest.NewServer(f)
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	f, server := newTestServer(t)
+	defer server.Close()
+
+	// the record is on the last page
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "ftp",
+		Value:     "2.2.2.2",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d: %v", r.Status().Status, r.Status().MG.GetMsgOf(core.Error))
+	}
+	if f.records[4].Value != "2.2.2.2" {
+		t.Errorf("unexpected record %+v", f.records[4])
+	}
+}
+
+func TestRequest_MakeRequestUnchanged(t *testing.T) {
+	_, server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "@",
+		Value:     "1.1.1.1",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Unchanged {
+		t.Errorf("status should be unchanged, got %d", r.Status().Status)
+	}
+}
+
+func TestRequest_MakeRequestWithBadToken(t *testing.T) {
+	_, server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "BAD TOKEN",
+		Domain:    "example.com",
+		Subdomain: "www",
+		Value:     "2.2.2.2",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	err := r.MakeRequest()
+	if err == nil {
+		t.Fatal("should return error")
+	}
+	t.Log(err)
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+}
+
+func TestRequest_MakeRequestWithRecordId(t *testing.T) {
+	f, server := newTestServer(t)
+	defer server.Close()
+
+	// the record is got by the id restored from State and left as it is
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "ftp",
+		Value:     "1.1.1.1",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	p.SetRecordId("zone1/r5")
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Unchanged || f.lists != 0 {
+		t.Errorf("expect unchanged without listing records, got status %d and %d list requests", r.Status().Status, f.lists)
+	}
+
+	// the stale id is looked up again
+	p.SetRecordId("zone1/r9")
+	p.Value = "2.2.2.2"
+	r, _ = p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success || f.records[4].Value != "2.2.2.2" {
+		t.Errorf("status should be success, got %d, record %+v", r.Status().Status, f.records[4])
+	}
+	if id := r.ToParameters().(*Parameters).GetRecordId(); id != "zone1/r5" {
+		t.Errorf("expect record id zone1/r5 looked up, got %s", id)
+	}
+}
@@ -0,0 +1,113 @@
+package restrecord
+
+import (
+	"bytes"
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util"
+	"GodDns/util/collections"
+	"gopkg.in/ini.v1"
+)
+
+// ConfigFactory is a factory that create a new Config
+type ConfigFactory[S Spec] struct{}
+
+// GetName return the name of provider
+func (c ConfigFactory[S]) GetName() string {
+	return info[S]().Name
+}
+
+// Get return a Config, Config is stateless so that a new one is as good as a singleton
+func (c ConfigFactory[S]) Get() core.Config {
+	return &Config[S]{}
+}
+
+// New return a new Config
+func (c ConfigFactory[S]) New() *core.Config {
+	var config core.Config = &Config[S]{}
+	return &config
+}
+
+// Config implements core.Config
+type Config[S Spec] struct{}
+
+// GetName Get name of service
+func (c Config[S]) GetName() string {
+	return info[S]().Name
+}
+
+// GenerateDefaultConfigInfo Create default config
+func (c Config[S]) GenerateDefaultConfigInfo() (core.ConfigStr, error) {
+	p := GenerateDefaultConfigInfo[S]()
+	return c.GenerateConfigInfo(&p, 0)
+}
+
+// ReadConfig Read config file
+// Parameters: sec ini.Section
+// Return: core.Parameters and error
+// if any error occurs, returned Parameters will be nil
+func (c Config[S]) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
+	names := [6]string{"Token", "Domain", "Subdomain", "Value", "TTL", "Type"}
+
+	p := Parameters[S]{}
+	var subdomains []string
+	for _, name := range names {
+		if !sec.HasKey(name) {
+			return nil, core.NewMissKeyErr(name, c.GetName())
+		} else {
+			switch name {
+			case "Subdomain":
+				subdomain := sec.Key(name).String()
+				subdomains = collections.AppendUnique(nil, strings.Fields(strings.ReplaceAll(subdomain, ",", " "))...)
+			case "TTL":
+				ttl, err := sec.Key(name).Uint64()
+				if err != nil {
+					return nil, err
+				}
+				p.TTL = ttl
+			case "Type":
+				p.Type = netutil.Type2Str(sec.Key(name).String())
+			default:
+				err := util.SetVariable(&p, name, sec.Key(name).String())
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// optional keys, empty if not exist
+	p.Endpoint = sec.Key("Endpoint").String()
+	p.Device = sec.Key("Device").String()
+	options, err := core.ReadDeviceOptions(sec)
+	if err != nil {
+		return nil, err
+	}
+	p.DeviceOptions = options
+
+	ps := make([]core.Parameters, 0, len(subdomains))
+	for _, subdomain := range subdomains {
+		if subdomain == "" {
+			continue
+		}
+		sp := p
+		sp.Subdomain = subdomain
+		ps = append(ps, &sp)
+	}
+	return ps, nil
+}
+
+// GenerateConfigInfo
+// Generate KeyValue style config
+func (c Config[S]) GenerateConfigInfo(parameters core.Parameters, No uint) (core.ConfigStr, error) {
+	buffer := bytes.NewBufferString(core.ConfigHead(parameters, No))
+	buffer.WriteString(util.Convert2KeyValue(core.Format, parameters))
+	buffer.Write([]byte{'\n', '\n'})
+
+	return core.ConfigStr{
+		Name:    c.GetName(),
+		Content: buffer.String(),
+	}, nil
+}
@@ -0,0 +1,255 @@
+package restrecord
+
+import (
+	"strings"
+	"testing"
+
+	"GodDns/core"
+	"gopkg.in/ini.v1"
+)
+
+// atSpec is a provider naming the apex "@"
+type atSpec struct{}
+
+func (atSpec) Info() Info {
+	return Info{
+		Name:            "FakeAt",
+		DefaultEndpoint: "https://at.example.com/v1",
+		Apex:            "@",
+		DefaultTTL:      300,
+		TokenComment:    "token of FakeAt",
+		TTLComment:      "Time-To-Live of FakeAt",
+	}
+}
+
+func (atSpec) NewProvider(Settings) Provider { return newFakeProvider("") }
+
+// emptySpec is a provider naming the apex ""
+type emptySpec struct{}
+
+func (emptySpec) Info() Info {
+	return Info{Name: "FakeEmpty", DefaultEndpoint: "https://empty.example.com/v4", Apex: ""}
+}
+
+func (emptySpec) NewProvider(Settings) Provider { return newFakeProvider("") }
+
+func TestConfig_GenerateDefaultConfigInfo(t *testing.T) {
+	config, err := Config[atSpec]{}.GenerateDefaultConfigInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	for _, want := range []string{
+		"[FakeAt]",
+		"# token of FakeAt\nToken=Token\n",
+		"# Time-To-Live of FakeAt\nTTL=300\n",
+		"# domain name like example.com\nDomain=example.com\n",
+	} {
+		if !strings.Contains(config.Content, want) {
+			t.Errorf("expect %q in config:\n%s", want, config.Content)
+		}
+	}
+	t.Log(config.Content)
+
+	p := &Parameters[atSpec]{Token: "TOKEN", Endpoint: "https://proxy.example.com/"}
+	if content := p.Convert2KeyValue(core.Format); !strings.Contains(content, "# API base url, https://at.example.com/v1(default)\nEndpoint=https://proxy.example.com/\n") {
+		t.Errorf("unexpected config:\n%s", content)
+	}
+}
+
+func TestConfig_ReadConfig(t *testing.T) {
+	const section = `
+Token=TOKEN
+Domain=example.com
+Subdomain=www,@,www,mail
+Value=1.2.3.4
+TTL=300
+Type=4
+`
+	tests := []struct {
+		name    string
+		read    func(sec ini.Section) ([]core.Parameters, error)
+		targets []string
+		names   []string
+	}{
+		{
+			name:    "apex is @",
+			read:    Config[atSpec]{}.ReadConfig,
+			targets: []string{"www.example.com", "example.com", "mail.example.com"},
+			names:   []string{"www", "@", "mail"},
+		},
+		{
+			name:    "apex is empty",
+			read:    Config[emptySpec]{}.ReadConfig,
+			targets: []string{"www.example.com", "example.com", "mail.example.com"},
+			names:   []string{"www", "", "mail"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ini.Load([]byte("[Fake]" + section))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ps, err := tt.read(*cfg.Section("Fake"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ps) != len(tt.targets) {
+				t.Fatalf("expect %d parameters, got %d", len(tt.targets), len(ps))
+			}
+			for i, p := range ps {
+				s := p.(interface{ Settings() Settings }).Settings()
+				if s.Target != tt.targets[i] {
+					t.Errorf("expect target %s, got %s", tt.targets[i], s.Target)
+				}
+				if s.Name != tt.names[i] || s.Type != "A" || s.Token != "TOKEN" || s.Endpoint == "" {
+					t.Errorf("unexpected settings %+v", s)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_ReadConfigMissingKey(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[FakeAt]
+Token=TOKEN
+Subdomain=www
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (Config[atSpec]{}).ReadConfig(*cfg.Section("FakeAt")); err == nil {
+		t.Error("should return error when key is missing")
+	}
+}
+
+func TestConfig_ReadConfigIPv6Suffix(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[FakeAt]
+Token=TOKEN
+Domain=example.com
+Subdomain=nas,printer
+Value=::
+TTL=300
+Type=AAAA
+IPv6Suffix=::1:2:3:4
+PrefixLength=56
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config[atSpec]{}.ReadConfig(*cfg.Section("FakeAt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range ps {
+		p := p.(*Parameters[atSpec])
+		if p.GetIPv6Suffix() != "::1:2:3:4" || p.GetPrefixLength() != 56 {
+			t.Errorf("unexpected parameters %+v", p)
+		}
+		if err := core.SetValue(p, "2001:db8:1:ff::1"); err != nil {
+			t.Fatal(err)
+		}
+		if p.Value != "2001:db8:1:0:1:2:3:4" {
+			t.Errorf("expect 2001:db8:1:0:1:2:3:4, got %s", p.Value)
+		}
+	}
+
+	sec := cfg.Section("FakeAt")
+	sec.Key("PrefixLength").SetValue("129")
+	if _, err := (Config[atSpec]{}).ReadConfig(*sec); err == nil {
+		t.Error("expect error with PrefixLength 129")
+	}
+}
+
+func TestConfig_SaveMerged(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[FakeAt]
+Token=TOKEN
+Domain=example.com
+Subdomain=www,@,mail
+Value=1.2.3.4
+TTL=300
+Type=A
+Filter=remove-private
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := Config[atSpec]{}.ReadConfig(*cfg.Section("FakeAt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps[0].(*Parameters[atSpec]).SetRecordId("zone1/r1")
+
+	// saved back as a single section, ids are kept in State only
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("expect 1 merged parameters, got %d", len(merged))
+	}
+	saved, err := merged[0].SaveConfig(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(saved.Content, "Subdomain=www,@,mail\n") || strings.Contains(saved.Content, "zone1") {
+		t.Errorf("unexpected config:\n%s", saved.Content)
+	}
+
+	cfg, err = ini.Load([]byte(saved.Content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Config[atSpec]{}.ReadConfig(*cfg.Section("FakeAt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != len(ps) {
+		t.Fatalf("expect %d parameters read again, got %d", len(ps), len(again))
+	}
+	for i := range again {
+		p, q := *ps[i].(*Parameters[atSpec]), *again[i].(*Parameters[atSpec])
+		p.cached = cachedRecord{}
+		if p != q {
+			t.Errorf("expect %+v, got %+v", p, q)
+		}
+	}
+}
+
+func TestParameters_RecordId(t *testing.T) {
+	p := &Parameters[atSpec]{}
+	if p.GetRecordId() != "" {
+		t.Errorf("expect no record id, got %s", p.GetRecordId())
+	}
+	p.SetRecordId("example.com/12345")
+	if p.GetRecordId() != "example.com/12345" || p.cached != (cachedRecord{zoneId: "example.com", recordId: "12345"}) {
+		t.Errorf("unexpected ids %+v", p.cached)
+	}
+	p.SetRecordId("12345")
+	if p.GetRecordId() != "" {
+		t.Errorf("id without zone should be dropped, got %s", p.GetRecordId())
+	}
+}
+
+func TestParameters_Type(t *testing.T) {
+	tests := []struct {
+		Type string
+		want string
+	}{
+		{"A", "A"},
+		{"4", "A"},
+		{"AAAA", "AAAA"},
+		{"6", "AAAA"},
+		{"A/AAAA/4/6", ""},
+	}
+	for _, tt := range tests {
+		p := &Parameters[atSpec]{Domain: "example.com", Type: tt.Type}
+		if p.IsTypeSet() != (tt.want != "") {
+			t.Errorf("IsTypeSet of %s should be %v", tt.Type, tt.want != "")
+		}
+		if s := p.Settings(); s.Type != tt.want {
+			t.Errorf("expect type %s of %s, got %s", tt.want, tt.Type, s.Type)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package restrecord
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util"
+	"GodDns/util/collections"
+)
+
+// Info is the static information of a provider
+type Info struct {
+	Name            string // name of service and config section
+	DefaultEndpoint string // API base url used if Endpoint is not set
+	Apex            string // record name of the apex in the form the API uses, "@" or ""
+	DefaultTTL      uint64 // TTL in the default config
+	TokenComment    string // comment of Token in config
+	TTLComment      string // comment of TTL in config
+}
+
+// Spec is implemented by an empty type of each provider, which is the type parameter of Parameters, Request and Config
+type Spec interface {
+	// Info return the static information of the provider
+	Info() Info
+	// NewProvider return the Provider updating the record of s
+	NewProvider(s Settings) Provider
+}
+
+// Settings is Parameters resolved for a Provider
+type Settings struct {
+	Token    string
+	Domain   string
+	Target   string // full record name like www.example.com
+	Name     string // record name in the form the API uses, Info.Apex for the apex
+	Type     string
+	Endpoint string // API base url without trailing '/'
+}
+
+// Parameters implements DeviceOverridable, Filterable, SuffixComposable, RecordCacheable and Mergeable
+// Token is an API token of the provider
+// Device is Device name when overriding ip with specific Device/interface
+type Parameters[S Spec] struct {
+	Token     string `KeyValue:"Token,API token"`
+	Domain    string `KeyValue:"Domain,domain name like example.com"`
+	Subdomain string `KeyValue:"Subdomain,record name like www, use @ for the domain apex, if you have multiple records to update, set like Subdomain=www,ftp,mail"`
+	Value     string `KeyValue:"Value,IP address like 6.6.6.6"`
+	TTL       uint64 `KeyValue:"TTL,Time-To-Live"`
+	Type      string `KeyValue:"Type,A/AAAA/4/6"`
+	Endpoint  string `KeyValue:"Endpoint,API base url"`
+	Device    string `KeyValue:"Device,device/net interface name"`
+	core.DeviceOptions
+
+	// cached is kept in State instead of config
+	cached cachedRecord
+}
+
+// recordIdSep separates zone id and record id in the id kept in State like "zone1/r5"
+const recordIdSep = "/"
+
+// info return the static information of S
+func info[S Spec]() Info {
+	var s S
+	return s.Info()
+}
+
+// GenerateDefaultConfigInfo return Default config
+func GenerateDefaultConfigInfo[S Spec]() Parameters[S] {
+	return Parameters[S]{
+		Token:     "Token",
+		Domain:    "example.com",
+		Subdomain: "www,mail,ftp...",
+		Value:     "1.2.3.4",
+		TTL:       info[S]().DefaultTTL,
+		Type:      "A/AAAA/4/6",
+		Device:    "your device/net interface name",
+	}
+}
+
+// GetName return the name of provider
+func (p *Parameters[S]) GetName() string {
+	return info[S]().Name
+}
+
+// SaveConfig return core.ConfigStr
+func (p *Parameters[S]) SaveConfig(No uint) (core.ConfigStr, error) {
+	return Config[S]{}.GenerateConfigInfo(p, No)
+}
+
+// Target return the full record name
+func (p *Parameters[S]) Target() string {
+	if p.Subdomain == "@" || p.Subdomain == "" {
+		return p.Domain
+	}
+	return p.Subdomain + "." + p.Domain
+}
+
+// ToRequest Convert to core.Request
+func (p *Parameters[S]) ToRequest() (core.Request, error) {
+	r := new(Request[S])
+	r.Init(*p)
+	return r, nil
+}
+
+// SetValue set ip
+func (p *Parameters[S]) SetValue(value string) {
+	p.Value = value
+}
+
+// GetIP return ip value
+func (p *Parameters[S]) GetIP() string {
+	return p.Value
+}
+
+// GetType return Type like "4" or "6" and "" if invalid type
+func (p *Parameters[S]) GetType() string {
+	return netutil.Type2Num(p.Type)
+}
+
+// IsTypeSet return whether the type is set correctly, "4" and "6" are accepted as "A" and "AAAA"
+func (p *Parameters[S]) IsTypeSet() bool {
+	return netutil.Type2Str(p.Type) != ""
+}
+
+// GetDevice return Device name
+func (p *Parameters[S]) GetDevice() string {
+	return p.Device
+}
+
+// IsDeviceSet return whether the Device is set
+func (p *Parameters[S]) IsDeviceSet() bool {
+	return p.Device != ""
+}
+
+// GetRecordId return the zone id and record id looked up like "zone1/r5", "" if they are not known
+func (p *Parameters[S]) GetRecordId() string {
+	if p.cached.recordId == "" {
+		return ""
+	}
+	return p.cached.zoneId + recordIdSep + p.cached.recordId
+}
+
+// SetRecordId set the ids like "zone1/r5" cached, a stale one is looked up again when updating
+func (p *Parameters[S]) SetRecordId(id string) {
+	zoneId, recordId, ok := strings.Cut(id, recordIdSep)
+	if !ok {
+		p.cached = cachedRecord{}
+		return
+	}
+	p.cached = cachedRecord{zoneId: zoneId, recordId: recordId}
+}
+
+// MergeableWith return true if the two Parameters differ only by Subdomain
+func (p *Parameters[S]) MergeableWith(other core.Parameters) bool {
+	o, ok := other.(*Parameters[S])
+	if !ok {
+		return false
+	}
+	return p.Token == o.Token && p.Domain == o.Domain && p.Value == o.Value && p.TTL == o.TTL && p.Type == o.Type &&
+		p.Endpoint == o.Endpoint && p.Device == o.Device && p.DeviceOptions == o.DeviceOptions
+}
+
+// Merge return a new Parameters whose Subdomain is like "www,ftp", ids cached are not merged as they are kept in State
+func (p *Parameters[S]) Merge(others ...core.Parameters) core.Parameters {
+	merged := *p
+	subdomains := []string{p.Subdomain}
+	for _, other := range others {
+		subdomains = collections.AppendUnique(subdomains, other.(*Parameters[S]).Subdomain)
+	}
+	merged.Subdomain = strings.Join(subdomains, ",")
+	merged.cached = cachedRecord{}
+	return &merged
+}
+
+// Settings return the parameters resolved for Provider
+// "@" and "" of Subdomain are the apex, Type is "A" or "AAAA", Endpoint is Info.DefaultEndpoint if not set
+func (p *Parameters[S]) Settings() Settings {
+	i := info[S]()
+	s := Settings{
+		Token:    p.Token,
+		Domain:   p.Domain,
+		Target:   p.Target(),
+		Name:     p.Subdomain,
+		Type:     netutil.Type2Str(p.Type),
+		Endpoint: strings.TrimSuffix(p.Endpoint, "/"),
+	}
+	if s.Name == "@" || s.Name == "" {
+		s.Name = i.Apex
+	}
+	if s.Endpoint == "" {
+		s.Endpoint = i.DefaultEndpoint
+	}
+	return s
+}
+
+// Convert2KeyValue implements util.ConvertableKeyValue, comments of Token, TTL and Endpoint are taken from Info
+func (p *Parameters[S]) Convert2KeyValue(format string) string {
+	i := info[S]()
+	comments := map[string]string{
+		"Token":    i.TokenComment,
+		"TTL":      i.TTLComment,
+		"Endpoint": "API base url, " + i.DefaultEndpoint + "(default)",
+	}
+
+	content := new(strings.Builder)
+	v := reflect.ValueOf(p).Elem()
+	t := v.Type()
+	for n := 0; n < t.NumField(); n++ {
+		if !t.Field(n).IsExported() || v.Field(n).IsZero() {
+			continue
+		}
+		if t.Field(n).Anonymous {
+			content.WriteString(util.Convert2KeyValue(format, v.Field(n).Interface()))
+			continue
+		}
+		name, comment, _ := strings.Cut(t.Field(n).Tag.Get("KeyValue"), ",")
+		if c := comments[name]; c != "" {
+			comment = c
+		}
+		content.WriteString(fmt.Sprintf("# %s", comment))
+		content.WriteByte('\n')
+		content.WriteString(fmt.Sprintf(format, name, v.Field(n).Interface()))
+		content.WriteByte('\n')
+	}
+	return content.String()
+}
@@ -0,0 +1,80 @@
+// Package restrecord is the shared base of REST DNS providers which
+// list records by name and type and then PUT the new value, authenticating with a token.
+// A provider implements Spec and Provider, its Parameters, Request and Config are those of this package with its Spec.
+package restrecord
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// PerPage is the page size when listing records
+const PerPage = 100
+
+// ErrNotFound is matched by errors.Is when the API answers 404 Not Found
+var ErrNotFound = errors.New("not found")
+
+// Record is a DNS record
+// Name is in the form the API uses, e.g. relative name like www or @
+type Record struct {
+	Id    string
+	Name  string
+	Type  string
+	Value string
+	TTL   uint64 // 0 means the default TTL of provider
+}
+
+// Provider is the provider specific part of a REST DNS API
+type Provider interface {
+	// Auth return the token and how to send it
+	Auth() TokenAuth
+	// ZoneId return the id of the zone, which is the zone name if the API identifies zones by name
+	ZoneId(c *Client) (string, error)
+	// GetRecord return the record with id, error wrapping ErrNotFound should be returned if the record is gone
+	GetRecord(c *Client, zoneId string, id string) (Record, error)
+	// ListRecords return records of the zone in page(starts from 1) and whether there are more pages
+	// it should filter by name and type if the API supports, other records are skipped by Base
+	ListRecords(c *Client, zoneId string, page int) ([]Record, bool, error)
+	// UpdateRecord set the value and TTL of record, error wrapping ErrNotFound should be returned if the record is gone
+	UpdateRecord(c *Client, zoneId string, record Record) error
+	// ErrorMessage extract message from the body of an error response, "" if unknown
+	ErrorMessage(body []byte) string
+}
+
+// TokenAuth send Token in header Header, or "Authorization: Bearer <Token>" if Header is empty
+type TokenAuth struct {
+	Token  string
+	Header string
+}
+
+// apply set the token on req
+func (a TokenAuth) apply(req *resty.Request) *resty.Request {
+	if a.Header == "" {
+		return req.SetAuthToken(a.Token)
+	}
+	return req.SetHeader(a.Header, a.Token)
+}
+
+// APIError is the error answered by the API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s", e.StatusCode, e.Message)
+}
+
+// Is make errors.Is(err, ErrNotFound) true for 404
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == 404
+}
+
+// cachedRecord is the ids looked up before, so that the record need not be looked up again
+// it is kept in State through Parameters, see Parameters.GetRecordId
+type cachedRecord struct {
+	zoneId   string
+	recordId string
+}
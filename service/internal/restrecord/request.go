@@ -0,0 +1,252 @@
+package restrecord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	"GodDns/netutil"
+	json "GodDns/util/json"
+	"github.com/go-resty/resty/v2"
+)
+
+const requestTimeout = 20 * time.Second
+
+// Client make authorized requests for a Provider
+type Client struct {
+	ctx      context.Context
+	client   *resty.Client
+	provider Provider
+}
+
+// R return a json request with context and token set
+func (c *Client) R() *resty.Request {
+	return c.provider.Auth().apply(c.client.R().SetContext(c.ctx)).
+		SetHeader("Content-Type", "application/json")
+}
+
+// Do execute req with method and url, unmarshal the response to result if it is not nil
+// *APIError is returned if the API answers an error
+func (c *Client) Do(req *resty.Request, method string, url string, result any) error {
+	log.Debugf("%s %s", method, url)
+	response, err := req.Execute(method, url)
+	if err != nil {
+		return err
+	}
+	log.Tracef("response: %v", response)
+
+	if response.IsError() {
+		msg := c.provider.ErrorMessage(response.Body())
+		if msg == "" {
+			msg = response.String()
+		}
+		return &APIError{StatusCode: response.StatusCode(), Message: msg}
+	}
+	if result == nil {
+		return nil
+	}
+	if err = json.Unmarshal(response.Body(), result); err != nil {
+		return fmt.Errorf("error unmarshalling response %s: %w", response.String(), err)
+	}
+	return nil
+}
+
+// Request implements core.Request and core.ThroughProxy for the provider of S
+type Request[S Spec] struct {
+	parameters Parameters[S]
+	Base
+}
+
+// Init set parameter
+func (r *Request[S]) Init(parameters Parameters[S]) {
+	var spec S
+	r.parameters = parameters
+	s := r.parameters.Settings()
+	want := Record{Name: s.Name, Type: s.Type, Value: r.parameters.Value, TTL: r.parameters.TTL}
+	r.Base.bind(r.parameters.GetName(), spec.NewProvider(s), want, &r.parameters.cached)
+}
+
+// Target return target domain
+func (r *Request[S]) Target() string {
+	return r.parameters.Target()
+}
+
+// GetName return the name of provider
+func (r *Request[S]) GetName() string {
+	return r.parameters.GetName()
+}
+
+// ToParameters return core.Service
+func (r *Request[S]) ToParameters() core.Service {
+	return &r.parameters
+}
+
+// Base implements Status, MakeRequest and RequestThroughProxy of core.Request and core.ThroughProxy
+// 1.get zone id 2.list records page by page until the record is found 3.update the record if the value differs
+// ids are cached, so step 1 and 2 are replaced by getting the record by id next time until the record is gone
+type Base struct {
+	name     string
+	provider Provider
+	want     Record
+	cached   *cachedRecord
+	status   core.Status
+}
+
+// bind set name of service, provider, the record to set and the ids cached, which are updated when looked up
+func (b *Base) bind(name string, provider Provider, want Record, cached *cachedRecord) {
+	b.name = name
+	b.provider = provider
+	b.want = want
+	b.cached = cached
+}
+
+// Status return core.Status which contains execution result etc.
+func (b *Base) Status() core.Status {
+	return b.status
+}
+
+func (b *Base) newStatus() *core.Status {
+	return &core.Status{
+		Name:   b.name,
+		Status: core.NotExecute,
+		MG:     core.NewDefaultMsgGroup(),
+	}
+}
+
+// MakeRequest look up the record and update it
+func (b *Base) MakeRequest() error {
+	client := core.MainClientPool.Get().(*resty.Client)
+	defer core.MainClientPool.Put(client)
+	return b.execute(client)
+}
+
+// RequestThroughProxy make request through the proxies in netutil.GlobalProxies one by one until one succeeds
+func (b *Base) RequestThroughProxy() error {
+	if b.status.MG == nil {
+		b.status = *b.newStatus()
+	}
+
+	iter := netutil.GlobalProxies.GetProxyIter()
+	if iter.Len() == 0 {
+		return b.fail(errors.New("no proxy available"))
+	}
+
+	var err error
+	for iter.NotLast() {
+		proxy := iter.Next()
+		// never set proxy on a pooled client
+		client := core.MainClientPool.New().(*resty.Client).SetProxy(proxy)
+		err = b.execute(client)
+		// the server has answered, other proxies will get the same answer
+		var apiErr *APIError
+		if err == nil || errors.As(err, &apiErr) {
+			return err
+		}
+		errMsg := fmt.Sprintf("request error through proxy %s: %v", proxy, err)
+		b.status.MG.AddError(errMsg)
+		log.Errorf(errMsg)
+	}
+	return err
+}
+
+func (b *Base) execute(client *resty.Client) error {
+	if b.status.MG == nil {
+		b.status = *b.newStatus()
+	}
+
+	want := b.want
+	if want.Type != "A" && want.Type != "AAAA" {
+		return b.fail(fmt.Errorf("unsupported type %s", want.Type))
+	}
+	if !netutil.IsIpValid(want.Value) {
+		return b.fail(fmt.Errorf("invalid ip %s", want.Value))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	c := &Client{ctx: ctx, client: client, provider: b.provider}
+
+	if b.cached.recordId != "" {
+		record, err := b.provider.GetRecord(c, b.cached.zoneId, b.cached.recordId)
+		switch {
+		case err == nil && strings.EqualFold(record.Name, want.Name) && record.Type == want.Type:
+			return b.apply(c, b.cached.zoneId, record, want)
+		case err == nil || errors.Is(err, ErrNotFound):
+			log.Debugf("cached record %s of %s is gone, look it up again", b.cached.recordId, want.Name)
+			*b.cached = cachedRecord{}
+		default:
+			return b.fail(fmt.Errorf("error getting record %s: %w", b.cached.recordId, err))
+		}
+	}
+
+	zoneId, err := b.provider.ZoneId(c)
+	if err != nil {
+		return b.fail(fmt.Errorf("error getting zone id: %w", err))
+	}
+	record, err := b.findRecord(c, zoneId, want)
+	if err != nil {
+		return b.fail(fmt.Errorf("error getting record %s %s: %w", want.Name, want.Type, err))
+	}
+	*b.cached = cachedRecord{zoneId: zoneId, recordId: record.Id}
+	return b.apply(c, zoneId, record, want)
+}
+
+// apply update record to want unless its value and TTL are already the ones of want
+func (b *Base) apply(c *Client, zoneId string, record Record, want Record) error {
+	if netutil.IpEqual(record.Value, want.Value) && (want.TTL == 0 || record.TTL == want.TTL) {
+		b.status.Status = core.Unchanged
+		b.status.MG.AddInfo(fmt.Sprintf("record %s %s is already %s", want.Name, want.Type, want.Value))
+		return nil
+	}
+	return b.update(c, zoneId, record.Id, want)
+}
+
+// update set the record with id to want
+func (b *Base) update(c *Client, zoneId string, id string, want Record) error {
+	want.Id = id
+	if err := b.provider.UpdateRecord(c, zoneId, want); err != nil {
+		return b.fail(fmt.Errorf("error updating record %s: %w", id, err))
+	}
+	b.status.Status = core.Success
+	b.status.MG.AddInfo(fmt.Sprintf("record %s %s updated to %s", want.Name, want.Type, want.Value))
+	return nil
+}
+
+// findRecord list records page by page and return the one with the name and type of want
+func (b *Base) findRecord(c *Client, zoneId string, want Record) (Record, error) {
+	var found []Record
+	for page := 1; ; page++ {
+		records, more, err := b.provider.ListRecords(c, zoneId, page)
+		if err != nil {
+			return Record{}, err
+		}
+		for _, record := range records {
+			if strings.EqualFold(record.Name, want.Name) && record.Type == want.Type {
+				found = append(found, record)
+			}
+		}
+		if !more {
+			break
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return Record{}, errors.New("no record found")
+	case 1:
+	default:
+		b.status.MG.AddWarn(fmt.Sprintf("%d records found, only record %s is updated", len(found), found[0].Id))
+	}
+	return found[0], nil
+}
+
+// fail set status to Failed with err and return err
+func (b *Base) fail(err error) error {
+	b.status.Status = core.Failed
+	b.status.MG.AddError(err.Error())
+	return err
+}
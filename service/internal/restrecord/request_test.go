@@ -0,0 +1,160 @@
+package restrecord
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"GodDns/core"
+)
+
+// fakeProvider keeps records in memory, listing 2 records per page
+type fakeProvider struct {
+	want    Record
+	records []Record
+
+	zoneHits   int
+	getHits    int
+	listHits   int
+	updateHits int
+}
+
+func (f *fakeProvider) Auth() TokenAuth            { return TokenAuth{Token: "TOKEN"} }
+func (f *fakeProvider) ErrorMessage([]byte) string { return "" }
+
+func (f *fakeProvider) ZoneId(*Client) (string, error) {
+	f.zoneHits++
+	return "zone1", nil
+}
+
+func (f *fakeProvider) GetRecord(_ *Client, _ string, id string) (Record, error) {
+	f.getHits++
+	for _, record := range f.records {
+		if record.Id == id {
+			return record, nil
+		}
+	}
+	return Record{}, &APIError{StatusCode: 404, Message: "record not found"}
+}
+
+func (f *fakeProvider) ListRecords(_ *Client, _ string, page int) ([]Record, bool, error) {
+	f.listHits++
+	start, end := (page-1)*2, page*2
+	if end > len(f.records) {
+		end = len(f.records)
+	}
+	return f.records[start:end], end < len(f.records), nil
+}
+
+func (f *fakeProvider) UpdateRecord(_ *Client, _ string, record Record) error {
+	f.updateHits++
+	for i := range f.records {
+		if f.records[i].Id == record.Id {
+			f.records[i] = record
+			return nil
+		}
+	}
+	return &APIError{StatusCode: 404, Message: "record not found"}
+}
+
+func newFakeProvider(name string) *fakeProvider {
+	f := &fakeProvider{want: Record{Name: name, Type: "A", Value: "1.2.3.4", TTL: 300}}
+	for i, n := range []string{"@", "www", "www", "mail", "ftp"} {
+		f.records = append(f.records, Record{Id: strconv.Itoa(i), Name: n, Type: "A", Value: "5.6.7.8", TTL: 300})
+	}
+	f.records[2].Type = "AAAA"
+	return f
+}
+
+// newBase return a Base updating the record f wants, ids looked up are cached in cached
+func newBase(name string, f *fakeProvider, cached *cachedRecord) *Base {
+	b := &Base{}
+	b.bind(name, f, f.want, cached)
+	return b
+}
+
+func TestBase_MakeRequest(t *testing.T) {
+	f := newFakeProvider("ftp")
+	cached := &cachedRecord{}
+	b := newBase("Fake", f, cached)
+	if err := b.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if b.Status().Status != core.Success || f.records[4].Value != "1.2.3.4" {
+		t.Errorf("unexpected status %d, records %+v", b.Status().Status, f.records)
+	}
+	if f.listHits != 3 {
+		t.Errorf("expect 3 pages listed, got %d", f.listHits)
+	}
+
+	if *cached != (cachedRecord{zoneId: "zone1", recordId: "4"}) {
+		t.Errorf("unexpected ids cached %+v", *cached)
+	}
+
+	// ids are cached, the record is got by id and left as it is
+	b = newBase("Fake", f, cached)
+	if err := b.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if f.zoneHits != 1 || f.listHits != 3 || f.getHits != 1 || f.updateHits != 1 {
+		t.Errorf("ids should be cached, got %d zone, %d list, %d get and %d update requests", f.zoneHits, f.listHits, f.getHits, f.updateHits)
+	}
+	if b.Status().Status != core.Unchanged {
+		t.Errorf("status should be unchanged with cached ids, got %d", b.Status().Status)
+	}
+
+	// ids are cached and the value differs
+	f.records[4].Value = "5.6.7.8"
+	b = newBase("Fake", f, cached)
+	if err := b.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if f.zoneHits != 1 || f.updateHits != 2 || b.Status().Status != core.Success {
+		t.Errorf("record should be updated by cached id, got %d zone and %d update requests, status %d", f.zoneHits, f.updateHits, b.Status().Status)
+	}
+
+	// the cached record is gone
+	f.records[4].Id = "5"
+	f.records[4].Value = "5.6.7.8"
+	b = newBase("Fake", f, cached)
+	if err := b.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if f.zoneHits != 2 || f.records[4].Value != "1.2.3.4" || b.Status().Status != core.Success {
+		t.Errorf("record should be looked up again, got status %d, records %+v", b.Status().Status, f.records)
+	}
+}
+
+func TestBase_MakeRequestUnchanged(t *testing.T) {
+	f := newFakeProvider("www")
+	f.records[1].Value = "1.2.3.4"
+	b := newBase("FakeUnchanged", f, &cachedRecord{})
+	if err := b.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if b.Status().Status != core.Unchanged || f.updateHits != 0 {
+		t.Errorf("unexpected status %d", b.Status().Status)
+	}
+}
+
+func TestBase_MakeRequestNotFound(t *testing.T) {
+	f := newFakeProvider("smtp")
+	b := newBase("FakeNotFound", f, &cachedRecord{})
+	if err := b.MakeRequest(); err == nil {
+		t.Error("should return error when no record found")
+	}
+	if b.Status().Status != core.Failed {
+		t.Errorf("unexpected status %d", b.Status().Status)
+	}
+}
+
+func TestAPIError_Is(t *testing.T) {
+	var err error = &APIError{StatusCode: 404}
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("404 should be ErrNotFound")
+	}
+	err = &APIError{StatusCode: 401}
+	if errors.Is(err, ErrNotFound) {
+		t.Error("401 should not be ErrNotFound")
+	}
+}
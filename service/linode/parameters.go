@@ -0,0 +1,32 @@
+// Package linode use Linode API v4 to update DNS record
+package linode
+
+import (
+	"GodDns/service/internal/restrecord"
+)
+
+const serviceName = "Linode"
+
+// DefaultEndpoint is the base url of Linode API v4
+const DefaultEndpoint = "https://api.linode.com/v4"
+
+// Parameters implements DeviceOverridable, Filterable and SuffixComposable
+type Parameters = restrecord.Parameters[spec]
+
+// spec implements restrecord.Spec, the domain apex is "" in the API
+type spec struct{}
+
+func (spec) Info() restrecord.Info {
+	return restrecord.Info{
+		Name:            serviceName,
+		DefaultEndpoint: DefaultEndpoint,
+		Apex:            "",
+		DefaultTTL:      300,
+		TokenComment:    "personal access token with Domains Read/Write scope, get from https://cloud.linode.com/profile/tokens",
+		TTLComment:      "Time-To-Live, 0 means the default TTL of the domain",
+	}
+}
+
+func (spec) NewProvider(s restrecord.Settings) restrecord.Provider {
+	return provider{s}
+}
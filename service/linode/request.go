@@ -0,0 +1,139 @@
+package linode
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"GodDns/service/internal/restrecord"
+	json "GodDns/util/json"
+)
+
+// usage
+// r:=linode.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request and core.ThroughProxy
+type Request = restrecord.Request[spec]
+
+// provider implements restrecord.Provider
+// domains are identified by numeric id, lists are filtered by the header X-Filter
+type provider struct {
+	restrecord.Settings
+}
+
+func (p provider) Auth() restrecord.TokenAuth {
+	return restrecord.TokenAuth{Token: p.Token}
+}
+
+func (p provider) ZoneId(c *restrecord.Client) (string, error) {
+	res := &page[domain]{}
+	req := c.R().SetHeader("X-Filter", filter(map[string]string{"domain": p.Domain}))
+	if err := c.Do(req, http.MethodGet, p.Endpoint+"/domains", res); err != nil {
+		return "", err
+	}
+	for _, d := range res.Data {
+		if strings.EqualFold(d.Domain, p.Domain) {
+			return strconv.FormatInt(d.Id, 10), nil
+		}
+	}
+	return "", errors.New("no domain found")
+}
+
+func (p provider) GetRecord(c *restrecord.Client, zoneId string, id string) (restrecord.Record, error) {
+	res := &domainRecord{}
+	if err := c.Do(c.R(), http.MethodGet, p.Endpoint+"/domains/"+zoneId+"/records/"+id, res); err != nil {
+		return restrecord.Record{}, err
+	}
+	return res.toRecord(), nil
+}
+
+func (p provider) ListRecords(c *restrecord.Client, zoneId string, n int) ([]restrecord.Record, bool, error) {
+	res := &page[domainRecord]{}
+	req := c.R().
+		SetHeader("X-Filter", filter(map[string]string{"name": p.Name, "type": p.Type})).
+		SetQueryParams(map[string]string{
+			"page":      strconv.Itoa(n),
+			"page_size": strconv.Itoa(restrecord.PerPage),
+		})
+	if err := c.Do(req, http.MethodGet, p.Endpoint+"/domains/"+zoneId+"/records", res); err != nil {
+		return nil, false, err
+	}
+
+	records := make([]restrecord.Record, 0, len(res.Data))
+	for _, record := range res.Data {
+		records = append(records, record.toRecord())
+	}
+	return records, res.Page < res.Pages, nil
+}
+
+func (p provider) UpdateRecord(c *restrecord.Client, zoneId string, record restrecord.Record) error {
+	req := c.R().SetBody(domainRecord{
+		Target: record.Value,
+		TTLSec: record.TTL,
+	})
+	return c.Do(req, http.MethodPut, p.Endpoint+"/domains/"+zoneId+"/records/"+record.Id, nil)
+}
+
+func (p provider) ErrorMessage(body []byte) string {
+	res := &errorResponse{}
+	if err := json.Unmarshal(body, res); err != nil || len(res.Errors) == 0 {
+		return ""
+	}
+	msg := make([]string, 0, len(res.Errors))
+	for _, e := range res.Errors {
+		if e.Field != "" {
+			msg = append(msg, e.Field+": "+e.Reason)
+		} else {
+			msg = append(msg, e.Reason)
+		}
+	}
+	return strings.Join(msg, "; ")
+}
+
+// filter return the value of header X-Filter
+func filter(f map[string]string) string {
+	data, _ := json.Marshal(f)
+	return string(data)
+}
+
+// page is a page of list, page starts from 1
+type page[T any] struct {
+	Data    []T `json:"data"`
+	Page    int `json:"page"`
+	Pages   int `json:"pages"`
+	Results int `json:"results"`
+}
+
+type domain struct {
+	Id     int64  `json:"id"`
+	Domain string `json:"domain"`
+}
+
+type domainRecord struct {
+	Id     int64  `json:"id,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Target string `json:"target"`
+	TTLSec uint64 `json:"ttl_sec,omitempty"`
+}
+
+func (d domainRecord) toRecord() restrecord.Record {
+	return restrecord.Record{
+		Id:    strconv.FormatInt(d.Id, 10),
+		Name:  d.Name,
+		Type:  d.Type,
+		Value: d.Target,
+		TTL:   d.TTLSec,
+	}
+}
+
+// errorResponse is like {"errors":[{"field":"target","reason":"Invalid IPv4 address"}]}
+type errorResponse struct {
+	Errors []struct {
+		Field  string `json:"field"`
+		Reason string `json:"reason"`
+	} `json:"errors"`
+}
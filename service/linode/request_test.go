@@ -0,0 +1,142 @@
+package linode
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"GodDns/core"
+)
+
+// fakeLinode serve records of domain example.com with id 10, one record per page
+type fakeLinode struct {
+	t       *testing.T
+	mu      sync.Mutex
+	records []domainRecord
+}
+
+func (f *fakeLinode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("Authorization") != "Bearer TOKEN" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"errors":[{"reason":"Invalid Token"}]}`)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/domains":
+		if r.Header.Get("X-Filter") != `{"domain":"example.com"}` {
+			f.t.Errorf("unexpected filter %s", r.Header.Get("X-Filter"))
+		}
+		_, _ = io.WriteString(w, `{"data":[{"id":10,"domain":"example.com"}],"page":1,"pages":1,"results":1}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/domains/10/records":
+		// ignore X-Filter, so that records are looked up page by page
+		n := 1
+		_ = json.Unmarshal([]byte(r.URL.Query().Get("page")), &n)
+		res := page[domainRecord]{Data: f.records[n-1 : n], Page: n, Pages: len(f.records), Results: len(f.records)}
+		data, _ := json.Marshal(res)
+		_, _ = w.Write(data)
+	case r.Method == http.MethodPut && r.URL.Path == "/domains/10/records/3":
+		record := domainRecord{}
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			f.t.Error(err)
+		}
+		f.records[2].Target = record.Target
+		// ttl_sec is kept if not set
+		if record.TTLSec != 0 {
+			f.records[2].TTLSec = record.TTLSec
+		}
+		data, _ := json.Marshal(f.records[2])
+		_, _ = w.Write(data)
+	case r.Method == http.MethodPut:
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"errors":[{"field":"target","reason":"Invalid IPv4 address"}]}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"errors":[{"reason":"Not found"}]}`)
+	}
+}
+
+func newTestServer(t *testing.T) (*fakeLinode, *httptest.Server) {
+	f := &fakeLinode{t: t, records: []domainRecord{
+		{Id: 1, Type: "A", Name: "", Target: "1.1.1.1", TTLSec: 300},
+		{Id: 2, Type: "AAAA", Name: "www", Target: "::1", TTLSec: 300},
+		{Id: 3, Type: "A", Name: "www", Target: "1.1.1.1", TTLSec: 300},
+		{Id: 4, Type: "A", Name: "mail", Target: "1.1.1.1", TTLSec: 300},
+	}}
+	return f, httptest.NewServer(f)
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	f, server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "www",
+		Value:     "2.2.2.2",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d: %v", r.Status().Status, r.Status().MG.GetMsgOf(core.Error))
+	}
+	if f.records[2].Target != "2.2.2.2" || f.records[2].TTLSec != 300 {
+		t.Errorf("unexpected record %+v", f.records[2])
+	}
+}
+
+func TestRequest_MakeRequestUnchanged(t *testing.T) {
+	_, server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "@",
+		Value:     "1.1.1.1",
+		TTL:       300,
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Unchanged {
+		t.Errorf("status should be unchanged, got %d", r.Status().Status)
+	}
+}
+
+func TestRequest_MakeRequestWithError(t *testing.T) {
+	_, server := newTestServer(t)
+	defer server.Close()
+
+	p := Parameters{
+		Token:     "TOKEN",
+		Domain:    "example.com",
+		Subdomain: "mail",
+		Value:     "2.2.2.2",
+		Type:      "A",
+		Endpoint:  server.URL,
+	}
+	r, _ := p.ToRequest()
+	err := r.MakeRequest()
+	if err == nil {
+		t.Fatal("should return error")
+	}
+	t.Log(err)
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+}
@@ -0,0 +1,146 @@
+package powerdns
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util"
+	"GodDns/util/collections"
+	"gopkg.in/ini.v1"
+)
+
+func init() {
+	core.Add2FactoryList(configFactoryInstance)
+}
+
+var (
+	configFactoryInstance ConfigFactory
+	configInstance        Config
+)
+
+// ConfigFactory is a factory that create a new Config
+type ConfigFactory struct{}
+
+// GetName return the name of powerdns
+func (c ConfigFactory) GetName() string {
+	return serviceName
+}
+
+// Get return a singleton Config
+func (c ConfigFactory) Get() core.Config {
+	return &configInstance
+}
+
+// New return a new Config
+func (c ConfigFactory) New() *core.Config {
+	var config core.Config = &Config{}
+	return &config
+}
+
+// Config implements core.Config
+type Config struct{}
+
+// GetName Get name of service
+func (c Config) GetName() string {
+	return serviceName
+}
+
+// GenerateDefaultConfigInfo Create default config
+func (c Config) GenerateDefaultConfigInfo() (core.ConfigStr, error) {
+	p := GenerateDefaultConfigInfo()
+	return c.GenerateConfigInfo(&p, 0)
+}
+
+// ReadConfig Read config file
+// Parameters: sec ini.Section
+// Return: core.Parameters and error
+// if any error occurs, returned Parameters will be nil
+func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
+	names := [7]string{"ApiKey", "Endpoint", "Zone", "Subdomain", "Value", "TTL", "Type"}
+
+	p := Parameters{}
+	var subdomains, types []string
+	for _, name := range names {
+		if !sec.HasKey(name) {
+			return nil, core.NewMissKeyErr(name, serviceName)
+		} else {
+			switch name {
+			case "Subdomain":
+				subdomain := sec.Key(name).String()
+				subdomains = strings.Fields(strings.ReplaceAll(subdomain, ",", " "))
+				collections.RemoveDuplicate(&subdomains)
+			case "TTL":
+				ttl, err := sec.Key(name).Uint64()
+				if err != nil {
+					return nil, err
+				}
+				p.TTL = ttl
+			case "Type":
+				types = netutil.ParseTypes(sec.Key(name).String())
+				for _, t := range types {
+					if t == "" {
+						return nil, fmt.Errorf("invalid type %s", sec.Key(name).String())
+					}
+				}
+			default:
+				err := util.SetVariable(&p, name, sec.Key(name).String())
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// optional keys, empty if not exist
+	p.Server = sec.Key("Server").String()
+	p.Notify = sec.Key("Notify").MustBool(false)
+	p.Rectify = sec.Key("Rectify").MustBool(false)
+	p.Device = sec.Key("Device").String()
+	options, err := core.ReadDeviceOptions(sec)
+	if err != nil {
+		return nil, err
+	}
+	p.DeviceOptions = options
+
+	// one value per type like Value=1.2.3.4,2001:db8::1
+	values := netutil.ValuesOfTypes(p.Value, types)
+	ps := make([]core.Parameters, 0, len(subdomains)*len(types))
+	for _, subdomain := range subdomains {
+		if subdomain == "" {
+			continue
+		}
+		for _, t := range types {
+			ps = append(ps, &Parameters{
+				ApiKey:        p.ApiKey,
+				Endpoint:      p.Endpoint,
+				Server:        p.Server,
+				Zone:          p.Zone,
+				Subdomain:     subdomain,
+				Value:         values[t],
+				TTL:           p.TTL,
+				Type:          t,
+				Notify:        p.Notify,
+				Rectify:       p.Rectify,
+				Device:        p.Device,
+				DeviceOptions: p.DeviceOptions,
+			})
+		}
+	}
+	return ps, nil
+}
+
+// GenerateConfigInfo
+// Generate KeyValue style config
+func (c Config) GenerateConfigInfo(parameters core.Parameters, No uint) (core.ConfigStr, error) {
+	buffer := bytes.NewBufferString(core.ConfigHead(parameters, No))
+	buffer.WriteString(util.Convert2KeyValue(core.Format, parameters))
+	buffer.Write([]byte{'\n', '\n'})
+
+	return core.ConfigStr{
+		Name:    serviceName,
+		Content: buffer.String(),
+	}, nil
+}
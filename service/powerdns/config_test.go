@@ -0,0 +1,104 @@
+package powerdns
+
+import (
+	"testing"
+
+	"GodDns/core"
+	"gopkg.in/ini.v1"
+)
+
+func TestConfig_GenerateDefaultConfigInfo(t *testing.T) {
+	config, err := Config{}.GenerateDefaultConfigInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(config.Content)
+}
+
+func TestConfig_ReadConfig(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[PowerDNS]
+ApiKey=KEY
+Endpoint=http://127.0.0.1:8081
+Zone=example.com
+Subdomain=www,@
+Value=2001:db8::1,1.2.3.4
+TTL=300
+Type=4,AAAA
+Notify=true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("PowerDNS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 4 {
+		t.Fatalf("expect 4 parameters, got %d", len(ps))
+	}
+	for _, p := range ps {
+		p := p.(*Parameters)
+		if typ := p.GetType(); (typ == "4" && p.Value != "1.2.3.4") || (typ == "6" && p.Value != "2001:db8::1") {
+			t.Errorf("unexpected value %s of type %s", p.Value, p.Type)
+		}
+		if !p.Notify || p.Rectify || p.getServer() != DefaultServer {
+			t.Errorf("unexpected parameters %+v", p)
+		}
+		t.Log(p.getName(), p.Type)
+	}
+
+	// merged back into one section
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("expect 1 merged parameters, got %d", len(merged))
+	}
+	m := merged[0].(*Parameters)
+	if m.Type != "A,AAAA" || len(m.Subdomain) != len("www,@") || len(m.Value) != len("1.2.3.4,2001:db8::1") {
+		t.Errorf("unexpected merged parameters %+v", m)
+	}
+}
+
+func TestConfig_ReadConfigInvalidType(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[PowerDNS]
+ApiKey=KEY
+Endpoint=http://127.0.0.1:8081
+Zone=example.com
+Subdomain=www
+Value=1.2.3.4
+TTL=300
+Type=A,MX
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (Config{}).ReadConfig(*cfg.Section("PowerDNS")); err == nil {
+		t.Error("should return error when type is invalid")
+	}
+}
+
+func TestConfig_ReadConfigMissingKey(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[PowerDNS]
+ApiKey=KEY
+Subdomain=www
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (Config{}).ReadConfig(*cfg.Section("PowerDNS")); err == nil {
+		t.Error("should return error when key is missing")
+	}
+}
+
+func TestMergeParametersDifferentValues(t *testing.T) {
+	www := &Parameters{ApiKey: "KEY", Zone: "example.com", Subdomain: "www", Value: "1.2.3.4", Type: "A"}
+	ftp := &Parameters{ApiKey: "KEY", Zone: "example.com", Subdomain: "ftp", Value: "5.6.7.8", Type: "A"}
+
+	merged := core.MergeParameters(www, ftp)
+	if len(merged) != 2 {
+		t.Errorf("records of the same type with different values should not be merged, got %+v", merged[0])
+	}
+}
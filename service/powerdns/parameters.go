@@ -0,0 +1,165 @@
+// Package powerdns use the HTTP API of PowerDNS Authoritative Server to update DNS record
+package powerdns
+
+import (
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util/collections"
+)
+
+const serviceName = "PowerDNS"
+
+// DefaultServer is the id of server, always localhost for PowerDNS Authoritative Server
+const DefaultServer = "localhost"
+
+// Parameters implements DeviceOverridable and Mergeable
+// ApiKey is the api-key in pdns.conf, Endpoint is the address of its webserver
+// one Parameters per Subdomain and Type, they are merged back into one section when saving
+// Device is Device name when overriding ip with specific Device/interface
+type Parameters struct {
+	ApiKey    string `KeyValue:"ApiKey,api-key in pdns.conf"`
+	Endpoint  string `KeyValue:"Endpoint,address of the webserver like http://127.0.0.1:8081"`
+	Server    string `KeyValue:"Server,server id, localhost(default)"`
+	Zone      string `KeyValue:"Zone,zone name like example.com"`
+	Subdomain string `KeyValue:"Subdomain,record name like www, use @ for the zone apex, if you have multiple records to update, set like Subdomain=www,ftp,mail"`
+	Value     string `KeyValue:"Value,IP address like 6.6.6.6, set like Value=6.6.6.6,2001:db8::1 if Type=A,AAAA"`
+	TTL       uint64 `KeyValue:"TTL,Time-To-Live, 300(default)"`
+	Type      string `KeyValue:"Type,A/AAAA/4/6, set like Type=A,AAAA or Type=both to update both in one request"`
+	Notify    bool   `KeyValue:"Notify,whether to send NOTIFY to slaves after updating, true or false(default)"`
+	Rectify   bool   `KeyValue:"Rectify,whether to rectify the zone after updating, for DNSSEC zones without API-RECTIFY, true or false(default)"`
+	Device    string `KeyValue:"Device,device/net interface name"`
+	core.DeviceOptions
+}
+
+// GenerateDefaultConfigInfo return Default config
+func GenerateDefaultConfigInfo() Parameters {
+	return Parameters{
+		ApiKey:    "ApiKey",
+		Endpoint:  "http://127.0.0.1:8081",
+		Zone:      "example.com",
+		Subdomain: "www,mail,ftp...",
+		Value:     "1.2.3.4",
+		TTL:       300,
+		Type:      "A/AAAA/4/6",
+		Device:    "your device/net interface name",
+	}
+}
+
+// GetName return "PowerDNS"
+func (p *Parameters) GetName() string {
+	return serviceName
+}
+
+// SaveConfig return core.ConfigStr
+func (p *Parameters) SaveConfig(No uint) (core.ConfigStr, error) {
+	return configInstance.GenerateConfigInfo(p, No)
+}
+
+// Target return the full record name
+func (p *Parameters) Target() string {
+	return strings.TrimSuffix(p.getName(), ".")
+}
+
+// ToRequest Convert to core.Request
+func (p *Parameters) ToRequest() (core.Request, error) {
+	r := new(Request)
+	r.Init(*p)
+	return r, nil
+}
+
+// SetValue set ip
+func (p *Parameters) SetValue(value string) {
+	p.Value = value
+}
+
+// GetIP return ip value
+func (p *Parameters) GetIP() string {
+	return p.Value
+}
+
+// GetType return Type like "4" or "6" and "" if invalid type
+func (p *Parameters) GetType() string {
+	return netutil.Type2Num(p.Type)
+}
+
+// IsTypeSet return whether the type is set correctly
+func (p *Parameters) IsTypeSet() bool {
+	return p.Type == "A" || p.Type == "AAAA"
+}
+
+// GetDevice return Device name
+func (p *Parameters) GetDevice() string {
+	return p.Device
+}
+
+// IsDeviceSet return whether the Device is set
+func (p *Parameters) IsDeviceSet() bool {
+	return p.Device != ""
+}
+
+// MergeableWith return true if the two Parameters differ only by Subdomain, Type and Value,
+// Value must be the same if Type is the same
+func (p *Parameters) MergeableWith(other core.Parameters) bool {
+	o, ok := other.(*Parameters)
+	if !ok {
+		return false
+	}
+	return p.ApiKey == o.ApiKey && p.Endpoint == o.Endpoint && p.Server == o.Server && p.Zone == o.Zone &&
+		(p.Type != o.Type || p.Value == o.Value) &&
+		p.TTL == o.TTL && p.Notify == o.Notify && p.Rectify == o.Rectify && p.Device == o.Device &&
+		p.DeviceOptions == o.DeviceOptions
+}
+
+// Merge return a new Parameters whose Subdomain, Type and Value are like "www,ftp", "A,AAAA" and "1.2.3.4,2001:db8::1"
+func (p *Parameters) Merge(others ...core.Parameters) core.Parameters {
+	merged := *p
+	var subdomains, types, values []string
+	for _, other := range append([]core.Parameters{p}, others...) {
+		o := other.(*Parameters)
+		subdomains = collections.AppendUnique(subdomains, o.Subdomain)
+		types = collections.AppendUnique(types, o.Type)
+		if o.Value != "" {
+			values = collections.AppendUnique(values, o.Value)
+		}
+	}
+	merged.Subdomain = strings.Join(subdomains, ",")
+	merged.Type = strings.Join(types, ",")
+	merged.Value = strings.Join(values, ",")
+	return &merged
+}
+
+// getEndpoint return Endpoint without the trailing slash
+func (p *Parameters) getEndpoint() string {
+	return strings.TrimSuffix(p.Endpoint, "/")
+}
+
+// getServer return Server or DefaultServer if not set
+func (p *Parameters) getServer() string {
+	if p.Server == "" {
+		return DefaultServer
+	}
+	return p.Server
+}
+
+// getZone return the zone name ending with a dot like example.com.
+func (p *Parameters) getZone() string {
+	return strings.TrimSuffix(p.Zone, ".") + "."
+}
+
+// getName return the record name ending with a dot like www.example.com., "@" stands for the zone apex
+func (p *Parameters) getName() string {
+	if p.Subdomain == "@" || p.Subdomain == "" {
+		return p.getZone()
+	}
+	return p.Subdomain + "." + p.getZone()
+}
+
+// getTTL return TTL, 300 if not set
+func (p *Parameters) getTTL() uint64 {
+	if p.TTL == 0 {
+		return 300
+	}
+	return p.TTL
+}
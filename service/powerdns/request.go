@@ -0,0 +1,229 @@
+package powerdns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	"GodDns/netutil"
+	json "GodDns/util/json"
+	"github.com/go-resty/resty/v2"
+)
+
+const requestTimeout = 20 * time.Second
+
+// usage
+// r:=powerdns.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request and core.Batch
+// it does not implement core.ThroughProxy, since the server is usually on-prem
+type Request struct {
+	parameters Parameters
+	status     core.Status
+}
+
+// Init set parameter
+func (r *Request) Init(parameters Parameters) {
+	r.parameters = parameters
+}
+
+// Target return target domain
+func (r *Request) Target() string {
+	return r.parameters.Target()
+}
+
+// GetName return "PowerDNS"
+func (r *Request) GetName() string {
+	return serviceName
+}
+
+// ToParameters return core.Service
+func (r *Request) ToParameters() core.Service {
+	return &r.parameters
+}
+
+// Status return core.Status which contains execution result etc.
+func (r *Request) Status() core.Status {
+	return r.status
+}
+
+func newStatus() *core.Status {
+	return &core.Status{
+		Name:   serviceName,
+		Status: core.NotExecute,
+		MG:     core.NewDefaultMsgGroup(),
+	}
+}
+
+// MakeRequest PATCH the zone to replace the rrset, then NOTIFY and rectify the zone if set
+func (r *Request) MakeRequest() error {
+	return r.MakeBatchRequest()
+}
+
+// BatchKey return the key of zone, records of one zone are replaced by one PATCH
+func (r *Request) BatchKey() string {
+	p := r.parameters
+	return fmt.Sprintf("%s|%s|%s|%s|%t|%t", p.getEndpoint(), p.getServer(), p.getZone(), p.ApiKey, p.Notify, p.Rectify)
+}
+
+// MakeBatchRequest replace the rrsets of the receiver and others in one PATCH
+// the PATCH is atomic, all requests fail if any rrset is rejected, and they are retried one by one
+func (r *Request) MakeBatchRequest(others ...core.Request) error {
+	requests := make([]*Request, 0, len(others)+1)
+	requests = append(requests, r)
+	for _, other := range others {
+		o, ok := other.(*Request)
+		if !ok || o.BatchKey() != r.BatchKey() {
+			return r.fail(fmt.Errorf("%s:%s can not be batched with %s", other.GetName(), other.Target(), r.Target()))
+		}
+		requests = append(requests, o)
+	}
+
+	var err error
+	valid := make([]*Request, 0, len(requests))
+	rrsets := make([]rrset, 0, len(requests))
+	for _, request := range requests {
+		request.status = *newStatus()
+		if err = request.validate(); err != nil {
+			_ = request.fail(err)
+			continue
+		}
+		valid = append(valid, request)
+		rrsets = append(rrsets, request.rrset())
+	}
+	if len(valid) == 0 {
+		return err
+	}
+
+	client := core.MainClientPool.Get().(*resty.Client)
+	defer core.MainClientPool.Put(client)
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	code, err := r.call(ctx, client, http.MethodPatch, "", patchRequest{Rrsets: rrsets})
+	for _, request := range valid {
+		request.status = *code2status(code)
+		if err != nil {
+			request.status.Status = core.Failed
+			request.status.MG.AddError(fmt.Sprintf("%s at %s", err.Error(), request.Target()))
+		} else {
+			request.status.MG.AddInfo(fmt.Sprintf("%s %s replaced with %s", request.Target(), request.parameters.Type, request.parameters.Value))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// the records have been replaced, so it is only a warning if NOTIFY or rectify fails
+	if r.parameters.Rectify {
+		r.afterPatch(ctx, client, valid, "rectify")
+	}
+	if r.parameters.Notify {
+		r.afterPatch(ctx, client, valid, "notify")
+	}
+	// invalid ones have been set to Failed
+	return nil
+}
+
+// afterPatch PUT the zone/action like zone/notify, add the result to status of requests
+func (r *Request) afterPatch(ctx context.Context, client *resty.Client, requests []*Request, action string) {
+	_, err := r.call(ctx, client, http.MethodPut, "/"+action, nil)
+	for _, request := range requests {
+		if err != nil {
+			request.status.MG.AddWarn(fmt.Sprintf("error %s %s: %s", action, r.parameters.getZone(), err.Error()))
+		} else {
+			request.status.MG.AddInfo(fmt.Sprintf("%s %s succeeded", action, r.parameters.getZone()))
+		}
+	}
+}
+
+// validate check Type and Value
+func (r *Request) validate() error {
+	if !r.parameters.IsTypeSet() {
+		return fmt.Errorf("unsupported type %s", r.parameters.Type)
+	}
+	if netutil.WhichTypeStr(r.parameters.Value) != r.parameters.Type {
+		return fmt.Errorf("invalid ip %s of type %s", r.parameters.Value, r.parameters.Type)
+	}
+	return nil
+}
+
+// rrset return the rrset which replaces the record
+func (r *Request) rrset() rrset {
+	return rrset{
+		Name:       r.parameters.getName(),
+		Type:       r.parameters.Type,
+		TTL:        r.parameters.getTTL(),
+		ChangeType: "REPLACE",
+		Records:    []record{{Content: r.parameters.Value}},
+	}
+}
+
+// call make request to the zone with suffix like "/notify", return status code of the response
+func (r *Request) call(ctx context.Context, client *resty.Client, method string, suffix string, body any) (int, error) {
+	u := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s%s", r.parameters.getEndpoint(),
+		url.PathEscape(r.parameters.getServer()), url.PathEscape(r.parameters.getZone()), suffix)
+
+	req := client.R().SetContext(ctx).SetHeader("X-API-Key", r.parameters.ApiKey)
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		req.SetHeader("Content-Type", "application/json").SetBody(data)
+	}
+	log.Debugf("%s %s", method, u)
+	response, err := req.Execute(method, u)
+	if err != nil {
+		return 0, err
+	}
+	log.Tracef("response: %v", response)
+
+	if response.IsError() {
+		e := &errorResponse{}
+		if err = json.Unmarshal(response.Body(), e); err != nil || e.Error == "" {
+			return response.StatusCode(), fmt.Errorf("unexpected response %s: %s", response.Status(), response.String())
+		}
+		return response.StatusCode(), errors.New(e.Error)
+	}
+	return response.StatusCode(), nil
+}
+
+// fail set status to Failed with err and return err
+func (r *Request) fail(err error) error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+	r.status.Status = core.Failed
+	r.status.MG.AddError(err.Error())
+	return err
+}
+
+type record struct {
+	Content  string `json:"content"`
+	Disabled bool   `json:"disabled"`
+}
+
+type rrset struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	TTL        uint64   `json:"ttl"`
+	ChangeType string   `json:"changetype"`
+	Records    []record `json:"records"`
+}
+
+type patchRequest struct {
+	Rrsets []rrset `json:"rrsets"`
+}
+
+// errorResponse is like {"error":"RRset www.example.com. IN A: Record www.example.com./A '1.2.3': Parsing record content ..."}
+type errorResponse struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,157 @@
+package powerdns
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"GodDns/core"
+)
+
+// fakePowerDNS accept PATCH of zone example.com. with api key KEY
+type fakePowerDNS struct {
+	t       *testing.T
+	mu      sync.Mutex
+	patches []patchRequest
+	actions []string
+}
+
+func (f *fakePowerDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("X-API-Key") != "KEY" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"error":"Unauthorized"}`)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPatch && r.URL.Path == "/api/v1/servers/localhost/zones/example.com.":
+		req := patchRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.t.Error(err)
+		}
+		for _, rrset := range req.Rrsets {
+			if rrset.ChangeType != "REPLACE" || len(rrset.Records) != 1 {
+				f.t.Errorf("unexpected rrset %+v", rrset)
+			}
+			if rrset.Records[0].Content == "10.0.0.1" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = io.WriteString(w, `{"error":"RRset `+rrset.Name+` IN A: private address is not allowed"}`)
+				return
+			}
+		}
+		f.patches = append(f.patches, req)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && (r.URL.Path == "/api/v1/servers/localhost/zones/example.com./notify" ||
+		r.URL.Path == "/api/v1/servers/localhost/zones/example.com./rectify"):
+		f.actions = append(f.actions, r.URL.Path)
+		_, _ = io.WriteString(w, `{"result":"Notification queued"}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":"Could not find domain '`+r.URL.Path+`'"}`)
+	}
+}
+
+func newTestRequest(endpoint string, subdomain string, value string, t string) *Request {
+	p := Parameters{
+		ApiKey:    "KEY",
+		Endpoint:  endpoint,
+		Zone:      "example.com",
+		Subdomain: subdomain,
+		Value:     value,
+		TTL:       60,
+		Type:      t,
+		Notify:    true,
+		Rectify:   true,
+	}
+	r, _ := p.ToRequest()
+	return r.(*Request)
+}
+
+func TestRequest_MakeBatchRequest(t *testing.T) {
+	f := &fakePowerDNS{t: t}
+	server := httptest.NewServer(f)
+	defer server.Close()
+
+	a := newTestRequest(server.URL, "www", "1.2.3.4", "A")
+	aaaa := newTestRequest(server.URL+"/", "www", "2001:db8::1", "AAAA")
+	apex := newTestRequest(server.URL, "@", "1.2.3.4", "A")
+	invalid := newTestRequest(server.URL, "ftp", "2001:db8::1", "A")
+
+	groups := core.GroupBatches(a, aaaa, apex, invalid)
+	if len(groups) != 1 {
+		t.Fatalf("expect 1 batch, got %d", len(groups))
+	}
+	if err := a.MakeBatchRequest(aaaa, apex, invalid); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.patches) != 1 || len(f.patches[0].Rrsets) != 3 {
+		t.Fatalf("expect 1 PATCH with 3 rrsets, got %+v", f.patches)
+	}
+	if rrset := f.patches[0].Rrsets[2]; rrset.Name != "example.com." || rrset.TTL != 60 {
+		t.Errorf("unexpected rrset %+v", rrset)
+	}
+	if len(f.actions) != 2 {
+		t.Errorf("expect rectify and notify, got %v", f.actions)
+	}
+	for _, r := range []*Request{a, aaaa, apex} {
+		if r.Status().Status != core.Success {
+			t.Errorf("status of %s should be success, got %d", r.Target(), r.Status().Status)
+		}
+		t.Log(r.Status().MG.GetMsgOf(core.Info))
+	}
+	if invalid.Status().Status != core.Failed {
+		t.Errorf("status of invalid request should be failed, got %d", invalid.Status().Status)
+	}
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	f := &fakePowerDNS{t: t}
+	server := httptest.NewServer(f)
+	defer server.Close()
+
+	r := newTestRequest(server.URL, "www", "1.2.3.4", "A")
+	r.parameters.Notify = false
+	r.parameters.Rectify = false
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success || len(f.patches) != 1 || len(f.actions) != 0 {
+		t.Errorf("unexpected status %d, %d patches and %d actions", r.Status().Status, len(f.patches), len(f.actions))
+	}
+}
+
+func TestRequest_MakeBatchRequestRejected(t *testing.T) {
+	f := &fakePowerDNS{t: t}
+	server := httptest.NewServer(f)
+	defer server.Close()
+
+	a := newTestRequest(server.URL, "www", "1.2.3.4", "A")
+	private := newTestRequest(server.URL, "mail", "10.0.0.1", "A")
+	err := a.MakeBatchRequest(private)
+	if err == nil {
+		t.Fatal("should return error")
+	}
+	t.Log(err)
+	for _, r := range []*Request{a, private} {
+		if r.Status().Status != core.Failed {
+			t.Errorf("status of %s should be failed, got %d", r.Target(), r.Status().Status)
+		}
+	}
+	if len(f.actions) != 0 {
+		t.Errorf("should not notify after failure, got %v", f.actions)
+	}
+
+	// wrong api key
+	a.parameters.ApiKey = "BAD KEY"
+	if err = a.MakeRequest(); err == nil {
+		t.Fatal("should return error")
+	}
+	t.Log(a.Status().MG.GetMsgOf(core.Error))
+}
@@ -0,0 +1,38 @@
+package powerdns
+
+import (
+	"net/http"
+
+	"GodDns/core"
+)
+
+// code2status
+// convert the status code of http response to message and set status.Status
+// https://doc.powerdns.com/authoritative/http-api/index.html#working-with-the-api
+func code2status(code int) *core.Status {
+	msg := newStatus()
+	switch code {
+	case http.StatusOK, http.StatusNoContent:
+		msg.MG.AddInfo("request succeeded")
+	case http.StatusBadRequest:
+		msg.MG.AddError("bad request")
+	case http.StatusUnauthorized:
+		msg.MG.AddError("unauthorized, check ApiKey")
+	case http.StatusNotFound:
+		msg.MG.AddError("server or zone not found")
+	case http.StatusUnprocessableEntity:
+		msg.MG.AddError("invalid rrsets")
+	case http.StatusInternalServerError:
+		msg.MG.AddError("internal server error")
+	default:
+		msg.MG.AddError("unknown error")
+	}
+
+	if code == http.StatusOK || code == http.StatusNoContent {
+		msg.Status = core.Success
+	} else {
+		msg.Status = core.Failed
+	}
+
+	return msg
+}
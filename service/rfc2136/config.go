@@ -0,0 +1,149 @@
+package rfc2136
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util"
+	"GodDns/util/collections"
+	"gopkg.in/ini.v1"
+)
+
+func init() {
+	core.Add2FactoryList(configFactoryInstance)
+}
+
+var (
+	configFactoryInstance ConfigFactory
+	configInstance        Config
+)
+
+// ConfigFactory is a factory that create a new Config
+type ConfigFactory struct{}
+
+// GetName return the name of rfc2136
+func (c ConfigFactory) GetName() string {
+	return serviceName
+}
+
+// Get return a singleton Config
+func (c ConfigFactory) Get() core.Config {
+	return &configInstance
+}
+
+// New return a new Config
+func (c ConfigFactory) New() *core.Config {
+	var config core.Config = &Config{}
+	return &config
+}
+
+// Config implements core.Config
+type Config struct{}
+
+// GetName Get name of service
+func (c Config) GetName() string {
+	return serviceName
+}
+
+// GenerateDefaultConfigInfo Create default config
+func (c Config) GenerateDefaultConfigInfo() (core.ConfigStr, error) {
+	p := GenerateDefaultConfigInfo()
+	return c.GenerateConfigInfo(&p, 0)
+}
+
+// ReadConfig Read config file
+// Parameters: sec ini.Section
+// Return: core.Parameters and error
+// if any error occurs, returned Parameters will be nil
+func (c Config) ReadConfig(sec ini.Section) ([]core.Parameters, error) {
+	names := [6]string{"Server", "Zone", "Subdomain", "Value", "TTL", "Type"}
+
+	p := Parameters{}
+	var subdomains []string
+	for _, name := range names {
+		if !sec.HasKey(name) {
+			return nil, core.NewMissKeyErr(name, serviceName)
+		} else {
+			switch name {
+			case "Subdomain":
+				subdomain := sec.Key(name).String()
+				subdomains = collections.AppendUnique(nil, strings.Fields(strings.ReplaceAll(subdomain, ",", " "))...)
+			case "TTL":
+				ttl, err := sec.Key(name).Uint64()
+				if err != nil {
+					return nil, err
+				}
+				p.TTL = ttl
+			case "Type":
+				p.Type = netutil.Type2Str(sec.Key(name).String())
+			default:
+				err := util.SetVariable(&p, name, sec.Key(name).String())
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// optional keys, empty if not exist
+	p.Protocol = sec.Key("Protocol").String()
+	p.KeyName = sec.Key("KeyName").String()
+	p.Secret = sec.Key("Secret").String()
+	p.Algorithm = sec.Key("Algorithm").String()
+	p.Device = sec.Key("Device").String()
+	options, err := core.ReadDeviceOptions(sec)
+	if err != nil {
+		return nil, err
+	}
+	p.DeviceOptions = options
+
+	if protocol := p.getProtocol(); protocol != "udp" && protocol != "tcp" {
+		return nil, fmt.Errorf("unsupported protocol %s in %s", p.Protocol, serviceName)
+	}
+	if p.KeyName != "" {
+		if p.Secret == "" {
+			return nil, core.NewMissKeyErr("Secret", serviceName)
+		}
+		if _, ok := algorithms[p.getAlgorithm()]; !ok {
+			return nil, fmt.Errorf("unsupported TSIG algorithm %s in %s", p.Algorithm, serviceName)
+		}
+	}
+
+	ps := make([]core.Parameters, 0, len(subdomains))
+	for _, subdomain := range subdomains {
+		if subdomain == "" {
+			continue
+		}
+		ps = append(ps, &Parameters{
+			Server:        p.Server,
+			Protocol:      p.Protocol,
+			Zone:          p.Zone,
+			Subdomain:     subdomain,
+			KeyName:       p.KeyName,
+			Secret:        p.Secret,
+			Algorithm:     p.Algorithm,
+			Value:         p.Value,
+			TTL:           p.TTL,
+			Type:          p.Type,
+			Device:        p.Device,
+			DeviceOptions: p.DeviceOptions,
+		})
+	}
+	return ps, nil
+}
+
+// GenerateConfigInfo
+// Generate KeyValue style config
+func (c Config) GenerateConfigInfo(parameters core.Parameters, No uint) (core.ConfigStr, error) {
+	buffer := bytes.NewBufferString(core.ConfigHead(parameters, No))
+	buffer.WriteString(util.Convert2KeyValue(core.Format, parameters))
+	buffer.Write([]byte{'\n', '\n'})
+
+	return core.ConfigStr{
+		Name:    serviceName,
+		Content: buffer.String(),
+	}, nil
+}
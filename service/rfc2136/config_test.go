@@ -0,0 +1,119 @@
+package rfc2136
+
+import (
+	"strings"
+	"testing"
+
+	"GodDns/core"
+	"gopkg.in/ini.v1"
+)
+
+func TestConfig_GenerateDefaultConfigInfo(t *testing.T) {
+	config, err := Config{}.GenerateDefaultConfigInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(config.Content)
+}
+
+func TestConfig_ReadConfig(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[RFC2136]
+Server=ns1.example.com
+Zone=example.com
+Subdomain=www,mail
+KeyName=ddns-key
+Secret=c2VjcmV0
+Value=1.2.3.4
+TTL=300
+Type=4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("RFC2136"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 {
+		t.Fatalf("expect 2 parameters, got %d", len(ps))
+	}
+	for _, p := range ps {
+		p := p.(*Parameters)
+		if p.Type != "A" || p.getServer() != "ns1.example.com:53" || p.getProtocol() != "udp" || p.getAlgorithm() != HmacSHA256 {
+			t.Errorf("unexpected parameters %+v", p)
+		}
+		t.Log(p.Target())
+	}
+}
+
+func TestConfig_ReadConfigInvalid(t *testing.T) {
+	cases := []string{
+		// missing secret
+		"Server=ns1\nZone=example.com\nSubdomain=www\nKeyName=key\nValue=1.2.3.4\nTTL=300\nType=A",
+		// unsupported algorithm
+		"Server=ns1\nZone=example.com\nSubdomain=www\nKeyName=key\nSecret=c2VjcmV0\nAlgorithm=hmac-md5\nValue=1.2.3.4\nTTL=300\nType=A",
+		// unsupported protocol
+		"Server=ns1\nProtocol=quic\nZone=example.com\nSubdomain=www\nValue=1.2.3.4\nTTL=300\nType=A",
+	}
+	for _, c := range cases {
+		cfg, err := ini.Load([]byte("[RFC2136]\n" + c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = (Config{}).ReadConfig(*cfg.Section("RFC2136")); err == nil {
+			t.Errorf("should return error for %q", c)
+		}
+	}
+}
+
+func TestConfig_SaveMerged(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[RFC2136]
+Server=ns1.example.com:53
+Zone=example.com
+Subdomain=www,@,mail
+KeyName=ddns-key
+Secret=c2VjcmV0
+Value=1.2.3.4
+TTL=600
+Type=A
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := Config{}.ReadConfig(*cfg.Section("RFC2136"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged := core.MergeParameters(ps...)
+	if len(merged) != 1 {
+		t.Fatalf("expect 1 merged parameters, got %d", len(merged))
+	}
+	saved, err := merged[0].SaveConfig(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(saved.Content, "Subdomain=www,@,mail\n") {
+		t.Errorf("unexpected config:\n%s", saved.Content)
+	}
+
+	cfg, err = ini.Load([]byte(saved.Content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Config{}.ReadConfig(*cfg.Section("RFC2136"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != len(ps) {
+		t.Fatalf("expect %d parameters read again, got %d", len(ps), len(again))
+	}
+	for i := range again {
+		if *again[i].(*Parameters) != *ps[i].(*Parameters) {
+			t.Errorf("expect %+v, got %+v", ps[i], again[i])
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package rfc2136 send DNS UPDATE messages(RFC 2136) signed with TSIG(RFC 8945) to update DNS record
+package rfc2136
+
+import (
+	"net"
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util/collections"
+)
+
+const serviceName = "RFC2136"
+
+const (
+	defaultPort      = "53"
+	defaultProtocol  = "udp"
+	defaultAlgorithm = HmacSHA256
+)
+
+// Parameters implements DeviceOverridable and Mergeable
+// Server is the primary name server accepting updates of Zone
+// KeyName, Secret and Algorithm make up the TSIG key, leave KeyName empty to send unsigned updates
+// Device is Device name when overriding ip with specific Device/interface
+type Parameters struct {
+	Server    string `KeyValue:"Server,name server accepting dynamic update like ns1.example.com:53, port 53(default)"`
+	Protocol  string `KeyValue:"Protocol,udp(default)/tcp"`
+	Zone      string `KeyValue:"Zone,zone name like example.com"`
+	Subdomain string `KeyValue:"Subdomain,record name like www, use @ for the zone apex, if you have multiple records to update, set like Subdomain=www,ftp,mail"`
+	KeyName   string `KeyValue:"KeyName,TSIG key name, updates are not signed if empty"`
+	Secret    string `KeyValue:"Secret,base64 encoded TSIG secret"`
+	Algorithm string `KeyValue:"Algorithm,hmac-sha256(default)/hmac-sha512"`
+	Value     string `KeyValue:"Value,IP address like 6.6.6.6"`
+	TTL       uint64 `KeyValue:"TTL,Time-To-Live"`
+	Type      string `KeyValue:"Type,A/AAAA/4/6"`
+	Device    string `KeyValue:"Device,device/net interface name"`
+	core.DeviceOptions
+}
+
+// GenerateDefaultConfigInfo return Default config
+func GenerateDefaultConfigInfo() Parameters {
+	return Parameters{
+		Server:    "ns1.example.com:53",
+		Protocol:  defaultProtocol,
+		Zone:      "example.com",
+		Subdomain: "www,mail,ftp...",
+		KeyName:   "ddns-key",
+		Secret:    "base64 encoded secret",
+		Algorithm: defaultAlgorithm,
+		Value:     "1.2.3.4",
+		TTL:       600,
+		Type:      "A/AAAA/4/6",
+		Device:    "your device/net interface name",
+	}
+}
+
+// GetName return "RFC2136"
+func (p *Parameters) GetName() string {
+	return serviceName
+}
+
+// SaveConfig return core.ConfigStr
+func (p *Parameters) SaveConfig(No uint) (core.ConfigStr, error) {
+	return configInstance.GenerateConfigInfo(p, No)
+}
+
+// Target return the full record name
+func (p *Parameters) Target() string {
+	return p.getTotalDomain()
+}
+
+// ToRequest Convert to core.Request
+func (p *Parameters) ToRequest() (core.Request, error) {
+	r := new(Request)
+	r.Init(*p)
+	return r, nil
+}
+
+// SetValue set ip
+func (p *Parameters) SetValue(value string) {
+	p.Value = value
+}
+
+// GetIP return ip value
+func (p *Parameters) GetIP() string {
+	return p.Value
+}
+
+// GetType return Type like "4" or "6" and "" if invalid type
+func (p *Parameters) GetType() string {
+	return netutil.Type2Num(p.Type)
+}
+
+// IsTypeSet return whether the type is set correctly
+func (p *Parameters) IsTypeSet() bool {
+	return p.Type == "A" || p.Type == "AAAA"
+}
+
+// GetDevice return Device name
+func (p *Parameters) GetDevice() string {
+	return p.Device
+}
+
+// IsDeviceSet return whether the Device is set
+func (p *Parameters) IsDeviceSet() bool {
+	return p.Device != ""
+}
+
+// MergeableWith return true if the two Parameters differ only by Subdomain
+func (p *Parameters) MergeableWith(other core.Parameters) bool {
+	o, ok := other.(*Parameters)
+	if !ok {
+		return false
+	}
+	return p.Server == o.Server && p.Protocol == o.Protocol && p.Zone == o.Zone && p.KeyName == o.KeyName &&
+		p.Secret == o.Secret && p.Algorithm == o.Algorithm && p.Value == o.Value && p.TTL == o.TTL && p.Type == o.Type &&
+		p.Device == o.Device && p.DeviceOptions == o.DeviceOptions
+}
+
+// Merge return a new Parameters whose Subdomain is like "www,ftp"
+func (p *Parameters) Merge(others ...core.Parameters) core.Parameters {
+	merged := *p
+	subdomains := []string{p.Subdomain}
+	for _, other := range others {
+		subdomains = collections.AppendUnique(subdomains, other.(*Parameters).Subdomain)
+	}
+	merged.Subdomain = strings.Join(subdomains, ",")
+	return &merged
+}
+
+// getTotalDomain return subdomain+zone, "@" stands for the zone apex
+func (p *Parameters) getTotalDomain() string {
+	zone := strings.TrimSuffix(p.Zone, ".")
+	if p.Subdomain == "@" || p.Subdomain == "" {
+		return zone
+	}
+	return p.Subdomain + "." + zone
+}
+
+// getServer return Server with the default port appended if not set
+func (p *Parameters) getServer() string {
+	if _, _, err := net.SplitHostPort(p.Server); err == nil {
+		return p.Server
+	}
+	return net.JoinHostPort(strings.Trim(p.Server, "[]"), defaultPort)
+}
+
+// getProtocol return Protocol or "udp" if not set
+func (p *Parameters) getProtocol() string {
+	if p.Protocol == "" {
+		return defaultProtocol
+	}
+	return strings.ToLower(p.Protocol)
+}
+
+// getAlgorithm return Algorithm or "hmac-sha256" if not set
+func (p *Parameters) getAlgorithm() string {
+	if p.Algorithm == "" {
+		return defaultAlgorithm
+	}
+	return strings.ToLower(p.Algorithm)
+}
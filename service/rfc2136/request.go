@@ -0,0 +1,275 @@
+package rfc2136
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// opUpdate is the opcode of DNS UPDATE
+	opUpdate       = dnsmessage.OpCode(5)
+	requestTimeout = 20 * time.Second
+)
+
+// usage
+// r:=rfc2136.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request
+// DNS UPDATE can not go through a http proxy, so core.ThroughProxy is not implemented
+type Request struct {
+	parameters Parameters
+	status     core.Status
+}
+
+// Init set parameter
+func (r *Request) Init(parameters Parameters) {
+	r.parameters = parameters
+}
+
+// Target return target domain
+func (r *Request) Target() string {
+	return r.parameters.getTotalDomain()
+}
+
+// GetName return "RFC2136"
+func (r *Request) GetName() string {
+	return serviceName
+}
+
+// ToParameters return core.Service
+func (r *Request) ToParameters() core.Service {
+	return &r.parameters
+}
+
+// Status return core.Status which contains execution result etc.
+func (r *Request) Status() core.Status {
+	return r.status
+}
+
+func newStatus() *core.Status {
+	return &core.Status{
+		Name:   serviceName,
+		Status: core.NotExecute,
+		MG:     core.NewDefaultMsgGroup(),
+	}
+}
+
+// MakeRequest send a DNS UPDATE message which deletes the A/AAAA RRset of Target and adds the new record
+// the message is signed with TSIG if KeyName is set, so is the response verified
+func (r *Request) MakeRequest() error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	var key *tsigKey
+	if r.parameters.KeyName != "" {
+		var err error
+		key, err = newTsigKey(r.parameters.KeyName, r.parameters.getAlgorithm(), r.parameters.Secret)
+		if err != nil {
+			return r.fail(err)
+		}
+	}
+
+	id := newId()
+	msg, err := r.buildUpdate(id)
+	if err != nil {
+		return r.fail(fmt.Errorf("error building update message: %w", err))
+	}
+	var requestMAC []byte
+	if key != nil {
+		msg, requestMAC = key.sign(msg, nil, time.Now())
+	}
+
+	server := r.parameters.getServer()
+	protocol := r.parameters.getProtocol()
+	log.Debugf("send update of %s to %s over %s", r.Target(), server, protocol)
+	res, err := exchange(ctx, protocol, server, msg)
+	if err != nil {
+		return r.fail(fmt.Errorf("error sending update to %s: %w", server, err))
+	}
+
+	var p dnsmessage.Parser
+	header, err := p.Start(res)
+	if err != nil {
+		return r.fail(fmt.Errorf("error parsing response: %w", err))
+	}
+	// retry over tcp if the response is truncated
+	if header.Truncated && protocol == "udp" {
+		log.Debugf("response truncated, retry over tcp")
+		res, err = exchange(ctx, "tcp", server, msg)
+		if err != nil {
+			return r.fail(fmt.Errorf("error sending update to %s: %w", server, err))
+		}
+		if header, err = p.Start(res); err != nil {
+			return r.fail(fmt.Errorf("error parsing response: %w", err))
+		}
+	}
+	log.Tracef("response header: %v", header)
+
+	r.status = *rcode2status(header.RCode)
+	if header.RCode != NoError {
+		// error response may not be signed, report the TSIG error if any
+		if t, _ := parseTSIG(res); t != nil && t.error != 0 {
+			r.status.MG.AddError("TSIG error: " + tsigErrorString(t.error))
+		}
+		r.status.MG.AddError(fmt.Sprintf("failed to update %s at %s", r.Target(), server))
+		return fmt.Errorf("response code:%s", header.RCode)
+	}
+
+	if key != nil {
+		if _, err := key.verify(res, requestMAC, time.Now()); err != nil {
+			return r.fail(fmt.Errorf("error verifying response: %w", err))
+		}
+	}
+
+	r.status.MG.AddInfo(fmt.Sprintf("record updated %s %s %s", r.Target(), r.parameters.Type, r.parameters.Value))
+	return nil
+}
+
+// buildUpdate build the DNS UPDATE message
+// Zone section: the zone of the record
+// Prerequisite section: empty
+// Update section: delete the RRset of Target then add the record
+func (r *Request) buildUpdate(id uint16) ([]byte, error) {
+	zone, err := dnsmessage.NewName(canonical(r.parameters.Zone))
+	if err != nil {
+		return nil, err
+	}
+	name, err := dnsmessage.NewName(canonical(r.Target()))
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(r.parameters.Value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip %s", r.parameters.Value)
+	}
+
+	ttl := r.parameters.TTL
+	if ttl > math.MaxInt32 {
+		ttl = math.MaxInt32
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, OpCode: opUpdate})
+	if err = b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	err = b.Question(dnsmessage.Question{Name: zone, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.StartAuthorities(); err != nil {
+		return nil, err
+	}
+	h := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: uint32(ttl)}
+	// class ANY with empty rdata deletes the whole RRset
+	deleteRRset := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassANY}
+	switch r.parameters.Type {
+	case "A":
+		ip4 := ip.To4()
+		if ip4 == nil {
+			return nil, fmt.Errorf("%s is not an ipv4 address", r.parameters.Value)
+		}
+		if err = b.UnknownResource(deleteRRset, dnsmessage.UnknownResource{Type: dnsmessage.TypeA}); err != nil {
+			return nil, err
+		}
+		a := dnsmessage.AResource{}
+		copy(a.A[:], ip4)
+		err = b.AResource(h, a)
+	case "AAAA":
+		if ip.To4() != nil {
+			return nil, fmt.Errorf("%s is not an ipv6 address", r.parameters.Value)
+		}
+		if err = b.UnknownResource(deleteRRset, dnsmessage.UnknownResource{Type: dnsmessage.TypeAAAA}); err != nil {
+			return nil, err
+		}
+		aaaa := dnsmessage.AAAAResource{}
+		copy(aaaa.AAAA[:], ip.To16())
+		err = b.AAAAResource(h, aaaa)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", r.parameters.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Finish()
+}
+
+// exchange send msg to server over network(udp/tcp) and return the response with the same id
+func exchange(ctx context.Context, network, server string, msg []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		// messages over tcp are prefixed with a two byte length field
+		buf := make([]byte, 2, 2+len(msg))
+		binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+		if _, err = conn.Write(append(buf, msg...)); err != nil {
+			return nil, err
+		}
+		if _, err = io.ReadFull(conn, buf[:2]); err != nil {
+			return nil, err
+		}
+		res := make([]byte, binary.BigEndian.Uint16(buf))
+		if _, err = io.ReadFull(conn, res); err != nil {
+			return nil, err
+		}
+		if len(res) < 2 || binary.BigEndian.Uint16(res) != binary.BigEndian.Uint16(msg) {
+			return nil, errors.New("response id mismatch")
+		}
+		return res, nil
+	}
+
+	if _, err = conn.Write(msg); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, math.MaxUint16)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// ignore stray responses
+		if n >= 12 && binary.BigEndian.Uint16(buf) == binary.BigEndian.Uint16(msg) {
+			return buf[:n], nil
+		}
+	}
+}
+
+// fail set status to Failed with err and return err
+func (r *Request) fail(err error) error {
+	r.status.Status = core.Failed
+	r.status.MG.AddError(err.Error())
+	return err
+}
+
+// newId return a random message id
+func newId() uint16 {
+	b := make([]byte, 2)
+	_, _ = rand.Read(b)
+	return binary.BigEndian.Uint16(b)
+}
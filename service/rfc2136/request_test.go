@@ -0,0 +1,280 @@
+package rfc2136
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"GodDns/core"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var testSecret = base64.StdEncoding.EncodeToString([]byte("a very secret key"))
+
+// fakeServer is a name server accepting updates of example.com signed with ddns-key
+type fakeServer struct {
+	t         *testing.T
+	key       *tsigKey
+	rcode     dnsmessage.RCode
+	mu        sync.Mutex
+	updates   []dnsmessage.Resource
+	tsigError uint16
+}
+
+// handle return the response of the update message
+func (s *fakeServer) handle(msg []byte) []byte {
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	if err != nil {
+		s.t.Error(err)
+		return nil
+	}
+	if header.OpCode != opUpdate {
+		s.t.Errorf("opcode should be update, got %d", header.OpCode)
+	}
+	questions, _ := p.AllQuestions()
+	if len(questions) != 1 || questions[0].Name.String() != "example.com." || questions[0].Type != dnsmessage.TypeSOA {
+		s.t.Errorf("unexpected zone section %v", questions)
+	}
+	_ = p.SkipAllAnswers()
+	updates, _ := p.AllAuthorities()
+
+	rcode := s.rcode
+	var requestMAC []byte
+	var tsigError uint16
+	if s.key != nil {
+		t, err := s.key.verify(msg, nil, time.Now())
+		if err != nil {
+			rcode, tsigError = NotAuth, BadSig
+		} else {
+			requestMAC = t.mac
+		}
+	}
+
+	s.mu.Lock()
+	s.updates = updates
+	s.tsigError = tsigError
+	s.mu.Unlock()
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, OpCode: opUpdate, RCode: rcode})
+	_ = b.StartQuestions()
+	for _, q := range questions {
+		_ = b.Question(q)
+	}
+	res, _ := b.Finish()
+	if tsigError != 0 {
+		// unsigned TSIG record carrying the error
+		t := &tsigRecord{
+			name:       s.key.name,
+			algorithm:  s.key.algorithm,
+			timeSigned: uint64(time.Now().Unix()),
+			fudge:      fudge,
+			originalId: header.ID,
+			error:      tsigError,
+		}
+		res = t.appendRecord(res)
+		binary.BigEndian.PutUint16(res[10:], 1)
+		return res
+	}
+	if s.key != nil {
+		res, _ = s.key.sign(res, requestMAC, time.Now())
+	}
+	return res
+}
+
+func (s *fakeServer) serveUDP() string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo(s.handle(buf[:n]), addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func (s *fakeServer) serveTCP() string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			length := make([]byte, 2)
+			if _, err = io.ReadFull(conn, length); err != nil {
+				_ = conn.Close()
+				continue
+			}
+			msg := make([]byte, binary.BigEndian.Uint16(length))
+			if _, err = io.ReadFull(conn, msg); err != nil {
+				_ = conn.Close()
+				continue
+			}
+			res := s.handle(msg)
+			binary.BigEndian.PutUint16(length, uint16(len(res)))
+			_, _ = conn.Write(append(length, res...))
+			_ = conn.Close()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func (s *fakeServer) checkUpdates(name string, _type dnsmessage.Type, ttl uint32) {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.updates) != 2 {
+		s.t.Fatalf("expect 2 updates, got %d", len(s.updates))
+	}
+	del, add := s.updates[0].Header, s.updates[1].Header
+	if del.Name.String() != name || del.Type != _type || del.Class != dnsmessage.ClassANY || del.TTL != 0 || del.Length != 0 {
+		s.t.Errorf("first update should delete the RRset, got %v", del)
+	}
+	if add.Name.String() != name || add.Type != _type || add.Class != dnsmessage.ClassINET || add.TTL != ttl {
+		s.t.Errorf("second update should add the record, got %v", add)
+	}
+}
+
+func TestRequest_MakeRequestUDP(t *testing.T) {
+	key, _ := newTsigKey("ddns-key", HmacSHA256, testSecret)
+	server := &fakeServer{t: t, key: key}
+
+	p := Parameters{
+		Server:    server.serveUDP(),
+		Zone:      "example.com",
+		Subdomain: "www",
+		KeyName:   "ddns-key",
+		Secret:    testSecret,
+		Value:     "1.2.3.4",
+		TTL:       300,
+		Type:      "A",
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err, r.Status().MG.GetMsgOf(core.Error))
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d", r.Status().Status)
+	}
+	server.checkUpdates("www.example.com.", dnsmessage.TypeA, 300)
+	if a := server.updates[1].Body.(*dnsmessage.AResource).A; net.IP(a[:]).String() != "1.2.3.4" {
+		t.Errorf("unexpected value %v", a)
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Info))
+}
+
+func TestRequest_MakeRequestTCP(t *testing.T) {
+	key, _ := newTsigKey("ddns-key", HmacSHA512, testSecret)
+	server := &fakeServer{t: t, key: key}
+
+	p := Parameters{
+		Server:    server.serveTCP(),
+		Protocol:  "tcp",
+		Zone:      "example.com.",
+		Subdomain: "@",
+		KeyName:   "ddns-key",
+		Secret:    testSecret,
+		Algorithm: HmacSHA512,
+		Value:     "2001:db8::1",
+		TTL:       600,
+		Type:      "AAAA",
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err, r.Status().MG.GetMsgOf(core.Error))
+	}
+	server.checkUpdates("example.com.", dnsmessage.TypeAAAA, 600)
+}
+
+func TestRequest_MakeRequestWithBadKey(t *testing.T) {
+	key, _ := newTsigKey("ddns-key", HmacSHA256, testSecret)
+	server := &fakeServer{t: t, key: key}
+
+	p := Parameters{
+		Server:    server.serveUDP(),
+		Zone:      "example.com",
+		Subdomain: "www",
+		KeyName:   "ddns-key",
+		Secret:    base64.StdEncoding.EncodeToString([]byte("wrong key")),
+		Value:     "1.2.3.4",
+		TTL:       300,
+		Type:      "A",
+	}
+	r, _ := p.ToRequest()
+	if r.MakeRequest() == nil {
+		t.Fatal("should return error")
+	}
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+	if server.tsigError != BadSig {
+		t.Errorf("server should reject the signature")
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Error))
+}
+
+func TestRequest_MakeRequestRefused(t *testing.T) {
+	server := &fakeServer{t: t, rcode: Refused}
+
+	p := Parameters{
+		Server:    server.serveUDP(),
+		Zone:      "example.com",
+		Subdomain: "www",
+		Value:     "1.2.3.4",
+		TTL:       300,
+		Type:      "A",
+	}
+	r, _ := p.ToRequest()
+	if r.MakeRequest() == nil {
+		t.Fatal("should return error")
+	}
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Error))
+}
+
+func TestTsigKey_Verify(t *testing.T) {
+	key, _ := newTsigKey("ddns-key", HmacSHA256, testSecret)
+	p := Parameters{Zone: "example.com", Subdomain: "www", Value: "1.2.3.4", TTL: 300, Type: "A"}
+	r := &Request{parameters: p}
+	msg, err := r.buildUpdate(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, mac := key.sign(msg, nil, time.Now())
+	record, err := key.verify(signed, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(record.mac) != string(mac) || record.offset != len(msg) {
+		t.Error("unexpected TSIG record")
+	}
+
+	tampered := append([]byte{}, signed...)
+	tampered[len(msg)-1] ^= 0xff
+	if _, err = key.verify(tampered, nil, time.Now()); err == nil {
+		t.Error("tampered message should not pass verification")
+	}
+	if _, err = key.verify(signed, nil, time.Now().Add(time.Hour)); err == nil {
+		t.Error("expired message should not pass verification")
+	}
+}
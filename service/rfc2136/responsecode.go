@@ -0,0 +1,61 @@
+package rfc2136
+
+import (
+	"GodDns/core"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// response codes of DNS UPDATE, see https://www.rfc-editor.org/rfc/rfc2136#section-2.2
+const (
+	NoError  = dnsmessage.RCodeSuccess
+	FormErr  = dnsmessage.RCodeFormatError
+	ServFail = dnsmessage.RCodeServerFailure
+	NXDomain = dnsmessage.RCodeNameError
+	NotImp   = dnsmessage.RCodeNotImplemented
+	Refused  = dnsmessage.RCodeRefused
+	YXDomain = dnsmessage.RCode(6)
+	YXRRSet  = dnsmessage.RCode(7)
+	NXRRSet  = dnsmessage.RCode(8)
+	NotAuth  = dnsmessage.RCode(9)
+	NotZone  = dnsmessage.RCode(10)
+)
+
+// rcode2status
+// convert the rcode to message and set status.Status
+func rcode2status(rcode dnsmessage.RCode) *core.Status {
+	msg := newStatus()
+	switch rcode {
+	case NoError:
+		msg.MG.AddInfo("update succeeded")
+	case FormErr:
+		msg.MG.AddError("the server was unable to interpret the update message")
+	case ServFail:
+		msg.MG.AddError("the server encountered an internal failure")
+	case NXDomain:
+		msg.MG.AddError("a name that ought to exist does not exist")
+	case NotImp:
+		msg.MG.AddError("the server does not support dynamic update")
+	case Refused:
+		msg.MG.AddError("the server refused the update for policy reasons, check allow-update/update-policy")
+	case YXDomain:
+		msg.MG.AddError("a name that ought not to exist does exist")
+	case YXRRSet:
+		msg.MG.AddError("an RRset that ought not to exist does exist")
+	case NXRRSet:
+		msg.MG.AddError("an RRset that ought to exist does not exist")
+	case NotAuth:
+		msg.MG.AddError("the server is not authoritative for the zone or the key is not accepted")
+	case NotZone:
+		msg.MG.AddError("the record is not within the zone")
+	default:
+		msg.MG.AddError("unknown response code " + rcode.String())
+	}
+
+	if rcode == NoError {
+		msg.Status = core.Success
+	} else {
+		msg.Status = core.Failed
+	}
+
+	return msg
+}
@@ -0,0 +1,298 @@
+package rfc2136
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// supported TSIG algorithms, see https://www.rfc-editor.org/rfc/rfc8945#section-6
+const (
+	HmacSHA256 = "hmac-sha256"
+	HmacSHA512 = "hmac-sha512"
+)
+
+var algorithms = map[string]func() hash.Hash{
+	HmacSHA256: sha256.New,
+	HmacSHA512: sha512.New,
+}
+
+const (
+	typeTSIG = dnsmessage.Type(250)
+	// fudge is the permitted error of Time Signed in seconds, 300 is recommended by RFC 8945
+	fudge = 300
+)
+
+// TSIG error codes carried in the Error field of TSIG record
+const (
+	BadSig   = 16
+	BadKey   = 17
+	BadTime  = 18
+	BadTrunc = 22
+)
+
+// tsigKey is a shared secret used to sign DNS messages
+type tsigKey struct {
+	name      string
+	algorithm string
+	secret    []byte
+}
+
+func newTsigKey(name, algorithm, secret string) (*tsigKey, error) {
+	if _, ok := algorithms[algorithm]; !ok {
+		return nil, fmt.Errorf("unsupported TSIG algorithm %s", algorithm)
+	}
+	s, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("TSIG secret is not base64 encoded: %w", err)
+	}
+	return &tsigKey{name: name, algorithm: algorithm, secret: s}, nil
+}
+
+// tsigRecord is the parsed TSIG record at the end of a message
+type tsigRecord struct {
+	name       string
+	algorithm  string
+	timeSigned uint64
+	fudge      uint16
+	mac        []byte
+	originalId uint16
+	error      uint16
+	other      []byte
+	// offset of the record in the message
+	offset int
+}
+
+// sign append a TSIG record to the message and return the signed message with the MAC
+// requestMAC is the MAC of the request when signing a response, nil when signing a request
+func (k *tsigKey) sign(msg []byte, requestMAC []byte, now time.Time) ([]byte, []byte) {
+	t := &tsigRecord{
+		name:       k.name,
+		algorithm:  k.algorithm,
+		timeSigned: uint64(now.Unix()),
+		fudge:      fudge,
+		originalId: binary.BigEndian.Uint16(msg),
+	}
+	t.mac = k.mac(requestMAC, msg, t)
+
+	signed := make([]byte, 0, len(msg)+128)
+	signed = append(signed, msg...)
+	signed = t.appendRecord(signed)
+	// one more additional record
+	binary.BigEndian.PutUint16(signed[10:], binary.BigEndian.Uint16(signed[10:])+1)
+	return signed, t.mac
+}
+
+// verify check the TSIG record at the end of the message
+// requestMAC is the MAC of the request when verifying a response, nil when verifying a request
+func (k *tsigKey) verify(msg []byte, requestMAC []byte, now time.Time) (*tsigRecord, error) {
+	t, err := parseTSIG(msg)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.New("message is not signed")
+	}
+	if t.error != 0 {
+		return t, fmt.Errorf("TSIG error: %s", tsigErrorString(t.error))
+	}
+	if !strings.EqualFold(canonical(t.name), canonical(k.name)) {
+		return t, fmt.Errorf("message is signed with unknown key %s", t.name)
+	}
+	if !strings.EqualFold(canonical(t.algorithm), canonical(k.algorithm)) {
+		return t, fmt.Errorf("message is signed with unexpected algorithm %s", t.algorithm)
+	}
+
+	// strip the TSIG record and restore the header
+	unsigned := make([]byte, t.offset)
+	copy(unsigned, msg[:t.offset])
+	binary.BigEndian.PutUint16(unsigned, t.originalId)
+	binary.BigEndian.PutUint16(unsigned[10:], binary.BigEndian.Uint16(unsigned[10:])-1)
+
+	if !hmac.Equal(k.mac(requestMAC, unsigned, t), t.mac) {
+		return t, errors.New("TSIG error: bad signature")
+	}
+
+	diff := now.Unix() - int64(t.timeSigned)
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > int64(t.fudge) {
+		return t, errors.New("TSIG error: bad time")
+	}
+	return t, nil
+}
+
+// mac compute the MAC over
+// requestMAC(response only) + message without TSIG + TSIG variables
+func (k *tsigKey) mac(requestMAC []byte, msg []byte, t *tsigRecord) []byte {
+	h := hmac.New(algorithms[k.algorithm], k.secret)
+	if requestMAC != nil {
+		_ = binary.Write(h, binary.BigEndian, uint16(len(requestMAC)))
+		h.Write(requestMAC)
+	}
+	h.Write(msg)
+
+	variables := appendName(nil, t.name)
+	variables = binary.BigEndian.AppendUint16(variables, uint16(dnsmessage.ClassANY))
+	variables = binary.BigEndian.AppendUint32(variables, 0) // TTL
+	variables = appendName(variables, t.algorithm)
+	variables = appendUint48(variables, t.timeSigned)
+	variables = binary.BigEndian.AppendUint16(variables, t.fudge)
+	variables = binary.BigEndian.AppendUint16(variables, t.error)
+	variables = binary.BigEndian.AppendUint16(variables, uint16(len(t.other)))
+	variables = append(variables, t.other...)
+	h.Write(variables)
+
+	return h.Sum(nil)
+}
+
+// appendRecord append the wire format of TSIG record to b
+func (t *tsigRecord) appendRecord(b []byte) []byte {
+	rdata := appendName(nil, t.algorithm)
+	rdata = appendUint48(rdata, t.timeSigned)
+	rdata = binary.BigEndian.AppendUint16(rdata, t.fudge)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(t.mac)))
+	rdata = append(rdata, t.mac...)
+	rdata = binary.BigEndian.AppendUint16(rdata, t.originalId)
+	rdata = binary.BigEndian.AppendUint16(rdata, t.error)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(t.other)))
+	rdata = append(rdata, t.other...)
+
+	b = appendName(b, t.name)
+	b = binary.BigEndian.AppendUint16(b, uint16(typeTSIG))
+	b = binary.BigEndian.AppendUint16(b, uint16(dnsmessage.ClassANY))
+	b = binary.BigEndian.AppendUint32(b, 0) // TTL
+	b = binary.BigEndian.AppendUint16(b, uint16(len(rdata)))
+	return append(b, rdata...)
+}
+
+// parseTSIG return the TSIG record which must be the last additional record, nil if not exist
+func parseTSIG(msg []byte) (*tsigRecord, error) {
+	var p dnsmessage.Parser
+	if _, err := p.Start(msg); err != nil {
+		return nil, err
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, err
+	}
+	if err := p.SkipAllAnswers(); err != nil {
+		return nil, err
+	}
+	if err := p.SkipAllAuthorities(); err != nil {
+		return nil, err
+	}
+	additionals, err := p.AllAdditionals()
+	if err != nil {
+		return nil, err
+	}
+	if len(additionals) == 0 || additionals[len(additionals)-1].Header.Type != typeTSIG {
+		return nil, nil
+	}
+
+	last := additionals[len(additionals)-1]
+	rdata := last.Body.(*dnsmessage.UnknownResource).Data
+	t := &tsigRecord{name: last.Header.Name.String()}
+
+	// owner name of TSIG record is never compressed
+	length := len(appendName(nil, t.name)) + 10 + len(rdata)
+	if length > len(msg) {
+		return nil, errors.New("malformed TSIG record")
+	}
+	t.offset = len(msg) - length
+
+	var n int
+	t.algorithm, n, err = readName(rdata)
+	if err != nil {
+		return nil, err
+	}
+	rdata = rdata[n:]
+	if len(rdata) < 10 {
+		return nil, errors.New("malformed TSIG record")
+	}
+	t.timeSigned = uint64(binary.BigEndian.Uint16(rdata))<<32 | uint64(binary.BigEndian.Uint32(rdata[2:]))
+	t.fudge = binary.BigEndian.Uint16(rdata[6:])
+	macSize := int(binary.BigEndian.Uint16(rdata[8:]))
+	rdata = rdata[10:]
+	if len(rdata) < macSize+6 {
+		return nil, errors.New("malformed TSIG record")
+	}
+	t.mac = rdata[:macSize]
+	rdata = rdata[macSize:]
+	t.originalId = binary.BigEndian.Uint16(rdata)
+	t.error = binary.BigEndian.Uint16(rdata[2:])
+	otherLen := int(binary.BigEndian.Uint16(rdata[4:]))
+	rdata = rdata[6:]
+	if len(rdata) < otherLen {
+		return nil, errors.New("malformed TSIG record")
+	}
+	t.other = rdata[:otherLen]
+	return t, nil
+}
+
+// canonical return lower case fully qualified name
+func canonical(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "."
+}
+
+// appendName append the uncompressed canonical wire format of name to b
+func appendName(b []byte, name string) []byte {
+	name = strings.TrimSuffix(canonical(name), ".")
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			b = append(b, byte(len(label)))
+			b = append(b, label...)
+		}
+	}
+	return append(b, 0)
+}
+
+// readName read an uncompressed name from b, return the name and the count of bytes read
+func readName(b []byte) (string, int, error) {
+	var labels []string
+	i := 0
+	for {
+		if i >= len(b) {
+			return "", 0, errors.New("malformed name")
+		}
+		l := int(b[i])
+		i++
+		if l == 0 {
+			break
+		}
+		if l > 63 || i+l > len(b) {
+			return "", 0, errors.New("malformed name")
+		}
+		labels = append(labels, string(b[i:i+l]))
+		i += l
+	}
+	return strings.Join(labels, ".") + ".", i, nil
+}
+
+func appendUint48(b []byte, v uint64) []byte {
+	return append(b, byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func tsigErrorString(code uint16) string {
+	switch code {
+	case BadSig:
+		return "bad signature"
+	case BadKey:
+		return "bad key"
+	case BadTime:
+		return "bad time"
+	case BadTrunc:
+		return "bad truncation"
+	default:
+		return fmt.Sprintf("unknown error %d", code)
+	}
+}
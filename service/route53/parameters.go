@@ -0,0 +1,147 @@
+// Package route53 use Amazon Route 53 API to update DNS record, requests are signed with AWS Signature Version 4
+package route53
+
+import (
+	"strings"
+
+	"GodDns/core"
+	"GodDns/netutil"
+	"GodDns/util/collections"
+)
+
+const serviceName = "Route53"
+
+const (
+	// DefaultEndpoint is the endpoint of Route 53 API
+	DefaultEndpoint = "https://route53.amazonaws.com"
+	// DefaultRegion is the region to sign requests of Route 53, which is a global service
+	DefaultRegion = "us-east-1"
+)
+
+// Parameters implements DeviceOverridable and Mergeable
+// AccessKeyId, SecretAccessKey and SessionToken are used to sign the request
+// Domain is the full record name like www.example.com in the hosted zone HostedZoneId
+// Device is Device name when overriding ip with specific Device/interface
+type Parameters struct {
+	AccessKeyId     string `KeyValue:"AccessKeyId,get from https://console.aws.amazon.com/iam/home#/security_credentials"`
+	SecretAccessKey string `KeyValue:"SecretAccessKey,secret of the access key"`
+	SessionToken    string `KeyValue:"SessionToken,session token of temporary credentials, optional"`
+	HostedZoneId    string `KeyValue:"HostedZoneId,id of the hosted zone like Z1D633PJN98FT9"`
+	Domain          string `KeyValue:"Domain,full record name like www.example.com, if you have multiple records to update, set like Domain=www.example.com,ftp.example.com"`
+	Value           string `KeyValue:"Value,IP address like 6.6.6.6"`
+	TTL             uint64 `KeyValue:"TTL,Time-To-Live, 300(default)"`
+	Type            string `KeyValue:"Type,A/AAAA/4/6"`
+	Endpoint        string `KeyValue:"Endpoint,API endpoint, https://route53.amazonaws.com(default)"`
+	Region          string `KeyValue:"Region,region to sign requests, us-east-1(default)"`
+	Device          string `KeyValue:"Device,device/net interface name"`
+	core.DeviceOptions
+}
+
+// GenerateDefaultConfigInfo return Default config
+func GenerateDefaultConfigInfo() Parameters {
+	return Parameters{
+		AccessKeyId:     "AccessKeyId",
+		SecretAccessKey: "SecretAccessKey",
+		HostedZoneId:    "HostedZoneId",
+		Domain:          "www.example.com,mail.example.com...",
+		Value:           "1.2.3.4",
+		TTL:             300,
+		Type:            "A/AAAA/4/6",
+		Device:          "your device/net interface name",
+	}
+}
+
+// GetName return "Route53"
+func (p *Parameters) GetName() string {
+	return serviceName
+}
+
+// SaveConfig return core.ConfigStr
+func (p *Parameters) SaveConfig(No uint) (core.ConfigStr, error) {
+	return configInstance.GenerateConfigInfo(p, No)
+}
+
+// Target return the record name
+func (p *Parameters) Target() string {
+	return p.Domain
+}
+
+// ToRequest Convert to core.Request
+func (p *Parameters) ToRequest() (core.Request, error) {
+	r := new(Request)
+	r.Init(*p)
+	return r, nil
+}
+
+// SetValue set ip
+func (p *Parameters) SetValue(value string) {
+	p.Value = value
+}
+
+// GetIP return ip value
+func (p *Parameters) GetIP() string {
+	return p.Value
+}
+
+// GetType return Type like "4" or "6" and "" if invalid type
+func (p *Parameters) GetType() string {
+	return netutil.Type2Num(p.Type)
+}
+
+// IsTypeSet return whether the type is set correctly
+func (p *Parameters) IsTypeSet() bool {
+	return p.Type == "A" || p.Type == "AAAA"
+}
+
+// GetDevice return Device name
+func (p *Parameters) GetDevice() string {
+	return p.Device
+}
+
+// IsDeviceSet return whether the Device is set
+func (p *Parameters) IsDeviceSet() bool {
+	return p.Device != ""
+}
+
+// MergeableWith return true if the two Parameters differ only by Domain
+func (p *Parameters) MergeableWith(other core.Parameters) bool {
+	o, ok := other.(*Parameters)
+	if !ok {
+		return false
+	}
+	return p.AccessKeyId == o.AccessKeyId && p.SecretAccessKey == o.SecretAccessKey && p.SessionToken == o.SessionToken &&
+		p.HostedZoneId == o.HostedZoneId && p.Value == o.Value && p.TTL == o.TTL && p.Type == o.Type &&
+		p.Endpoint == o.Endpoint && p.Region == o.Region && p.Device == o.Device && p.DeviceOptions == o.DeviceOptions
+}
+
+// Merge return a new Parameters whose Domain is like "www.example.com,ftp.example.com"
+func (p *Parameters) Merge(others ...core.Parameters) core.Parameters {
+	merged := *p
+	domains := []string{p.Domain}
+	for _, other := range others {
+		domains = collections.AppendUnique(domains, other.(*Parameters).Domain)
+	}
+	merged.Domain = strings.Join(domains, ",")
+	return &merged
+}
+
+// getEndpoint return Endpoint or DefaultEndpoint if not set
+func (p *Parameters) getEndpoint() string {
+	if p.Endpoint == "" {
+		return DefaultEndpoint
+	}
+	return strings.TrimSuffix(p.Endpoint, "/")
+}
+
+// getRegion return Region or DefaultRegion if not set
+func (p *Parameters) getRegion() string {
+	if p.Region == "" {
+		return DefaultRegion
+	}
+	return p.Region
+}
+
+// getHostedZoneId return HostedZoneId without the prefix "/hostedzone/"
+func (p *Parameters) getHostedZoneId() string {
+	return strings.TrimPrefix(p.HostedZoneId, "/hostedzone/")
+}
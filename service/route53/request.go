@@ -0,0 +1,333 @@
+package route53
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	"GodDns/netutil"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	apiVersion     = "2013-04-01"
+	requestTimeout = 20 * time.Second
+)
+
+// variables so that they can be shortened in tests
+var (
+	// pollInterval is the interval of polling GetChange
+	pollInterval = 5 * time.Second
+	// syncTimeout is how long to wait for the change to be INSYNC
+	syncTimeout = 2 * time.Minute
+)
+
+// usage
+// r:=route53.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request and core.ThroughProxy
+type Request struct {
+	parameters Parameters
+	status     core.Status
+}
+
+// Init set parameter
+func (r *Request) Init(parameters Parameters) {
+	r.parameters = parameters
+}
+
+// Target return target domain
+func (r *Request) Target() string {
+	return r.parameters.Target()
+}
+
+// GetName return "Route53"
+func (r *Request) GetName() string {
+	return serviceName
+}
+
+// ToParameters return core.Service
+func (r *Request) ToParameters() core.Service {
+	return &r.parameters
+}
+
+// Status return core.Status which contains execution result etc.
+func (r *Request) Status() core.Status {
+	return r.status
+}
+
+func newStatus() *core.Status {
+	return &core.Status{
+		Name:   serviceName,
+		Status: core.NotExecute,
+		MG:     core.NewDefaultMsgGroup(),
+	}
+}
+
+// MakeRequest 1.ChangeResourceRecordSets to UPSERT the record 2.GetChange until the change is INSYNC
+func (r *Request) MakeRequest() error {
+	client := core.MainClientPool.Get().(*resty.Client)
+	defer core.MainClientPool.Put(client)
+	return r.execute(client)
+}
+
+// RequestThroughProxy make request through the proxies in netutil.GlobalProxies one by one until one succeeds
+func (r *Request) RequestThroughProxy() error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	iter := netutil.GlobalProxies.GetProxyIter()
+	if iter.Len() == 0 {
+		return r.fail(errors.New("no proxy available"))
+	}
+
+	var err error
+	for iter.NotLast() {
+		proxy := iter.Next()
+		// never set proxy on a pooled client
+		client := core.MainClientPool.New().(*resty.Client).SetProxy(proxy)
+		err = r.execute(client)
+		// the server has answered, other proxies will get the same answer
+		var apiErr *apiError
+		if err == nil || errors.As(err, &apiErr) {
+			return err
+		}
+		errMsg := fmt.Sprintf("request error through proxy %s: %v", proxy, err)
+		r.status.MG.AddError(errMsg)
+		log.Errorf(errMsg)
+	}
+	return err
+}
+
+func (r *Request) execute(client *resty.Client) error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	body, err := r.buildChangeBatch()
+	if err != nil {
+		return r.fail(fmt.Errorf("error building change batch: %w", err))
+	}
+
+	res := &changeInfoResponse{}
+	submitCtx, cancelSubmit := context.WithTimeout(ctx, requestTimeout)
+	defer cancelSubmit()
+	err = r.call(submitCtx, client, http.MethodPost, "/"+apiVersion+"/hostedzone/"+r.parameters.getHostedZoneId()+"/rrset/", body, res)
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			r.status = *code2status(apiErr.Code)
+			r.status.MG.AddError(fmt.Sprintf("%s at %s, RequestId: %s", apiErr.Message, r.Target(), apiErr.RequestId))
+			return err
+		}
+		return r.fail(err)
+	}
+
+	r.status = *newStatus()
+	r.status.Status = core.Success
+	r.status.MG.AddInfo(fmt.Sprintf("UPSERT %s %s %s submitted as change %s, status %s",
+		r.Target(), r.parameters.Type, r.parameters.Value, changeId(res.ChangeInfo.Id), res.ChangeInfo.Status))
+	r.waitForSync(ctx, client, res.ChangeInfo)
+	return nil
+}
+
+// waitForSync poll GetChange until the change is INSYNC
+// the change has been accepted, so it is only a warning if the change is not INSYNC in syncTimeout
+func (r *Request) waitForSync(ctx context.Context, client *resty.Client, info changeInfo) {
+	id := changeId(info.Id)
+	start := time.Now()
+	status := info.Status
+	var err error
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for status != InSync {
+		select {
+		case <-ctx.Done():
+			warnMsg := fmt.Sprintf("change %s is still %s after %s", id, status, time.Since(start).Round(time.Second))
+			if err != nil {
+				warnMsg += ", last error: " + err.Error()
+			}
+			r.status.MG.AddWarn(warnMsg)
+			return
+		case <-ticker.C:
+		}
+
+		res := &changeInfoResponse{}
+		if callErr := r.call(ctx, client, http.MethodGet, "/"+apiVersion+"/change/"+id, nil, res); callErr != nil {
+			log.Debugf("error getting change %s: %s", id, callErr.Error())
+			// the request canceled by timeout is not worth reporting
+			if ctx.Err() == nil {
+				err = callErr
+			}
+			continue
+		}
+		status = res.ChangeInfo.Status
+		log.Debugf("change %s is %s", id, status)
+	}
+	r.status.MG.AddInfo(fmt.Sprintf("change %s is %s after %s", id, InSync, time.Since(start).Round(time.Second)))
+}
+
+// call make a signed request to path with body, unmarshal the response to result
+// *apiError is returned if the API answers an error
+func (r *Request) call(ctx context.Context, client *resty.Client, method string, path string, body []byte, result any) error {
+	u, err := url.Parse(r.parameters.getEndpoint() + path)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string)
+	if body != nil {
+		headers["Content-Type"] = "application/xml"
+	}
+	s := signer{
+		accessKeyId:     r.parameters.AccessKeyId,
+		secretAccessKey: r.parameters.SecretAccessKey,
+		sessionToken:    r.parameters.SessionToken,
+		region:          r.parameters.getRegion(),
+		service:         signingService,
+	}
+	signed := s.sign(method, u, headers, body, time.Now())
+
+	req := client.R().SetContext(ctx).SetHeaders(headers).SetHeaders(signed)
+	if body != nil {
+		req.SetBody(body)
+	}
+	log.Debugf("%s %s", method, u.String())
+	response, err := req.Execute(method, u.String())
+	if err != nil {
+		return err
+	}
+	log.Tracef("response: %v", response)
+
+	if response.IsError() {
+		e := &errorResponse{}
+		if err = xml.Unmarshal(response.Body(), e); err != nil {
+			return fmt.Errorf("unexpected response %s: %s", response.Status(), response.String())
+		}
+		if e.Error.Code != "" {
+			return &apiError{Code: e.Error.Code, Message: e.Error.Message, RequestId: e.RequestId}
+		}
+		if len(e.Messages) != 0 {
+			return &apiError{Code: e.XMLName.Local, Message: strings.Join(e.Messages, "; "), RequestId: e.RequestId}
+		}
+		return fmt.Errorf("unexpected response %s: %s", response.Status(), response.String())
+	}
+
+	if err = xml.Unmarshal(response.Body(), result); err != nil {
+		return fmt.Errorf("error unmarshalling response %s: %w", response.String(), err)
+	}
+	return nil
+}
+
+// buildChangeBatch return the xml body of ChangeResourceRecordSets which UPSERT the record
+func (r *Request) buildChangeBatch() ([]byte, error) {
+	if !r.parameters.IsTypeSet() {
+		return nil, fmt.Errorf("unsupported type %s", r.parameters.Type)
+	}
+	if !netutil.IsIpValid(r.parameters.Value) {
+		return nil, fmt.Errorf("invalid ip %s", r.parameters.Value)
+	}
+
+	req := changeResourceRecordSetsRequest{}
+	req.ChangeBatch.Comment = "updated by " + core.FullName
+	req.ChangeBatch.Changes = []change{{
+		Action: "UPSERT",
+		ResourceRecordSet: resourceRecordSet{
+			Name:            r.parameters.Domain,
+			Type:            r.parameters.Type,
+			TTL:             r.parameters.TTL,
+			ResourceRecords: []resourceRecord{{Value: r.parameters.Value}},
+		},
+	}}
+	body, err := xml.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// fail set status to Failed with err and return err
+func (r *Request) fail(err error) error {
+	r.status.Status = core.Failed
+	r.status.MG.AddError(err.Error())
+	return err
+}
+
+// changeId return id of change without the prefix "/change/"
+func changeId(id string) string {
+	return strings.TrimPrefix(id, "/change/")
+}
+
+// apiError is the error answered by Route 53
+type apiError struct {
+	Code      string
+	Message   string
+	RequestId string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s, RequestId: %s", e.Code, e.Message, e.RequestId)
+}
+
+type changeResourceRecordSetsRequest struct {
+	XMLName     xml.Name `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ChangeResourceRecordSetsRequest"`
+	ChangeBatch struct {
+		Comment string   `xml:"Comment,omitempty"`
+		Changes []change `xml:"Changes>Change"`
+	} `xml:"ChangeBatch"`
+}
+
+type change struct {
+	Action            string            `xml:"Action"`
+	ResourceRecordSet resourceRecordSet `xml:"ResourceRecordSet"`
+}
+
+type resourceRecordSet struct {
+	Name            string           `xml:"Name"`
+	Type            string           `xml:"Type"`
+	TTL             uint64           `xml:"TTL"`
+	ResourceRecords []resourceRecord `xml:"ResourceRecords>ResourceRecord"`
+}
+
+type resourceRecord struct {
+	Value string `xml:"Value"`
+}
+
+type changeInfo struct {
+	Id          string `xml:"Id"`
+	Status      string `xml:"Status"`
+	SubmittedAt string `xml:"SubmittedAt"`
+	Comment     string `xml:"Comment"`
+}
+
+// changeInfoResponse is the response of ChangeResourceRecordSets and GetChange
+type changeInfoResponse struct {
+	ChangeInfo changeInfo `xml:"ChangeInfo"`
+}
+
+// errorResponse is like <ErrorResponse><Error><Code>...</Code></Error></ErrorResponse>
+// except InvalidChangeBatch which is like <InvalidChangeBatch><Messages><Message>...</Message></Messages></InvalidChangeBatch>
+type errorResponse struct {
+	XMLName xml.Name
+	Error   struct {
+		Type    string `xml:"Type"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+	Messages  []string `xml:"Messages>Message"`
+	RequestId string   `xml:"RequestId"`
+}
@@ -0,0 +1,198 @@
+package route53
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"GodDns/core"
+)
+
+func TestSigner_Sign(t *testing.T) {
+	// get-vanilla of the AWS Signature Version 4 test suite
+	s := signer{
+		accessKeyId:     "AKIDEXAMPLE",
+		secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		region:          "us-east-1",
+		service:         "service",
+	}
+	u, _ := url.Parse("https://example.amazonaws.com/")
+	now, _ := time.Parse(amzDateFormat, "20150830T123600Z")
+	signed := s.sign(http.MethodGet, u, nil, nil, now)
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if signed["Authorization"] != expected {
+		t.Errorf("unexpected authorization\n%s\n%s", signed["Authorization"], expected)
+	}
+	if signed["X-Amz-Date"] != "20150830T123600Z" {
+		t.Errorf("unexpected date %s", signed["X-Amz-Date"])
+	}
+}
+
+// fakeRoute53 is a Route 53 API accepting changes of hosted zone Z1 signed with AKID/SECRET
+// a change is PENDING until GetChange is requested pendingPolls times
+type fakeRoute53 struct {
+	t            *testing.T
+	pendingPolls int
+	errorBody    string
+
+	mu      sync.Mutex
+	changes []change
+	polls   int
+}
+
+func (f *fakeRoute53) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.verify(r, body)
+	w.Header().Set("Content-Type", "text/xml")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/2013-04-01/hostedzone/Z1/rrset/":
+		if f.errorBody != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, f.errorBody)
+			return
+		}
+		req := changeResourceRecordSetsRequest{}
+		if err := xml.Unmarshal(body, &req); err != nil {
+			f.t.Error(err)
+		}
+		f.changes = append(f.changes, req.ChangeBatch.Changes...)
+		_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ChangeResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/"><ChangeInfo><Id>/change/C1</Id><Status>PENDING</Status><SubmittedAt>2023-01-01T00:00:00.000Z</SubmittedAt></ChangeInfo></ChangeResourceRecordSetsResponse>`)
+	case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/change/C1":
+		f.polls++
+		status := Pending
+		if f.polls >= f.pendingPolls {
+			status = InSync
+		}
+		_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>
+<GetChangeResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/"><ChangeInfo><Id>/change/C1</Id><Status>`+status+`</Status><SubmittedAt>2023-01-01T00:00:00.000Z</SubmittedAt></ChangeInfo></GetChangeResponse>`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `<ErrorResponse><Error><Type>Sender</Type><Code>NoSuchHostedZone</Code><Message>No hosted zone found</Message></Error><RequestId>1</RequestId></ErrorResponse>`)
+	}
+}
+
+// verify check the signature with the same signer
+func (f *fakeRoute53) verify(r *http.Request, body []byte) {
+	now, err := time.Parse(amzDateFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		f.t.Errorf("bad X-Amz-Date: %v", err)
+		return
+	}
+	headers := map[string]string{}
+	if r.Header.Get("Content-Type") != "" {
+		headers["Content-Type"] = r.Header.Get("Content-Type")
+	}
+	s := signer{accessKeyId: "AKID", secretAccessKey: "SECRET", region: DefaultRegion, service: signingService}
+	u := *r.URL
+	u.Host = r.Host
+	if expected := s.sign(r.Method, &u, headers, body, now)["Authorization"]; r.Header.Get("Authorization") != expected {
+		f.t.Errorf("signature mismatch\n%s\n%s", r.Header.Get("Authorization"), expected)
+	}
+}
+
+func newFakeRoute53(t *testing.T, f *fakeRoute53) Parameters {
+	server := httptest.NewServer(f)
+	interval, timeout := pollInterval, syncTimeout
+	pollInterval, syncTimeout = 10*time.Millisecond, time.Second
+	t.Cleanup(func() {
+		pollInterval, syncTimeout = interval, timeout
+		server.Close()
+	})
+	return Parameters{
+		AccessKeyId:     "AKID",
+		SecretAccessKey: "SECRET",
+		HostedZoneId:    "/hostedzone/Z1",
+		Domain:          "www.example.com",
+		Value:           "2001:db8::1",
+		TTL:             300,
+		Type:            "AAAA",
+		Endpoint:        server.URL,
+	}
+}
+
+func TestRequest_MakeRequest(t *testing.T) {
+	f := &fakeRoute53{t: t, pendingPolls: 3}
+	p := newFakeRoute53(t, f)
+
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err, r.Status().MG.GetMsgOf(core.Error))
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d", r.Status().Status)
+	}
+	if f.polls != 3 {
+		t.Errorf("GetChange should be polled 3 times, got %d", f.polls)
+	}
+	if len(f.changes) != 1 {
+		t.Fatalf("expect 1 change, got %d", len(f.changes))
+	}
+	c := f.changes[0]
+	if c.Action != "UPSERT" || c.ResourceRecordSet.Name != "www.example.com" || c.ResourceRecordSet.Type != "AAAA" ||
+		c.ResourceRecordSet.TTL != 300 || c.ResourceRecordSet.ResourceRecords[0].Value != "2001:db8::1" {
+		t.Errorf("unexpected change %+v", c)
+	}
+
+	info := strings.Join(r.Status().MG.GetMsgOf(core.Info), "\n")
+	if !strings.Contains(info, "PENDING") || !strings.Contains(info, "INSYNC") {
+		t.Errorf("progress should be reported, got %s", info)
+	}
+	t.Log(info)
+}
+
+func TestRequest_MakeRequestSyncTimeout(t *testing.T) {
+	f := &fakeRoute53{t: t, pendingPolls: 1 << 30}
+	p := newFakeRoute53(t, f)
+	syncTimeout = 100 * time.Millisecond
+
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	// the change is accepted
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d", r.Status().Status)
+	}
+	if len(r.Status().MG.GetMsgOf(core.Warn)) == 0 {
+		t.Error("should warn the change is still pending")
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Warn))
+}
+
+func TestRequest_MakeRequestError(t *testing.T) {
+	cases := []struct {
+		body string
+		code string
+	}{
+		{`<?xml version="1.0"?>
+<InvalidChangeBatch xmlns="https://route53.amazonaws.com/doc/2013-04-01/"><Messages><Message>RRSet of type AAAA with DNS name www.example.com. is not permitted in zone example.org.</Message></Messages><RequestId>2</RequestId></InvalidChangeBatch>`, InvalidChangeBatch},
+		{`<?xml version="1.0"?>
+<ErrorResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/"><Error><Type>Sender</Type><Code>PriorRequestNotComplete</Code><Message>The request was rejected because Route 53 was still processing a prior request.</Message></Error><RequestId>3</RequestId></ErrorResponse>`, PriorRequestNotComplete},
+	}
+	for _, c := range cases {
+		f := &fakeRoute53{t: t, errorBody: c.body}
+		p := newFakeRoute53(t, f)
+
+		r, _ := p.ToRequest()
+		err := r.MakeRequest()
+		if err == nil || !strings.Contains(err.Error(), c.code) {
+			t.Errorf("error should contain %s, got %v", c.code, err)
+		}
+		if r.Status().Status != core.Failed {
+			t.Errorf("status should be failed, got %d", r.Status().Status)
+		}
+		t.Log(r.Status().MG.GetMsgOf(core.Error))
+	}
+}
@@ -0,0 +1,82 @@
+package route53
+
+import "GodDns/core"
+
+// https://docs.aws.amazon.com/Route53/latest/APIReference/API_ChangeResourceRecordSets.html#API_ChangeResourceRecordSets_Errors
+const (
+	Success = ""
+
+	InvalidChangeBatch      = "InvalidChangeBatch"
+	InvalidInput            = "InvalidInput"
+	NoSuchHostedZone        = "NoSuchHostedZone"
+	NoSuchHealthCheck       = "NoSuchHealthCheck"
+	NoSuchChange            = "NoSuchChange"
+	PriorRequestNotComplete = "PriorRequestNotComplete"
+
+	AccessDenied          = "AccessDenied"
+	InvalidClientTokenId  = "InvalidClientTokenId"
+	SignatureDoesNotMatch = "SignatureDoesNotMatch"
+	IncompleteSignature   = "IncompleteSignature"
+	RequestExpired        = "RequestExpired"
+	ExpiredToken          = "ExpiredToken"
+	Throttling            = "Throttling"
+
+	InternalFailure    = "InternalFailure"
+	ServiceUnavailable = "ServiceUnavailable"
+)
+
+// status of a change
+const (
+	Pending = "PENDING"
+	InSync  = "INSYNC"
+)
+
+// code2status
+// convert the error code to message and set status.Status
+func code2status(code string) *core.Status {
+	msg := newStatus()
+	switch code {
+	case Success:
+		msg.MG.AddInfo("request succeeded")
+	case InvalidChangeBatch:
+		msg.MG.AddError("invalid change batch")
+	case InvalidInput:
+		msg.MG.AddError("invalid input")
+	case NoSuchHostedZone:
+		msg.MG.AddError("no such hosted zone")
+	case NoSuchHealthCheck:
+		msg.MG.AddError("no such health check")
+	case NoSuchChange:
+		msg.MG.AddError("no such change")
+	case PriorRequestNotComplete:
+		msg.MG.AddError("prior request of the hosted zone is not complete, try again later")
+	case AccessDenied:
+		msg.MG.AddError("access denied, check the policy of the access key")
+	case InvalidClientTokenId:
+		msg.MG.AddError("access key id does not exist")
+	case SignatureDoesNotMatch:
+		msg.MG.AddError("signature does not match, check SecretAccessKey")
+	case IncompleteSignature:
+		msg.MG.AddError("incomplete signature")
+	case RequestExpired:
+		msg.MG.AddError("request expired, check the system time")
+	case ExpiredToken:
+		msg.MG.AddError("session token expired")
+	case Throttling:
+		msg.MG.AddError("rate exceeded")
+	case InternalFailure:
+		msg.MG.AddError("internal failure")
+	case ServiceUnavailable:
+		msg.MG.AddError("service unavailable")
+	default:
+		msg.MG.AddError("unknown error")
+	}
+
+	if code == Success {
+		msg.Status = core.Success
+	} else {
+		msg.Status = core.Failed
+	}
+
+	return msg
+}
@@ -0,0 +1,134 @@
+package route53
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWS Signature Version 4, see https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	signingService   = "route53"
+	amzDateFormat    = "20060102T150405Z"
+	dateFormat       = "20060102"
+)
+
+// signer sign requests with AWS Signature Version 4
+type signer struct {
+	accessKeyId     string
+	secretAccessKey string
+	sessionToken    string
+	region          string
+	service         string
+}
+
+// sign return headers to be added to the request, including X-Amz-Date, Authorization and X-Amz-Security-Token
+// headers are the other headers to be signed, host is always signed
+func (s signer) sign(method string, u *url.URL, headers map[string]string, payload []byte, now time.Time) map[string]string {
+	now = now.UTC()
+	signed := map[string]string{
+		"X-Amz-Date": now.Format(amzDateFormat),
+	}
+	if s.sessionToken != "" {
+		signed["X-Amz-Security-Token"] = s.sessionToken
+	}
+
+	// lower-case name -> trimmed value
+	canonicalHeaders := map[string]string{"host": u.Host}
+	for k, v := range headers {
+		canonicalHeaders[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+	for k, v := range signed {
+		canonicalHeaders[strings.ToLower(k)] = v
+	}
+	names := make([]string, 0, len(canonicalHeaders))
+	for k := range canonicalHeaders {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var headerBuilder strings.Builder
+	for _, k := range names {
+		headerBuilder.WriteString(k + ":" + canonicalHeaders[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(u),
+		headerBuilder.String(),
+		signedHeaders,
+		hashHex(payload),
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(dateFormat), s.region, s.service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		signed["X-Amz-Date"],
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), now.Format(dateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	signed["Authorization"] = signingAlgorithm + " Credential=" + s.accessKeyId + "/" + scope +
+		", SignedHeaders=" + signedHeaders + ", Signature=" + signature
+	return signed
+}
+
+// canonicalURI return the uri-encoded path, "/" if empty
+func canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// canonicalQuery return the query sorted by key and value, encoded following RFC 3986
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pieces := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			pieces = append(pieces, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(pieces, "&")
+}
+
+// uriEncode encode s following RFC 3986, space is %20 and ~ is not encoded
+func uriEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "%7E", "~")
+	return s
+}
+
+func hashHex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
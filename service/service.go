@@ -5,6 +5,18 @@ package service
 import (
 	_ "GodDns/service/dnspod"       // register Dnspod
 	_ "GodDns/service/dnspodyunapi" // register DnspodYunApi
+
+	_ "GodDns/service/alidns"       // register AliDNS
+	_ "GodDns/service/clouddns"     // register CloudDNS
+	_ "GodDns/service/cloudflare"   // register Cloudflare
+	_ "GodDns/service/digitalocean" // register DigitalOcean
+	_ "GodDns/service/dyndns2"      // register DynDNS2
+	_ "GodDns/service/hetzner"      // register Hetzner
+	_ "GodDns/service/linode"       // register Linode
+	_ "GodDns/service/powerdns"     // register PowerDNS
+	_ "GodDns/service/rfc2136"      // register RFC2136
+	_ "GodDns/service/route53"      // register Route53
+	_ "GodDns/service/webhook"      // register Webhook
 )
 
 // import _ "GodDns/Service/example"
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestConfig_GenerateDefaultConfigInfo(t *testing.T) {
+	config, err := Config{}.GenerateDefaultConfigInfo()
+	if err != nil {
+		t.Error(err)
+	}
+	t.Log(config.Content)
+}
+
+func TestConfig_ReadConfig(t *testing.T) {
+	cfg, err := ini.Load([]byte(`
+[Webhook]
+URL=https://dynupdate.no-ip.com/nic/update?hostname={{.Domain}}&myip={{.IP}}
+Method=get
+Headers=Authorization: Basic dXNlcjpwYXNz
+Success=regex:^(good|nochg)
+Domain=a.example.com,b.example.com
+Value=1.2.3.4
+Type=4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := Config{}.ReadConfig(*cfg.Section("Webhook"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 {
+		t.Fatalf("expect 2 parameters, got %d", len(ps))
+	}
+	for _, p := range ps {
+		p := p.(*Parameters)
+		if p.Type != "A" || p.Method != "GET" {
+			t.Errorf("unexpected parameters %+v", p)
+		}
+		URL, err := render("URL", p.URL, p.templateData())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(URL)
+	}
+}
+
+func TestConfig_ReadConfigInvalid(t *testing.T) {
+	cases := []string{
+		"URL=https://example.com/{{.IP\nDomain=example.com\nValue=1.2.3.4\nType=A",
+		"URL=https://example.com\nMethod=DELETE\nDomain=example.com\nValue=1.2.3.4\nType=A",
+		"URL=https://example.com\nHeaders=no colon\nDomain=example.com\nValue=1.2.3.4\nType=A",
+		"URL=https://example.com\nSuccess=regex:(\nDomain=example.com\nValue=1.2.3.4\nType=A",
+	}
+	for _, c := range cases {
+		cfg, err := ini.Load([]byte("[Webhook]\n" + c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = (Config{}).ReadConfig(*cfg.Section("Webhook")); err == nil {
+			t.Errorf("should return error for %q", c)
+		}
+	}
+}
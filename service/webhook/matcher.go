@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"GodDns/core"
+)
+
+// matcher tell whether the response of webhook means success
+type matcher interface {
+	match(statusCode int, body string) error
+}
+
+// newMatcher create matcher from Success setting
+//
+//	"" or "status:2xx"   status code is 2xx
+//	"status:200,204"     status code is one of 200 and 204
+//	"regex:^(good|nochg)" body matches the regular expression
+//	"json:code=0"        value of path "code" in json body is 0, path is the same as [api.xxx] Value
+func newMatcher(s string) (matcher, error) {
+	if s == "" {
+		return statusMatcher{}, nil
+	}
+
+	kind, expr, found := strings.Cut(s, ":")
+	if !found {
+		return nil, fmt.Errorf("invalid success matcher %s, should be like status:200, regex:^OK or json:path=value", s)
+	}
+	expr = strings.TrimSpace(expr)
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "status":
+		m := statusMatcher{}
+		if expr == "2xx" || expr == "" {
+			return m, nil
+		}
+		for _, code := range strings.Split(expr, ",") {
+			c, err := strconv.Atoi(strings.TrimSpace(code))
+			if err != nil {
+				return nil, fmt.Errorf("invalid status code %s", code)
+			}
+			m.codes = append(m.codes, c)
+		}
+		return m, nil
+	case "regex":
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return regexMatcher{re}, nil
+	case "json":
+		path, expected, found := strings.Cut(expr, "=")
+		if !found || path == "" {
+			return nil, fmt.Errorf("invalid json matcher %s, should be like json:path=value", expr)
+		}
+		return jsonMatcher{path: path, expected: expected}, nil
+	default:
+		return nil, fmt.Errorf("unknown success matcher %s", kind)
+	}
+}
+
+// statusMatcher match status code, any 2xx if codes is empty
+type statusMatcher struct {
+	codes []int
+}
+
+func (s statusMatcher) match(statusCode int, _ string) error {
+	if len(s.codes) == 0 {
+		if statusCode >= 200 && statusCode < 300 {
+			return nil
+		}
+		return fmt.Errorf("unexpected status code %d", statusCode)
+	}
+	for _, code := range s.codes {
+		if code == statusCode {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected status code %d", statusCode)
+}
+
+// regexMatcher match body with regular expression
+type regexMatcher struct {
+	*regexp.Regexp
+}
+
+func (r regexMatcher) match(_ int, body string) error {
+	if r.MatchString(body) {
+		return nil
+	}
+	return errors.New("response does not match " + r.String())
+}
+
+// jsonMatcher match value of path in json body
+type jsonMatcher struct {
+	path     string
+	expected string
+}
+
+func (j jsonMatcher) match(_ int, body string) error {
+	value, err := core.GetJsonValue(body, j.path)
+	if err != nil {
+		return fmt.Errorf("error getting %s from response: %w", j.path, err)
+	}
+	if got := fmt.Sprintf("%v", value); got != j.expected {
+		return fmt.Errorf("%s is %s, expect %s", j.path, got, j.expected)
+	}
+	return nil
+}
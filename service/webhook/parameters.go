@@ -0,0 +1,170 @@
+// Package webhook is a generic DDNS service which sends a templated http request,
+// new registrars can be supported by config alone
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"GodDns/core"
+	"GodDns/netutil"
+)
+
+const serviceName = "Webhook"
+
+// Parameters implements DeviceOverridable
+// URL and Body are templates, {{.IP}}, {{.Domain}} and {{.Type}} will be replaced
+// Headers are separated by '|' like "Authorization: Bearer token|Content-Type: application/json"
+// Success is the matcher to tell whether the request succeeded, see newMatcher
+// Device is Device name when overriding ip with specific Device/interface
+type Parameters struct {
+	URL     string `KeyValue:"URL,url template like https://www.duckdns.org/update?domains=example&token=TOKEN&ip={{.IP}}"`
+	Method  string `KeyValue:"Method,GET(default)/POST/PUT/PATCH"`
+	Headers string `KeyValue:"Headers,headers separated by '|' like Authorization: Bearer TOKEN|Content-Type: application/json"`
+	Body    string `KeyValue:"Body,body template like {\"ip\":\"{{.IP}}\"}, {{.IP}} {{.Domain}} {{.Type}} are available"`
+	Success string `KeyValue:"Success,status:200,204 or regex:^OK or json:path=value, status:2xx(default)"`
+	Domain  string `KeyValue:"Domain,domain name like www.example.com, if you have multiple domains to update, set like Domain=www.example.com,ftp.example.com"`
+	Value   string `KeyValue:"Value,IP address like 6.6.6.6"`
+	Type    string `KeyValue:"Type,A/AAAA/4/6"`
+	Device  string `KeyValue:"Device,device/net interface name"`
+	core.DeviceOptions
+}
+
+// GenerateDefaultConfigInfo return Default config
+func GenerateDefaultConfigInfo() Parameters {
+	return Parameters{
+		URL:     "https://www.duckdns.org/update?domains=example&token=TOKEN&ip={{.IP}}",
+		Method:  "GET",
+		Success: "regex:^OK",
+		Domain:  "example.duckdns.org",
+		Value:   "1.2.3.4",
+		Type:    "A/AAAA/4/6",
+		Device:  "your device/net interface name",
+	}
+}
+
+// GetName return "Webhook"
+func (p *Parameters) GetName() string {
+	return serviceName
+}
+
+// SaveConfig return core.ConfigStr
+func (p *Parameters) SaveConfig(No uint) (core.ConfigStr, error) {
+	return configInstance.GenerateConfigInfo(p, No)
+}
+
+// Target return Domain
+func (p *Parameters) Target() string {
+	return p.Domain
+}
+
+// ToRequest Convert to core.Request
+func (p *Parameters) ToRequest() (core.Request, error) {
+	r := new(Request)
+	r.Init(*p)
+	return r, nil
+}
+
+// SetValue set ip
+func (p *Parameters) SetValue(value string) {
+	p.Value = value
+}
+
+// GetIP return ip value
+func (p *Parameters) GetIP() string {
+	return p.Value
+}
+
+// GetType return Type like "4" or "6" and "" if invalid type
+func (p *Parameters) GetType() string {
+	return netutil.Type2Num(p.Type)
+}
+
+// IsTypeSet return whether the type is set correctly
+func (p *Parameters) IsTypeSet() bool {
+	return p.Type == "A" || p.Type == "AAAA"
+}
+
+// GetDevice return Device name
+func (p *Parameters) GetDevice() string {
+	return p.Device
+}
+
+// IsDeviceSet return whether the Device is set
+func (p *Parameters) IsDeviceSet() bool {
+	return p.Device != ""
+}
+
+// getMethod return Method or "GET" if not set
+func (p *Parameters) getMethod() string {
+	if p.Method == "" {
+		return "GET"
+	}
+	return p.Method
+}
+
+// validate check Method, templates, Headers and Success
+func (p *Parameters) validate() error {
+	switch p.getMethod() {
+	case "GET", "POST", "PUT", "PATCH":
+	default:
+		return fmt.Errorf("unsupported method %s", p.Method)
+	}
+	if _, err := template.New("URL").Parse(p.URL); err != nil {
+		return err
+	}
+	if _, err := template.New("Body").Parse(p.Body); err != nil {
+		return err
+	}
+	if _, err := parseHeaders(p.Headers); err != nil {
+		return err
+	}
+	_, err := newMatcher(p.Success)
+	return err
+}
+
+// templateData is the data available in URL and Body templates
+type templateData struct {
+	IP     string
+	Domain string
+	Type   string
+}
+
+func (p *Parameters) templateData() templateData {
+	return templateData{
+		IP:     p.Value,
+		Domain: p.Domain,
+		Type:   p.Type,
+	}
+}
+
+// render execute the template text with data
+func render(name string, text string, data templateData) (string, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buffer := new(bytes.Buffer)
+	if err = t.Execute(buffer, data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// parseHeaders parse headers like "Authorization: Bearer TOKEN|Content-Type: application/json"
+func parseHeaders(headers string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, header := range strings.Split(headers, "|") {
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid header %s, should be like Key: Value", header)
+		}
+		m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return m, nil
+}
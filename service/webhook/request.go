@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"GodDns/core"
+	log "GodDns/log"
+	"GodDns/netutil"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	requestTimeout = 20 * time.Second
+	// maxResponseLength is the max length of response kept in status message
+	maxResponseLength = 200
+)
+
+// usage
+// r:=webhook.Request
+// r.Init(Parameters)
+// r.MakeRequest()
+
+// Request implements core.Request and core.ThroughProxy
+type Request struct {
+	parameters Parameters
+	status     core.Status
+}
+
+// Init set parameter
+func (r *Request) Init(parameters Parameters) {
+	r.parameters = parameters
+}
+
+// Target return target domain
+func (r *Request) Target() string {
+	return r.parameters.Target()
+}
+
+// GetName return "Webhook"
+func (r *Request) GetName() string {
+	return serviceName
+}
+
+// ToParameters return core.Service
+func (r *Request) ToParameters() core.Service {
+	return &r.parameters
+}
+
+// Status return core.Status which contains execution result etc.
+func (r *Request) Status() core.Status {
+	return r.status
+}
+
+func newStatus() *core.Status {
+	return &core.Status{
+		Name:   serviceName,
+		Status: core.NotExecute,
+		MG:     core.NewDefaultMsgGroup(),
+	}
+}
+
+// MakeRequest render URL and Body, send the request and check the response with Success matcher
+func (r *Request) MakeRequest() error {
+	client := core.MainClientPool.Get().(*resty.Client)
+	defer core.MainClientPool.Put(client)
+	return r.execute(client)
+}
+
+// RequestThroughProxy make request through the proxies in netutil.GlobalProxies one by one until one succeeds
+func (r *Request) RequestThroughProxy() error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	iter := netutil.GlobalProxies.GetProxyIter()
+	if iter.Len() == 0 {
+		return r.fail(errors.New("no proxy available"))
+	}
+
+	var err error
+	for iter.NotLast() {
+		proxy := iter.Next()
+		// never set proxy on a pooled client
+		client := core.MainClientPool.New().(*resty.Client).SetProxy(proxy)
+		err = r.execute(client)
+		if err == nil {
+			return nil
+		}
+		errMsg := fmt.Sprintf("request error through proxy %s: %v", proxy, err)
+		r.status.MG.AddError(errMsg)
+		log.Errorf(errMsg)
+	}
+	return err
+}
+
+func (r *Request) execute(client *resty.Client) error {
+	if r.status.MG == nil {
+		r.status = *newStatus()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	data := r.parameters.templateData()
+	URL, err := render("URL", r.parameters.URL, data)
+	if err != nil {
+		return r.fail(fmt.Errorf("error rendering URL: %w", err))
+	}
+	body, err := render("Body", r.parameters.Body, data)
+	if err != nil {
+		return r.fail(fmt.Errorf("error rendering Body: %w", err))
+	}
+	headers, err := parseHeaders(r.parameters.Headers)
+	if err != nil {
+		return r.fail(err)
+	}
+	m, err := newMatcher(r.parameters.Success)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	req := client.R().SetContext(ctx).SetHeaders(headers)
+	if body != "" {
+		req.SetBody(body)
+	}
+	log.Debugf("send %s webhook of %s", r.parameters.getMethod(), r.Target())
+	resp, err := req.Execute(r.parameters.getMethod(), URL)
+	if err != nil {
+		return r.fail(err)
+	}
+	log.Tracef("response: %v", resp)
+
+	if err = m.match(resp.StatusCode(), resp.String()); err != nil {
+		return r.fail(fmt.Errorf("webhook of %s failed: %w, response: %s", r.Target(), err, abbreviate(resp.String())))
+	}
+
+	r.status.Status = core.Success
+	r.status.MG.AddInfo(fmt.Sprintf("webhook of %s succeeded %s, response: %s", r.Target(), r.parameters.Value, abbreviate(resp.String())))
+	return nil
+}
+
+// fail set status to Failed with err and return err
+func (r *Request) fail(err error) error {
+	r.status.Status = core.Failed
+	r.status.MG.AddError(err.Error())
+	return err
+}
+
+// abbreviate cut the response to maxResponseLength
+func abbreviate(s string) string {
+	if len(s) > maxResponseLength {
+		return s[:maxResponseLength] + "..."
+	}
+	return s
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"GodDns/core"
+)
+
+func TestRequest_MakeRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method should be POST, got %s", r.Method)
+		}
+		if r.URL.Query().Get("hostname") != "www.example.com" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		if r.Header.Get("Authorization") != "Bearer TOKEN" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"ip":"1.2.3.4","type":"A"}` {
+			t.Errorf("unexpected body %s", body)
+		}
+		_, _ = io.WriteString(w, `{"code":0,"data":{"ips":["1.2.3.4"]}}`)
+	}))
+	defer server.Close()
+
+	p := Parameters{
+		URL:     server.URL + "/update?hostname={{.Domain}}",
+		Method:  "POST",
+		Headers: "Authorization: Bearer TOKEN|Content-Type: application/json",
+		Body:    `{"ip":"{{.IP}}","type":"{{.Type}}"}`,
+		Success: "json:data.ips[0]=1.2.3.4",
+		Domain:  "www.example.com",
+		Value:   "1.2.3.4",
+		Type:    "A",
+	}
+	r, _ := p.ToRequest()
+	if err := r.MakeRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status().Status != core.Success {
+		t.Errorf("status should be success, got %d", r.Status().Status)
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Info))
+}
+
+func TestRequest_MakeRequestFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "badauth")
+	}))
+	defer server.Close()
+
+	p := Parameters{
+		URL:     server.URL + "/nic/update?myip={{.IP}}",
+		Success: "regex:^(good|nochg)",
+		Domain:  "www.example.com",
+		Value:   "1.2.3.4",
+		Type:    "A",
+	}
+	r, _ := p.ToRequest()
+	if r.MakeRequest() == nil {
+		t.Fatal("should return error")
+	}
+	if r.Status().Status != core.Failed {
+		t.Errorf("status should be failed, got %d", r.Status().Status)
+	}
+	t.Log(r.Status().MG.GetMsgOf(core.Error))
+}
+
+func TestNewMatcher(t *testing.T) {
+	cases := []struct {
+		matcher    string
+		statusCode int
+		body       string
+		ok         bool
+	}{
+		{"", 200, "", true},
+		{"", 404, "", false},
+		{"status:2xx", 204, "", true},
+		{"status:200, 201", 201, "", true},
+		{"status:200", 204, "", false},
+		{"regex:^OK", 200, "OK", true},
+		{"regex:^OK", 200, "KO", false},
+		{"json:success=true", 200, `{"success":true}`, true},
+		{"json:success=true", 200, `{"success":false}`, false},
+		{"json:result[0].id=1", 200, `{"result":[{"id":1}]}`, true},
+		{"json:result[1].id=1", 200, `{"result":[{"id":1}]}`, false},
+		{"json:success=true", 200, `not json`, false},
+	}
+	for _, c := range cases {
+		m, err := newMatcher(c.matcher)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = m.match(c.statusCode, c.body); (err == nil) != c.ok {
+			t.Errorf("matcher %q with %d %q: expect %v, got %v", c.matcher, c.statusCode, c.body, c.ok, err)
+		}
+	}
+
+	for _, invalid := range []string{"200", "status:abc", "regex:(", "json:=1", "xpath:/a"} {
+		if _, err := newMatcher(invalid); err == nil {
+			t.Errorf("matcher %q should be invalid", invalid)
+		}
+	}
+}
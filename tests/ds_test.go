@@ -40,6 +40,13 @@ func TestRemoveDuplicate(t *testing.T) {
 	}
 }
 
+func TestAppendUnique(t *testing.T) {
+	s := collections.AppendUnique([]string{"b"}, "a", "b", "c", "a")
+	if fmt.Sprint(s) != "[b a c]" {
+		t.Errorf("AppendUnique error, got %v", s)
+	}
+}
+
 func TestPair(t *testing.T) {
 	p := collections.NewPair[int, string](0, "")
 	p.Set(1, "a")
@@ -3,6 +3,7 @@ package tests_test
 import (
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 
@@ -67,7 +68,7 @@ func TestConfigFileGenerator(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	err = DDNS.ConfigureWriter("test.conf", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, dnspod, yun)
+	err = DDNS.ConfigureWriter(filepath.Join(t.TempDir(), "test.conf"), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, dnspod, yun)
 	if err != nil {
 		t.Error(err)
 	}
@@ -100,6 +101,21 @@ func TestConvert2KeyValue(t *testing.T) {
 	t.Log("\n", util.Convert2KeyValue("%s = %v", &p))
 }
 
+func TestConvert2KeyValueEmbedded(t *testing.T) {
+	p := dnspod.Parameters{
+		Domain:        "example.com",
+		Device:        "eth0",
+		DeviceOptions: DDNS.DeviceOptions{Filter: "remove-private", PrefixLength: 56},
+	}
+	want := "# domain name\nDomain=example.com\n" +
+		"# device/net interface name\nDevice=eth0\n" +
+		"# filter pipeline of ip collected from Device in order, e.g. remove-private,remove-loopback,global-unicast,select:0\nFilter=remove-private\n" +
+		"# length of prefix taken from ip got when IPv6Suffix is set, 64(default)\nPrefixLength=56\n"
+	if got := util.Convert2KeyValue("%s=%v", &p); got != want {
+		t.Errorf("expect\n%s\ngot\n%s", want, got)
+	}
+}
+
 type TestStruct struct {
 	Name     string `json:"name" xwwwformurlencoded:"name"`
 	Age      int    `json:"age" xwwwformurlencoded:"age"`
@@ -288,3 +288,17 @@ func RemoveDuplicate[T comparable](slice *[]T) {
 	sliceTemp := set.Items()
 	*slice = sliceTemp
 }
+
+// AppendUnique append elements which are not in slice yet, the order of first appearance is kept
+func AppendUnique[T comparable](slice []T, val ...T) []T {
+NEXT:
+	for _, v := range val {
+		for _, s := range slice {
+			if s == v {
+				continue NEXT
+			}
+		}
+		slice = append(slice, v)
+	}
+	return slice
+}
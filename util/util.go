@@ -92,6 +92,12 @@ func Convert2KeyValue(format string, i any) string {
 			continue
 		}
 
+		// fields of an embedded struct are keys of the same section unless it is tagged KeyValue:"-"
+		if tfieldi.Anonymous && tfieldi.Type.Kind() == reflect.Struct && tfieldi.Tag.Get("KeyValue") != "-" {
+			content.WriteString(Convert2KeyValue(format, vfiledi))
+			continue
+		}
+
 		name := tfieldi.Tag.Get("KeyValue") // `name,comments`
 		if name == "-" {
 			continue
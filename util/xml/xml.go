@@ -1,6 +1,10 @@
 package xml
 
-import "github.com/beevik/etree"
+import (
+	"encoding/xml"
+
+	"github.com/beevik/etree"
+)
 
 type Reader struct {
 	doc *etree.Document
@@ -14,3 +18,7 @@ func (x *Reader) ReadFromFile(filename string) error {
 func (x *Reader) FindElement(path string) *etree.Element {
 	return x.doc.FindElement(path)
 }
+
+func Unmarshal(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}